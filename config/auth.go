@@ -0,0 +1,148 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// Role - rola użytkownika tokenu API
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"    // pełny dostęp, zarządzanie bazami
+	RoleOperator Role = "operator" // nagrywanie, OBS, stoper, dane rozgrywek
+	RoleScorer   Role = "scorer"   // wynik, wydarzenia, stoper
+	RoleOverlay  Role = "overlay"  // tylko odczyt (grafiki, podgląd)
+	RoleRecorder Role = "recorder" // rejestratory kamer
+)
+
+// AllRoles - lista wszystkich ról
+var AllRoles = []Role{RoleAdmin, RoleOperator, RoleScorer, RoleOverlay, RoleRecorder}
+
+// IsValidRole - sprawdza czy rola istnieje
+func IsValidRole(role string) bool {
+	for _, r := range AllRoles {
+		if string(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// APIToken - token API przypisany do roli (przechowywany jest tylko hash)
+type APIToken struct {
+	Name      string `json:"name"`       // Unikalna nazwa (np. "tablet-sedziowski")
+	TokenHash string `json:"token_hash"` // SHA-256 tokenu (hex)
+	Role      Role   `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+// AuthConfig - konfiguracja uwierzytelniania
+type AuthConfig struct {
+	Tokens []APIToken `json:"tokens"`
+}
+
+const AuthConfigFile = "auth_config.json"
+
+// LoadAuthConfig - wczytuje konfigurację tokenów (brak pliku = brak tokenów)
+func LoadAuthConfig() (*AuthConfig, error) {
+	if _, err := os.Stat(AuthConfigFile); os.IsNotExist(err) {
+		return &AuthConfig{Tokens: []APIToken{}}, nil
+	}
+
+	data, err := os.ReadFile(AuthConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var config AuthConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Auth: Loaded %d API tokens", len(config.Tokens))
+	return &config, nil
+}
+
+// SaveAuthConfig - zapisuje konfigurację tokenów do pliku
+func SaveAuthConfig(config *AuthConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Plik zawiera hashe tokenów - tylko dla właściciela
+	return os.WriteFile(AuthConfigFile, data, 0600)
+}
+
+// hashToken - zwraca SHA-256 tokenu w postaci hex
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsEnabled - uwierzytelnianie jest włączone gdy istnieje co najmniej jeden token
+func (c *AuthConfig) IsEnabled() bool {
+	return len(c.Tokens) > 0
+}
+
+// AddToken - generuje nowy token i zwraca jego jawną wartość (pokazywaną tylko raz)
+func (c *AuthConfig) AddToken(name string, role Role) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("nazwa tokenu jest wymagana")
+	}
+	if !IsValidRole(string(role)) {
+		return "", fmt.Errorf("nieznana rola: %s", role)
+	}
+	for _, t := range c.Tokens {
+		if t.Name == name {
+			return "", fmt.Errorf("token o nazwie %s już istnieje", name)
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("błąd generowania tokenu: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	c.Tokens = append(c.Tokens, APIToken{
+		Name:      name,
+		TokenHash: hashToken(token),
+		Role:      role,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+	return token, nil
+}
+
+// RemoveToken - usuwa token o podanej nazwie
+func (c *AuthConfig) RemoveToken(name string) bool {
+	for i, t := range c.Tokens {
+		if t.Name == name {
+			c.Tokens = append(c.Tokens[:i], c.Tokens[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// FindToken - wyszukuje token po jego jawnej wartości
+func (c *AuthConfig) FindToken(token string) *APIToken {
+	if token == "" {
+		return nil
+	}
+	hash := []byte(hashToken(token))
+	for i := range c.Tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(c.Tokens[i].TokenHash)) == 1 {
+			return &c.Tokens[i]
+		}
+	}
+	return nil
+}
@@ -2,10 +2,9 @@ package config
 
 // Config - konfiguracja aplikacji
 type Config struct {
-	Server    ServerConfig
-	OBS       OBSConfig
-	SocketIO  SocketIOConfig
-	Recording RecordingConfig
+	Server   ServerConfig
+	OBS      OBSConfig
+	SocketIO SocketIOConfig
 }
 
 // ServerConfig - konfiguracja serwera HTTP
@@ -25,11 +24,6 @@ type SocketIOConfig struct {
 	Enabled bool
 }
 
-// RecordingConfig - konfiguracja nagrywania
-type RecordingConfig struct {
-	AllCameras []string
-}
-
 // LoadConfig - ładuje konfigurację
 func LoadConfig() *Config {
 	return &Config{
@@ -44,13 +38,5 @@ func LoadConfig() *Config {
 		SocketIO: SocketIOConfig{
 			Enabled: true,
 		},
-		Recording: RecordingConfig{
-			AllCameras: []string{
-				"camera_main",
-				"camera_center",
-				"camera_left",
-				"camera_right",
-			},
-		},
 	}
 }
\ No newline at end of file
@@ -28,7 +28,8 @@ type CompetitionPresetFull struct {
 type ConstantData struct {
 	ValueTypes     []ValueTypeData     `json:"value_types"`
 	PlayerRoles    []RoleData          `json:"player_roles"`
-	GamePartValues []GamePartValueData `json:"game_part_values"`
+	GamePartValues []GamePartValueData `json:"game_part_values"` // sloty wartości każdej części meczu (domyślne)
+	GameParts      []GamePartData      `json:"game_parts"`       // części nowego meczu - bez nich mecz nie ma części ani slotów wartości
 	Stages         []StageData         `json:"stages"`
 	Groups         []GroupData         `json:"groups"`
 }
@@ -71,6 +72,16 @@ type GamePartValueData struct {
 	MaxValue       *int `json:"max_value"`        // nullable
 }
 
+// GamePartData - szablon części meczu tworzonej dla każdego nowego meczu
+type GamePartData struct {
+	Name               string              `json:"name"`
+	Length             *int                `json:"length"` // nullable - czas w sekundach
+	MatchOrder         int                 `json:"match_order"`
+	TimeGroup          *uint               `json:"time_group"` // nullable
+	IsAddedTimeAllowed bool                `json:"is_added_time_allowed"`
+	Values             []GamePartValueData `json:"values,omitempty"` // nadpisuje game_part_values dla tej części
+}
+
 // StagesData - dane etapów rozgrywek
 type StageData struct {
 	ID             uint    `json:"id"`
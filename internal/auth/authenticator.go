@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"recorder-server/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CookieName - nazwa ciasteczka z tokenem ustawianego przez stronę logowania
+const CookieName = "recorder_token"
+
+// Identity - tożsamość uwierzytelnionego klienta
+type Identity struct {
+	Name string      `json:"name"`
+	Role config.Role `json:"role"`
+}
+
+// Authenticator - weryfikuje tokeny API (konfiguracja przeładowywana po zmianie pliku)
+type Authenticator struct {
+	mu      sync.RWMutex
+	config  *config.AuthConfig
+	loadErr error // plik istnieje, ale nie da się go wczytać - dostęp zablokowany
+	loaded  bool
+	modTime time.Time
+}
+
+// NewAuthenticator - tworzy nowy authenticator
+func NewAuthenticator() *Authenticator {
+	a := &Authenticator{}
+	a.reloadIfChanged()
+	if a.loadErr != nil {
+		log.Printf("Auth: ❌ Nieprawidłowy plik %s - dostęp zablokowany do czasu poprawienia pliku", config.AuthConfigFile)
+	} else if !a.IsEnabled() {
+		log.Printf("Auth: ⚠️  Brak tokenów w %s - dostęp bez uwierzytelniania. Utwórz token: db_helper token create <nazwa> admin",
+			config.AuthConfigFile)
+	}
+	return a
+}
+
+// reloadIfChanged - przeładowuje konfigurację, jeśli plik został zmieniony (np. przez db_helper)
+//
+// Tylko brak pliku wyłącza uwierzytelnianie - plik, którego nie da się odczytać lub sparsować,
+// blokuje dostęp (fail closed).
+func (a *Authenticator) reloadIfChanged() {
+	var modTime time.Time
+	if info, err := os.Stat(config.AuthConfigFile); err == nil {
+		modTime = info.ModTime()
+	}
+
+	a.mu.RLock()
+	unchanged := a.loaded && modTime.Equal(a.modTime)
+	a.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	cfg, err := config.LoadAuthConfig()
+	if err != nil {
+		log.Printf("Auth: Błąd wczytywania %s: %v", config.AuthConfigFile, err)
+		cfg = nil
+	}
+
+	a.mu.Lock()
+	a.config = cfg
+	a.loadErr = err
+	a.loaded = true
+	a.modTime = modTime
+	a.mu.Unlock()
+}
+
+// IsEnabled - czy uwierzytelnianie jest wymagane (zawsze, gdy pliku konfiguracji nie da się wczytać)
+func (a *Authenticator) IsEnabled() bool {
+	a.reloadIfChanged()
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.loadErr != nil {
+		return true
+	}
+	return a.config != nil && a.config.IsEnabled()
+}
+
+// Authenticate - zwraca tożsamość dla tokenu lub nil
+func (a *Authenticator) Authenticate(token string) *Identity {
+	a.reloadIfChanged()
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.config == nil {
+		return nil
+	}
+	t := a.config.FindToken(token)
+	if t == nil {
+		return nil
+	}
+	return &Identity{Name: t.Name, Role: t.Role}
+}
+
+// TokenFromRequest - wyciąga token z nagłówka, ciasteczka lub parametru zapytania
+func TokenFromRequest(header http.Header, query url.Values) string {
+	if authHeader := header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+	}
+	if token := header.Get("X-API-Token"); token != "" {
+		return token
+	}
+
+	// Ciasteczko parsujemy z nagłówka (Socket.IO udostępnia tylko nagłówki)
+	request := http.Request{Header: header}
+	if cookie, err := request.Cookie(CookieName); err == nil {
+		return cookie.Value
+	}
+
+	return query.Get("token")
+}
+
+// AuthenticateRequest - uwierzytelnia żądanie HTTP
+func (a *Authenticator) AuthenticateRequest(r *http.Request) *Identity {
+	return a.Authenticate(TokenFromRequest(r.Header, r.URL.Query()))
+}
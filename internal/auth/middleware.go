@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"recorder-server/config"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type contextKey string
+
+const identityKey contextKey = "identity"
+
+// rule - reguła dostępu dla ścieżek zaczynających się od PathPrefix
+type rule struct {
+	PathPrefix string        // "*" zastępuje jeden segment ścieżki (np. ID meczu)
+	WriteOnly  bool          // true = reguła dotyczy tylko metod innych niż GET
+	Roles      []config.Role // role z dostępem
+}
+
+var (
+	everyone = []config.Role{config.RoleAdmin, config.RoleOperator, config.RoleScorer, config.RoleOverlay, config.RoleRecorder}
+	staff    = []config.Role{config.RoleAdmin, config.RoleOperator}
+	scoring  = []config.Role{config.RoleAdmin, config.RoleOperator, config.RoleScorer}
+)
+
+// rules - reguły sprawdzane w kolejności, pierwsza pasująca decyduje
+var rules = []rule{
+	{PathPrefix: "/setup", Roles: []config.Role{config.RoleAdmin}},
+	{PathPrefix: "/api/database/", WriteOnly: true, Roles: []config.Role{config.RoleAdmin}},
+	{PathPrefix: "/api/database/", Roles: staff},
+	{PathPrefix: "/api/recordings/files", WriteOnly: true, Roles: []config.Role{config.RoleAdmin, config.RoleOperator, config.RoleRecorder}},
+	{PathPrefix: "/api/recorders/snapshots", WriteOnly: true, Roles: []config.Role{config.RoleAdmin, config.RoleOperator, config.RoleRecorder}},
+	{PathPrefix: "/api/recorders/commands/ack", WriteOnly: true, Roles: []config.Role{config.RoleAdmin, config.RoleOperator, config.RoleRecorder}},
+	{PathPrefix: "/api/timer/", WriteOnly: true, Roles: scoring},
+	// Protokolant - tylko wynik, zdarzenia i cofanie ich zmian; pozostałe operacje na meczu dla obsługi
+	{PathPrefix: "/api/games/*/score", WriteOnly: true, Roles: scoring},
+	{PathPrefix: "/api/games/*/events", WriteOnly: true, Roles: scoring},
+	{PathPrefix: "/api/games/*/undo", WriteOnly: true, Roles: scoring},
+	{PathPrefix: "/api/games/*/redo", WriteOnly: true, Roles: scoring},
+	// Pozostałe operacje zapisu - tylko obsługa realizacji
+	{PathPrefix: "/", WriteOnly: true, Roles: staff},
+	// Odczyt - każda rola
+	{PathPrefix: "/", Roles: everyone},
+}
+
+// publicPrefixes - ścieżki dostępne bez logowania
+var publicPrefixes = []string{"/login", "/logout", "/static/", "/socket.io/"}
+
+// IsAllowed - sprawdza czy rola ma dostęp do metody i ścieżki
+func IsAllowed(role config.Role, method, path string) bool {
+	isWrite := method != http.MethodGet && method != http.MethodHead
+	for _, rl := range rules {
+		if !matchesPrefix(path, rl.PathPrefix) {
+			continue
+		}
+		if rl.WriteOnly && !isWrite {
+			continue
+		}
+		for _, r := range rl.Roles {
+			if r == role {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// matchesPrefix - czy ścieżka zaczyna się od wzorca; wzorce z "*" porównywane są całymi segmentami
+func matchesPrefix(path, pattern string) bool {
+	if !strings.Contains(pattern, "*") {
+		return strings.HasPrefix(path, pattern)
+	}
+
+	patternParts := strings.Split(pattern, "/")
+	pathParts := strings.Split(path, "/")
+	if len(pathParts) < len(patternParts) {
+		return false
+	}
+	for i, part := range patternParts {
+		if part == "*" {
+			if pathParts[i] == "" {
+				return false
+			}
+			continue
+		}
+		if part != pathParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IdentityFromContext - zwraca tożsamość zapisaną przez middleware (nil gdy brak uwierzytelniania)
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey).(*Identity)
+	return identity
+}
+
+// Middleware - middleware wymagający tokenu z odpowiednią rolą
+func (a *Authenticator) Middleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range publicPrefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			// Bez skonfigurowanych tokenów dostęp pozostaje otwarty
+			if !a.IsEnabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			identity := a.AuthenticateRequest(r)
+			if identity == nil {
+				if !strings.HasPrefix(r.URL.Path, "/api/") {
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
+				writeAuthError(w, http.StatusUnauthorized, "Wymagane uwierzytelnienie")
+				return
+			}
+
+			if !IsAllowed(identity.Role, r.Method, r.URL.Path) {
+				writeAuthError(w, http.StatusForbidden, "Brak uprawnień dla roli "+string(identity.Role))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), identityKey, identity)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// writeAuthError - zapisuje błąd uwierzytelniania w formacie API
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "error",
+		"error":  message,
+	})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"recorder-server/internal/auth"
+)
+
+// AuthHandler - handler logowania tokenem API
+type AuthHandler struct {
+	authenticator *auth.Authenticator
+}
+
+// NewAuthHandler - tworzy nowy handler logowania
+func NewAuthHandler(authenticator *auth.Authenticator) *AuthHandler {
+	return &AuthHandler{
+		authenticator: authenticator,
+	}
+}
+
+var loginTemplate = template.Must(template.New("login").Parse(`
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<title>Logowanie - Recorder Server</title>
+		<style>
+			body { font-family: Arial; max-width: 400px; margin: 80px auto; padding: 20px; }
+			h1 { color: #333; }
+			form { background: #f5f5f5; padding: 20px; border-radius: 8px; }
+			input { width: 100%; padding: 8px; border: 1px solid #ddd; border-radius: 4px; box-sizing: border-box; }
+			button { padding: 12px 24px; margin-top: 20px; background: #667eea; color: white;
+			         border: none; border-radius: 5px; cursor: pointer; font-size: 16px; }
+			button:hover { background: #5568d3; }
+			.error { color: #c00; margin-bottom: 10px; }
+		</style>
+	</head>
+	<body>
+		<h1>🔒 Logowanie</h1>
+		{{if .Error}}<div class="error">{{.Error}}</div>{{end}}
+		<form method="POST" action="/login">
+			<label>Token API:
+				<input type="password" name="token" required autofocus>
+			</label>
+			<button type="submit">Zaloguj</button>
+		</form>
+	</body>
+	</html>
+`))
+
+// ShowLoginPage - wyświetla stronę logowania
+// GET /login
+func (h *AuthHandler) ShowLoginPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	loginTemplate.Execute(w, map[string]string{})
+}
+
+// Login - weryfikuje token i zapisuje go w ciasteczku
+// POST /login
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	token := r.FormValue("token")
+
+	identity := h.authenticator.Authenticate(token)
+	if identity == nil {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		loginTemplate.Execute(w, map[string]string{"Error": "Nieprawidłowy token"})
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.CookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// Logout - usuwa ciasteczko z tokenem
+// GET /logout
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// WhoAmI - zwraca tożsamość aktualnego klienta
+// GET /api/auth/me
+func (h *AuthHandler) WhoAmI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"auth_enabled": h.authenticator.IsEnabled(),
+		"identity":     auth.IdentityFromContext(r.Context()),
+	})
+}
@@ -7,19 +7,33 @@ import (
 	"recorder-server/internal/models"
 	"recorder-server/internal/services"
 	"recorder-server/internal/state"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 // CameraHandler - handler dla operacji na kamerach
 type CameraHandler struct {
-	appState      *state.AppState
-	socketService *services.SocketIOService
+	appState         *state.AppState
+	socketService    *services.SocketIOService
+	recordingService *services.RecordingService
+	cameraService    *services.CameraService
+}
+
+// CameraRequest - struktura żądania dla tworzenia/edycji kamery
+type CameraRequest struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
 }
 
 // NewCameraHandler - tworzy nowy handler kamer
-func NewCameraHandler(appState *state.AppState, socketService *services.SocketIOService) *CameraHandler {
+func NewCameraHandler(appState *state.AppState, socketService *services.SocketIOService, recordingService *services.RecordingService, cameraService *services.CameraService) *CameraHandler {
 	return &CameraHandler{
-		appState:      appState,
-		socketService: socketService,
+		appState:         appState,
+		socketService:    socketService,
+		recordingService: recordingService,
+		cameraService:    cameraService,
 	}
 }
 
@@ -31,6 +45,14 @@ func (h *CameraHandler) StartRecording(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Zapisz sesję nagrywania w bazie (brak bazy nie blokuje nagrywania)
+	session, err := h.recordingService.StartSession(data.ActiveCameras)
+	if err != nil {
+		log.Printf("Ostrzeżenie: Nie zapisano sesji nagrywania: %v", err)
+	} else {
+		data.SessionID = session.ID
+	}
+
 	h.appState.StartRecording(data.ActiveCameras, data.InactiveCameras)
 	h.socketService.BroadcastStartRecording(data)
 
@@ -38,7 +60,10 @@ func (h *CameraHandler) StartRecording(w http.ResponseWriter, r *http.Request) {
 		data.ActiveCameras, data.InactiveCameras)
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(models.APIResponse{Status: "success"})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"session_id": data.SessionID,
+	})
 }
 
 // StopRecording - zatrzymuje nagrywanie
@@ -49,6 +74,10 @@ func (h *CameraHandler) StopRecording(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := h.recordingService.StopSession(); err != nil {
+		log.Printf("Ostrzeżenie: Nie zapisano zakończenia sesji nagrywania: %v", err)
+	}
+
 	h.appState.StopRecording()
 	h.socketService.BroadcastStopRecording(data)
 
@@ -80,4 +109,252 @@ func (h *CameraHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
-}
\ No newline at end of file
+}
+
+// ListCameras - lista kamer rozgrywek
+// GET /api/cameras
+func (h *CameraHandler) ListCameras(w http.ResponseWriter, r *http.Request) {
+	cameras, err := h.cameraService.ListCameras()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"cameras": cameras,
+		"count":   len(cameras),
+	})
+}
+
+// GetCamera - pobiera pojedynczą kamerę
+// GET /api/cameras/{id}
+func (h *CameraHandler) GetCamera(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cameraID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID kamery", http.StatusBadRequest)
+		return
+	}
+
+	camera, err := h.cameraService.GetCamera(uint(cameraID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Kamera nie znaleziona", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Błąd pobierania kamery", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"camera": camera,
+	})
+}
+
+// CreateCamera - dodaje nową kamerę
+// POST /api/cameras
+func (h *CameraHandler) CreateCamera(w http.ResponseWriter, r *http.Request) {
+	var req CameraRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	camera, err := h.cameraService.CreateCamera(req.Name, req.Location)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Kamera została utworzona",
+		"camera":  camera,
+	})
+}
+
+// UpdateCamera - aktualizuje kamerę
+// PUT /api/cameras/{id}
+func (h *CameraHandler) UpdateCamera(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cameraID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID kamery", http.StatusBadRequest)
+		return
+	}
+
+	var req CameraRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	camera, err := h.cameraService.UpdateCamera(uint(cameraID), req.Name, req.Location)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Kamera nie znaleziona", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Kamera została zaktualizowana",
+		"camera":  camera,
+	})
+}
+
+// DeleteCamera - usuwa kamerę
+// DELETE /api/cameras/{id}
+func (h *CameraHandler) DeleteCamera(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cameraID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID kamery", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.cameraService.DeleteCamera(uint(cameraID)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Kamera nie znaleziona", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Kamera została usunięta",
+	})
+}
+
+// GetGameCameras - pobiera wybór kamer dla meczu
+// GET /api/games/{id}/cameras
+func (h *CameraHandler) GetGameCameras(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	gameCameras, err := h.cameraService.GetGameCameras(uint(gameID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"game_id":      gameID,
+		"game_cameras": gameCameras,
+	})
+}
+
+// SetGameCameras - ustawia wybór kamer dla meczu
+// PUT /api/games/{id}/cameras
+func (h *CameraHandler) SetGameCameras(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Cameras []services.GameCameraSelection `json:"cameras"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	gameCameras, err := h.cameraService.SetGameCameras(uint(gameID), req.Cameras)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"message":      "Kamery meczu zaktualizowane",
+		"game_cameras": gameCameras,
+	})
+}
+
+// SetProgramCamera - ustawia kamerę aktualnie na wizji (używaną m.in. przy wydarzeniach)
+// POST /api/program-camera
+func (h *CameraHandler) SetProgramCamera(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Camera string `json:"camera"` // pusta nazwa = brak kamery na wizji
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Camera != "" && !containsCamera(h.appState.GetAllCameras(), req.Camera) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  "Nieznana kamera: " + req.Camera,
+		})
+		return
+	}
+
+	h.appState.SetProgramCamera(req.Camera)
+	log.Printf("Kamera na wizji: %s", req.Camera)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         "success",
+		"program_camera": req.Camera,
+	})
+}
+
+// containsCamera - sprawdza czy kamera jest na liście
+func containsCamera(cameras []string, name string) bool {
+	for _, camera := range cameras {
+		if camera == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// CrewHandler - handler sędziów, ekipy TV i obsady meczów
+type CrewHandler struct {
+	crewService *services.CrewService
+}
+
+// NewCrewHandler - tworzy nowy handler obsady
+func NewCrewHandler(crewService *services.CrewService) *CrewHandler {
+	return &CrewHandler{
+		crewService: crewService,
+	}
+}
+
+// writeCrewError - odpowiedź błędu (404 dla nieistniejącego rekordu)
+func writeCrewError(w http.ResponseWriter, err error, status int) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		status = http.StatusNotFound
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.APIResponse{
+		Status: "error",
+		Error:  err.Error(),
+	})
+}
+
+// ListReferees - lista sędziów
+// GET /api/referees
+func (h *CrewHandler) ListReferees(w http.ResponseWriter, r *http.Request) {
+	referees, err := h.crewService.ListReferees()
+	if err != nil {
+		writeCrewError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"referees": referees,
+		"count":    len(referees),
+	})
+}
+
+// CreateReferee - dodaje sędziego
+// POST /api/referees
+func (h *CrewHandler) CreateReferee(w http.ResponseWriter, r *http.Request) {
+	var req models.CrewMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	referee, err := h.crewService.CreateReferee(req)
+	if err != nil {
+		writeCrewError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"referee": referee,
+	})
+}
+
+// UpdateReferee - aktualizuje sędziego
+// PUT /api/referees/{id}
+func (h *CrewHandler) UpdateReferee(w http.ResponseWriter, r *http.Request) {
+	refereeID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID sędziego", http.StatusBadRequest)
+		return
+	}
+
+	var req models.CrewMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	referee, err := h.crewService.UpdateReferee(uint(refereeID), req)
+	if err != nil {
+		writeCrewError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"referee": referee,
+	})
+}
+
+// DeleteReferee - usuwa sędziego
+// DELETE /api/referees/{id}
+func (h *CrewHandler) DeleteReferee(w http.ResponseWriter, r *http.Request) {
+	refereeID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID sędziego", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.crewService.DeleteReferee(uint(refereeID)); err != nil {
+		writeCrewError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Sędzia został usunięty",
+	})
+}
+
+// ListRefereeRoles - role sędziów
+// GET /api/referee-roles
+func (h *CrewHandler) ListRefereeRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.crewService.ListRefereeRoles()
+	if err != nil {
+		writeCrewError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"roles":  roles,
+	})
+}
+
+// ListTVStaff - lista ekipy TV
+// GET /api/tv-staff
+func (h *CrewHandler) ListTVStaff(w http.ResponseWriter, r *http.Request) {
+	staff, err := h.crewService.ListTVStaff()
+	if err != nil {
+		writeCrewError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"tv_staff": staff,
+		"count":    len(staff),
+	})
+}
+
+// CreateTVStaff - dodaje członka ekipy TV
+// POST /api/tv-staff
+func (h *CrewHandler) CreateTVStaff(w http.ResponseWriter, r *http.Request) {
+	var req models.CrewMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	staff, err := h.crewService.CreateTVStaff(req)
+	if err != nil {
+		writeCrewError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"tv_staff": staff,
+	})
+}
+
+// UpdateTVStaff - aktualizuje członka ekipy TV
+// PUT /api/tv-staff/{id}
+func (h *CrewHandler) UpdateTVStaff(w http.ResponseWriter, r *http.Request) {
+	staffID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID członka ekipy TV", http.StatusBadRequest)
+		return
+	}
+
+	var req models.CrewMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	staff, err := h.crewService.UpdateTVStaff(uint(staffID), req)
+	if err != nil {
+		writeCrewError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"tv_staff": staff,
+	})
+}
+
+// DeleteTVStaff - usuwa członka ekipy TV
+// DELETE /api/tv-staff/{id}
+func (h *CrewHandler) DeleteTVStaff(w http.ResponseWriter, r *http.Request) {
+	staffID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID członka ekipy TV", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.crewService.DeleteTVStaff(uint(staffID)); err != nil {
+		writeCrewError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Członek ekipy TV został usunięty",
+	})
+}
+
+// ListTVStaffRoles - role ekipy TV
+// GET /api/tv-staff-roles
+func (h *CrewHandler) ListTVStaffRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.crewService.ListTVStaffRoles()
+	if err != nil {
+		writeCrewError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"roles":  roles,
+	})
+}
+
+// GetGameReferees - sędziowie meczu
+// GET /api/games/{id}/referees
+func (h *CrewHandler) GetGameReferees(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	referees, err := h.crewService.GetGameReferees(uint(gameID))
+	if err != nil {
+		writeCrewError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"referees": referees,
+	})
+}
+
+// SetGameReferees - zastępuje sędziów meczu (?force=true zapisuje mimo kolizji terminów)
+// PUT /api/games/{id}/referees
+func (h *CrewHandler) SetGameReferees(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	var assignments []models.CrewAssignment
+	if err := json.NewDecoder(r.Body).Decode(&assignments); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	force := r.URL.Query().Get("force") == "true"
+	referees, conflicts, err := h.crewService.SetGameReferees(uint(gameID), assignments, force)
+	if errors.Is(err, services.ErrCrewConflict) {
+		writeCrewConflicts(w, conflicts)
+		return
+	}
+	if err != nil {
+		writeCrewError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"referees":  referees,
+		"conflicts": conflicts,
+	})
+}
+
+// GetGameTVStaff - ekipa TV meczu
+// GET /api/games/{id}/tv-staff
+func (h *CrewHandler) GetGameTVStaff(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	staff, err := h.crewService.GetGameTVStaff(uint(gameID))
+	if err != nil {
+		writeCrewError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"tv_staff": staff,
+	})
+}
+
+// SetGameTVStaff - zastępuje ekipę TV meczu (?force=true zapisuje mimo kolizji terminów)
+// PUT /api/games/{id}/tv-staff
+func (h *CrewHandler) SetGameTVStaff(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	var assignments []models.CrewAssignment
+	if err := json.NewDecoder(r.Body).Decode(&assignments); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	force := r.URL.Query().Get("force") == "true"
+	staff, conflicts, err := h.crewService.SetGameTVStaff(uint(gameID), assignments, force)
+	if errors.Is(err, services.ErrCrewConflict) {
+		writeCrewConflicts(w, conflicts)
+		return
+	}
+	if err != nil {
+		writeCrewError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"tv_staff":  staff,
+		"conflicts": conflicts,
+	})
+}
+
+// writeCrewConflicts - 409 z listą kolizji (zapis możliwy z ?force=true)
+func writeCrewConflicts(w http.ResponseWriter, conflicts []models.CrewConflict) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "error",
+		"error":     services.ErrCrewConflict.Error(),
+		"conflicts": conflicts,
+	})
+}
+
+// GetCallSheet - karta realizacji meczu do wydruku (?format=json zwraca dane)
+// GET /api/games/{id}/call-sheet
+func (h *CrewHandler) GetCallSheet(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	sheet, err := h.crewService.GetCallSheet(uint(gameID))
+	if err != nil {
+		writeCrewError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     "success",
+			"call_sheet": sheet,
+		})
+		return
+	}
+
+	tmpl, err := template.ParseFiles("web/templates/call_sheet.html")
+	if err != nil {
+		log.Printf("Błąd wczytywania szablonu karty realizacji: %v", err)
+		http.Error(w, "Błąd szablonu karty realizacji", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, sheet); err != nil {
+		log.Printf("Błąd renderowania karty realizacji: %v", err)
+	}
+}
@@ -5,17 +5,20 @@ import (
 	"net/http"
 	"recorder-server/internal/database"
 	"recorder-server/internal/models"
+	"recorder-server/internal/services"
 )
 
 // DatabaseHandler - handler dla operacji na bazach danych
 type DatabaseHandler struct {
-	manager *database.Manager
+	manager       *database.Manager
+	cameraService *services.CameraService
 }
 
 // NewDatabaseHandler - tworzy nowy handler bazy danych
-func NewDatabaseHandler(manager *database.Manager) *DatabaseHandler {
+func NewDatabaseHandler(manager *database.Manager, cameraService *services.CameraService) *DatabaseHandler {
 	return &DatabaseHandler{
-		manager: manager,
+		manager:       manager,
+		cameraService: cameraService,
 	}
 }
 
@@ -84,6 +87,9 @@ func (h *DatabaseHandler) SwitchDatabase(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Kamery pochodzą z bazy nowych rozgrywek
+	h.cameraService.RefreshAppState()
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":           "success",
@@ -141,6 +147,10 @@ func (h *DatabaseHandler) CreateDatabase(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if req.SwitchTo {
+		h.cameraService.RefreshAppState()
+	}
+
 	response := map[string]interface{}{
 		"status":        "success",
 		"database_name": req.DatabaseName,
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// DisciplineHandler - handler kartek, zawieszeń i dyskwalifikacji
+type DisciplineHandler struct {
+	disciplineService *services.DisciplineService
+}
+
+// NewDisciplineHandler - tworzy nowy handler dyscyplinarny
+func NewDisciplineHandler(disciplineService *services.DisciplineService) *DisciplineHandler {
+	return &DisciplineHandler{
+		disciplineService: disciplineService,
+	}
+}
+
+// GetRules - reguły dyscyplinarne rozgrywek
+// GET /api/discipline/rules
+func (h *DisciplineHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.disciplineService.GetRules()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"rules":  rules,
+	})
+}
+
+// ListDiscipline - kartki i zawieszenia zawodników (?team_id= filtruje drużynę)
+// GET /api/discipline
+func (h *DisciplineHandler) ListDiscipline(w http.ResponseWriter, r *http.Request) {
+	var teamID uint64
+	if value := r.URL.Query().Get("team_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			http.Error(w, "Nieprawidłowe ID drużyny", http.StatusBadRequest)
+			return
+		}
+		teamID = parsed
+	}
+
+	players, err := h.disciplineService.ListDiscipline(uint(teamID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"players": players,
+		"count":   len(players),
+	})
+}
+
+// GetPlayerDiscipline - kartki i zawieszenie zawodnika
+// GET /api/players/{id}/discipline
+func (h *DisciplineHandler) GetPlayerDiscipline(w http.ResponseWriter, r *http.Request) {
+	playerID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID zawodnika", http.StatusBadRequest)
+		return
+	}
+
+	discipline, err := h.disciplineService.GetPlayerDiscipline(uint(playerID))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"discipline": discipline,
+	})
+}
+
+// GetSuspended - zawodnicy drużyn meczu zawieszeni na ten mecz
+// GET /api/games/{id}/suspended
+func (h *DisciplineHandler) GetSuspended(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	players, err := h.disciplineService.SuspendedForGame(uint(gameID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"players": players,
+	})
+}
+
+// parseGameTeam - odczytuje ID meczu i drużyny ze ścieżki
+func parseGameTeam(r *http.Request) (uint, uint, error) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	teamID, err := strconv.ParseUint(vars["teamId"], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(gameID), uint(teamID), nil
+}
+
+// Disqualify - dyskwalifikuje drużynę w meczu (walkower)
+// POST /api/games/{id}/dsq/{teamId}
+func (h *DisciplineHandler) Disqualify(w http.ResponseWriter, r *http.Request) {
+	gameID, teamID, err := parseGameTeam(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID", http.StatusBadRequest)
+		return
+	}
+
+	score, err := h.disciplineService.Disqualify(gameID, teamID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"score":  score,
+	})
+}
+
+// ClearDisqualification - zdejmuje dyskwalifikację drużyny
+// DELETE /api/games/{id}/dsq/{teamId}
+func (h *DisciplineHandler) ClearDisqualification(w http.ResponseWriter, r *http.Request) {
+	gameID, teamID, err := parseGameTeam(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID", http.StatusBadRequest)
+		return
+	}
+
+	score, err := h.disciplineService.ClearDisqualification(gameID, teamID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"score":  score,
+	})
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// EventHandler - handler wydarzeń meczu
+type EventHandler struct {
+	eventService *services.EventService
+	operationLog *services.OperationLogService
+}
+
+// NewEventHandler - tworzy nowy handler wydarzeń (zmiany zapisywane w dzienniku operacji)
+func NewEventHandler(eventService *services.EventService, operationLog *services.OperationLogService) *EventHandler {
+	return &EventHandler{
+		eventService: eventService,
+		operationLog: operationLog,
+	}
+}
+
+// parseGameEventIDs - odczytuje ID meczu i (opcjonalnie) ID wydarzenia ze ścieżki
+func parseGameEventIDs(r *http.Request) (uint, uint, error) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	if vars["eventId"] == "" {
+		return uint(gameID), 0, nil
+	}
+	eventID, err := strconv.ParseUint(vars["eventId"], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(gameID), uint(eventID), nil
+}
+
+// ListEventTypes - lista typów wydarzeń
+// GET /api/event-types
+func (h *EventHandler) ListEventTypes(w http.ResponseWriter, r *http.Request) {
+	eventTypes, err := h.eventService.ListEventTypes()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":      "success",
+		"event_types": eventTypes,
+		"count":       len(eventTypes),
+	})
+}
+
+// CreateEventType - tworzy typ wydarzenia
+// POST /api/event-types
+func (h *EventHandler) CreateEventType(w http.ResponseWriter, r *http.Request) {
+	var eventType models.EventType
+	if err := json.NewDecoder(r.Body).Decode(&eventType); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.eventService.CreateEventType(eventType)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"event_type": created,
+	})
+}
+
+// ListEvents - lista wydarzeń meczu
+// GET /api/games/{id}/events
+func (h *EventHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	gameID, _, err := parseGameEventIDs(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.eventService.ListEvents(gameID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"game_id": gameID,
+		"events":  events,
+		"count":   len(events),
+	})
+}
+
+// GetEvent - pobiera wydarzenie meczu
+// GET /api/games/{id}/events/{eventId}
+func (h *EventHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
+	gameID, eventID, err := parseGameEventIDs(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventService.GetEvent(gameID, eventID)
+	if err != nil {
+		http.Error(w, "Wydarzenie nie znalezione", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"event":  event,
+	})
+}
+
+// CreateEvent - dodaje wydarzenie (część meczu, czas i kamera uzupełniane automatycznie)
+// POST /api/games/{id}/events
+func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	gameID, _, err := parseGameEventIDs(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	var req models.EventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.operationLog.CreateEvent(gameID, req, requestActor(r))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"event":  event,
+	})
+}
+
+// UpdateEvent - aktualizuje wydarzenie meczu
+// PUT /api/games/{id}/events/{eventId}
+func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
+	gameID, eventID, err := parseGameEventIDs(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.EventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.operationLog.UpdateEvent(gameID, eventID, req, requestActor(r))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"event":  event,
+	})
+}
+
+// DeleteEvent - usuwa wydarzenie meczu
+// DELETE /api/games/{id}/events/{eventId}
+func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	gameID, eventID, err := parseGameEventIDs(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.operationLog.DeleteEvent(gameID, eventID, requestActor(r)); err != nil {
+		http.Error(w, "Wydarzenie nie znalezione", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Wydarzenie usunięte",
+	})
+}
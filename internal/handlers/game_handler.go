@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GameHandler - handler meczów
+type GameHandler struct {
+	gameService *services.GameService
+}
+
+// NewGameHandler - tworzy nowy handler meczów
+func NewGameHandler(gameService *services.GameService) *GameHandler {
+	return &GameHandler{
+		gameService: gameService,
+	}
+}
+
+// ListGames - lista meczów (opcjonalnie ?group_id=)
+// GET /api/games
+func (h *GameHandler) ListGames(w http.ResponseWriter, r *http.Request) {
+	var groupID uint64
+	if value := r.URL.Query().Get("group_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			http.Error(w, "Nieprawidłowe ID grupy", http.StatusBadRequest)
+			return
+		}
+		groupID = parsed
+	}
+
+	games, err := h.gameService.ListGames(uint(groupID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"games":  games,
+		"count":  len(games),
+	})
+}
+
+// GetGame - mecz z częściami meczu i drużynami
+// GET /api/games/{id}
+func (h *GameHandler) GetGame(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	game, err := h.gameService.GetGame(uint(gameID))
+	if err != nil {
+		http.Error(w, "Mecz nie znaleziony", http.StatusNotFound)
+		return
+	}
+
+	gameTeams, err := h.gameService.GetGameTeams(game.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"game":   game,
+		"teams":  gameTeams,
+	})
+}
+
+// CreateGame - tworzy mecz; części meczu i sloty wartości są tworzone z presetu
+// POST /api/games
+func (h *GameHandler) CreateGame(w http.ResponseWriter, r *http.Request) {
+	var req models.GameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	game, err := h.gameService.CreateGame(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"game":   game,
+	})
+}
+
+// InitializeGame - uzupełnia brakujące części meczu i sloty wartości z presetu
+// POST /api/games/{id}/initialize
+func (h *GameHandler) InitializeGame(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.gameService.InitializeGame(uint(gameID)); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	game, err := h.gameService.GetGame(uint(gameID))
+	if err != nil {
+		http.Error(w, "Mecz nie znaleziony", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"game":   game,
+	})
+}
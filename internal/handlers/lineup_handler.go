@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// LineupHandler - handler kadr meczowych i składów
+type LineupHandler struct {
+	lineupService *services.LineupService
+}
+
+// NewLineupHandler - tworzy nowy handler składów
+func NewLineupHandler(lineupService *services.LineupService) *LineupHandler {
+	return &LineupHandler{
+		lineupService: lineupService,
+	}
+}
+
+// GetLineup - składy obu drużyn meczu
+// GET /api/games/{id}/lineup
+func (h *LineupHandler) GetLineup(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	lineup, err := h.lineupService.GetLineup(uint(gameID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"lineup": lineup,
+	})
+}
+
+// SetSquad - zastępuje kadrę meczową drużyny
+// PUT /api/games/{id}/lineup/{teamId}
+func (h *LineupHandler) SetSquad(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+	teamID, err := strconv.ParseUint(vars["teamId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID drużyny", http.StatusBadRequest)
+		return
+	}
+
+	var req models.SquadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	lineup, err := h.lineupService.SetSquad(uint(gameID), uint(teamID), req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"lineup": lineup,
+	})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"recorder-server/internal/auth"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// OperationHandler - handler dziennika operacji meczu (historia, cofnij, ponów)
+type OperationHandler struct {
+	operationLog *services.OperationLogService
+}
+
+// NewOperationHandler - tworzy nowy handler dziennika operacji
+func NewOperationHandler(operationLog *services.OperationLogService) *OperationHandler {
+	return &OperationHandler{
+		operationLog: operationLog,
+	}
+}
+
+// requestActor - kto wykonuje żądanie (tożsamość tokenu API)
+func requestActor(r *http.Request) services.Actor {
+	if identity := auth.IdentityFromContext(r.Context()); identity != nil {
+		return services.Actor{Name: identity.Name, Role: string(identity.Role)}
+	}
+	return services.Actor{}
+}
+
+// ListOperations - dziennik operacji meczu (kto, co i kiedy)
+// GET /api/games/{id}/operations?limit=50
+func (h *OperationHandler) ListOperations(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	operations, err := h.operationLog.ListOperations(uint(gameID), limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"game_id":    gameID,
+		"operations": operations,
+		"count":      len(operations),
+	})
+}
+
+// Undo - cofa ostatnie operacje meczu
+// POST /api/games/{id}/undo?steps=1
+func (h *OperationHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	h.step(w, r, h.operationLog.Undo)
+}
+
+// Redo - ponawia cofnięte operacje meczu
+// POST /api/games/{id}/redo?steps=1
+func (h *OperationHandler) Redo(w http.ResponseWriter, r *http.Request) {
+	h.step(w, r, h.operationLog.Redo)
+}
+
+// step - wspólna obsługa cofania i ponawiania
+func (h *OperationHandler) step(w http.ResponseWriter, r *http.Request, apply func(uint, int, services.Actor) ([]models.GameOperation, error)) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	steps := 1
+	if value := r.URL.Query().Get("steps"); value != "" {
+		steps, err = strconv.Atoi(value)
+		if err != nil || steps < 1 || steps > services.UndoDepth {
+			http.Error(w, "Nieprawidłowa liczba kroków", http.StatusBadRequest)
+			return
+		}
+	}
+
+	operations, err := apply(uint(gameID), steps, requestActor(r))
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     "error",
+			"error":      err.Error(),
+			"operations": operations, // kroki wykonane przed błędem
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"operations": operations,
+	})
+}
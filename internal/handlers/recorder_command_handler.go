@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"recorder-server/internal/auth"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// RecorderCommandHandler - handler poleceń dla rejestratorów kamer
+type RecorderCommandHandler struct {
+	commandService *services.RecorderCommandService
+}
+
+// NewRecorderCommandHandler - tworzy nowy handler poleceń rejestratorów
+func NewRecorderCommandHandler(commandService *services.RecorderCommandService) *RecorderCommandHandler {
+	return &RecorderCommandHandler{
+		commandService: commandService,
+	}
+}
+
+// IssueCommand - wysyła polecenie do rejestratorów (split_file, mark_clip, set_quality, take_snapshot)
+// POST /api/recorders/commands
+func (h *RecorderCommandHandler) IssueCommand(w http.ResponseWriter, r *http.Request) {
+	var req models.RecorderCommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	issuedBy := ""
+	if identity := auth.IdentityFromContext(r.Context()); identity != nil {
+		issuedBy = identity.Name
+	}
+
+	command, err := h.commandService.Issue(req, issuedBy)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"command": command,
+	})
+}
+
+// AckCommand - zapisuje potwierdzenie polecenia (alternatywa dla eventu command_ack)
+// POST /api/recorders/commands/ack
+func (h *RecorderCommandHandler) AckCommand(w http.ResponseWriter, r *http.Request) {
+	var data models.RecorderCommandAckData
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	ack, err := h.commandService.Ack(data)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"ack":    ack,
+	})
+}
+
+// ListCommands - log poleceń z potwierdzeniami
+// GET /api/recorders/commands?limit=50
+func (h *RecorderCommandHandler) ListCommands(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	commands, err := h.commandService.ListCommands(limit)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"commands": commands,
+		"count":    len(commands),
+	})
+}
+
+// UploadSnapshot - przyjmuje miniaturę od rejestratora (multipart: camera_name, command_id, file)
+// POST /api/recorders/snapshots
+func (h *RecorderCommandHandler) UploadSnapshot(w http.ResponseWriter, r *http.Request) {
+	// Maksymalnie 10 MB
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Brak pliku miniatury", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	commandID, _ := strconv.ParseUint(r.FormValue("command_id"), 10, 32)
+
+	fileName, err := h.commandService.SaveSnapshot(r.FormValue("camera_name"), uint(commandID),
+		filepath.Ext(header.Filename), file)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"file":   fileName,
+		"url":    "/api/recorders/snapshots/" + fileName,
+	})
+}
+
+// GetSnapshot - zwraca plik miniatury
+// GET /api/recorders/snapshots/{file}
+func (h *RecorderCommandHandler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	path, err := h.commandService.SnapshotPath(mux.Vars(r)["file"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.ServeFile(w, r, path)
+}
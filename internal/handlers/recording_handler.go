@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// RecordingHandler - handler dla sesji nagrywania i plików kamer
+type RecordingHandler struct {
+	recordingService *services.RecordingService
+	footageService   *services.FootageService
+}
+
+// maxChunkSize - maksymalny rozmiar fragmentu przesyłanego pliku
+const maxChunkSize = 64 << 20
+
+// NewRecordingHandler - tworzy nowy handler nagrań
+func NewRecordingHandler(recordingService *services.RecordingService, footageService *services.FootageService) *RecordingHandler {
+	return &RecordingHandler{
+		recordingService: recordingService,
+		footageService:   footageService,
+	}
+}
+
+// ReportFile - zapisuje plik zgłoszony przez rejestrator kamery
+// POST /api/recordings/files
+func (h *RecordingHandler) ReportFile(w http.ResponseWriter, r *http.Request) {
+	var report models.RecordingFileReport
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	file, err := h.recordingService.AddFile(report)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"file":   file,
+	})
+}
+
+// ListSessions - lista sesji nagrywania
+// GET /api/recordings?game_id=1
+func (h *RecordingHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	var gameID *uint
+	if gameIDStr := r.URL.Query().Get("game_id"); gameIDStr != "" {
+		id, err := strconv.ParseUint(gameIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Nieprawidłowe game_id", http.StatusBadRequest)
+			return
+		}
+		value := uint(id)
+		gameID = &value
+	}
+
+	sessions, err := h.recordingService.ListSessions(gameID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// GetSession - pobiera sesję nagrywania z plikami
+// GET /api/recordings/{id}
+func (h *RecordingHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID sesji", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.recordingService.GetSession(uint(sessionID))
+	if err != nil {
+		http.Error(w, "Sesja nagrywania nie znaleziona", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"session": session,
+	})
+}
+
+// GetGameFootage - zwraca wszystkie nagrania meczu (sesje z plikami, w kolejności startu)
+// GET /api/games/{id}/footage
+func (h *RecordingHandler) GetGameFootage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	id := uint(gameID)
+	sessions, err := h.recordingService.ListSessions(&id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	fileCount := 0
+	for _, session := range sessions {
+		fileCount += len(session.Files)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"game_id":    id,
+		"sessions":   sessions,
+		"file_count": fileCount,
+	})
+}
+
+// parseFileID - odczytuje ID pliku nagrania ze ścieżki
+func parseFileID(r *http.Request) (uint, error) {
+	fileID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(fileID), nil
+}
+
+// writeUploadResult - zapisuje odpowiedź z aktualnym stanem przesyłania
+func writeUploadResult(w http.ResponseWriter, status *models.FootageUploadStatus, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		code := http.StatusBadRequest
+		var offsetErr *services.ErrUploadOffset
+		if errors.As(err, &offsetErr) {
+			code = http.StatusConflict
+		}
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "error",
+			"error":  err.Error(),
+			"upload": status,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"upload": status,
+	})
+}
+
+// InitUpload - rozpoczyna lub wznawia przesyłanie pliku (zwraca offset do kontynuacji)
+// POST /api/recordings/files/{id}/upload
+func (h *RecordingHandler) InitUpload(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseFileID(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID pliku", http.StatusBadRequest)
+		return
+	}
+
+	var init models.FootageUploadInit
+	if err := json.NewDecoder(r.Body).Decode(&init); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.footageService.InitUpload(fileID, init)
+	writeUploadResult(w, status, err)
+}
+
+// UploadChunk - przyjmuje fragment pliku (surowe bajty) od podanego offsetu
+// PUT /api/recordings/files/{id}/upload?offset=0 (nagłówek X-Chunk-SHA256 opcjonalny)
+func (h *RecordingHandler) UploadChunk(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseFileID(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID pliku", http.StatusBadRequest)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "Nieprawidłowy offset", http.StatusBadRequest)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxChunkSize)
+	status, err := h.footageService.WriteChunk(fileID, offset, r.Header.Get("X-Chunk-SHA256"), body)
+	if err != nil {
+		// Przy konflikcie offsetu klient dostaje aktualny stan do wznowienia
+		status, _ = h.footageService.GetUploadStatus(fileID)
+	}
+	writeUploadResult(w, status, err)
+}
+
+// CompleteUpload - kończy przesyłanie i weryfikuje rozmiar oraz sumę SHA-256
+// POST /api/recordings/files/{id}/upload/complete
+func (h *RecordingHandler) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseFileID(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID pliku", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.footageService.CompleteUpload(fileID)
+	writeUploadResult(w, status, err)
+}
+
+// GetUploadStatus - stan przesyłania pliku (offset do wznowienia)
+// GET /api/recordings/files/{id}/upload
+func (h *RecordingHandler) GetUploadStatus(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseFileID(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID pliku", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.footageService.GetUploadStatus(fileID)
+	if err != nil {
+		http.Error(w, "Plik nagrania nie znaleziony", http.StatusNotFound)
+		return
+	}
+	writeUploadResult(w, status, nil)
+}
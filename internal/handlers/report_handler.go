@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ReportHandler - handler protokołów meczów
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+// NewReportHandler - tworzy nowy handler protokołów
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// GetReport - generuje protokół meczu, zapisuje go w katalogu rozgrywek i zwraca plik
+// GET /api/games/{id}/report?format=html|pdf|json
+func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = models.ReportFormatHTML
+	}
+
+	if format == "json" {
+		report, err := h.reportService.BuildReport(uint(gameID))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(models.APIResponse{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"report": report,
+		})
+		return
+	}
+
+	if format != models.ReportFormatHTML && format != models.ReportFormatPDF {
+		http.Error(w, "Nieprawidłowy format protokołu", http.StatusBadRequest)
+		return
+	}
+
+	path, err := h.reportService.Generate(uint(gameID), format)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	if format == models.ReportFormatPDF {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(path)))
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	http.ServeFile(w, r, path)
+}
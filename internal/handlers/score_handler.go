@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ScoreHandler - handler wyniku meczu na żywo
+type ScoreHandler struct {
+	scoringService *services.ScoringService
+	operationLog   *services.OperationLogService
+}
+
+// NewScoreHandler - tworzy nowy handler wyniku (zmiany zapisywane w dzienniku operacji)
+func NewScoreHandler(scoringService *services.ScoringService, operationLog *services.OperationLogService) *ScoreHandler {
+	return &ScoreHandler{
+		scoringService: scoringService,
+		operationLog:   operationLog,
+	}
+}
+
+// writeScoreResult - zapisuje odpowiedź ze stanem wyniku
+func writeScoreResult(w http.ResponseWriter, score *models.ScoreUpdate, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"score":  score,
+	})
+}
+
+// GetScore - aktualny wynik meczu
+// GET /api/games/{id}/score
+func (h *ScoreHandler) GetScore(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	score, err := h.scoringService.GetScore(uint(gameID))
+	writeScoreResult(w, score, err)
+}
+
+// AdjustScore - zmienia wartość o delta (np. +1 bramka, -1 korekta)
+// POST /api/games/{id}/score/adjust
+func (h *ScoreHandler) AdjustScore(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ScoreAdjustRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	score, err := h.operationLog.AdjustScore(uint(gameID), req, requestActor(r))
+	writeScoreResult(w, score, err)
+}
+
+// SetScore - ustawia wartość bezwzględną
+// PUT /api/games/{id}/score
+func (h *ScoreHandler) SetScore(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ScoreAdjustRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	score, err := h.operationLog.SetScore(uint(gameID), req, requestActor(r))
+	writeScoreResult(w, score, err)
+}
+
+// RecomputeScore - przelicza GameValue z wartości części meczu
+// POST /api/games/{id}/score/recompute
+func (h *ScoreHandler) RecomputeScore(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	score, err := h.scoringService.Recompute(uint(gameID))
+	writeScoreResult(w, score, err)
+}
@@ -5,17 +5,22 @@ import (
 	"net/http"
 	"recorder-server/internal/database"
 	"recorder-server/internal/models"
+	"recorder-server/internal/services"
 )
 
 // SessionHandler - handler dla aktywnej sesji
 type SessionHandler struct {
-	manager *database.Manager
+	manager        *database.Manager
+	cameraService  *services.CameraService
+	commandService *services.RecorderCommandService
 }
 
 // NewSessionHandler - tworzy nowy handler sesji
-func NewSessionHandler(manager *database.Manager) *SessionHandler {
+func NewSessionHandler(manager *database.Manager, cameraService *services.CameraService, commandService *services.RecorderCommandService) *SessionHandler {
 	return &SessionHandler{
-		manager: manager,
+		manager:        manager,
+		cameraService:  cameraService,
+		commandService: commandService,
 	}
 }
 
@@ -71,6 +76,17 @@ func (h *SessionHandler) SetActiveGame(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Błąd zapisywania sesji", http.StatusInternalServerError)
 		return
 	}
+
+	// Aktywny mecz jest w trakcie - tabela na żywo (table_projection) liczy jego bramki
+	if req.GameID != nil {
+		if err := services.MarkGameInProgress(db, *req.GameID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Zmiana meczu zmienia zestaw używanych kamer
+	h.cameraService.RefreshAppState()
 	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -102,12 +118,18 @@ func (h *SessionHandler) SetActiveGamePart(w http.ResponseWriter, r *http.Reques
 	}
 	
 	// Aktualizuj GamePartID
+	partChanged := !sameGamePart(session.GamePartID, req.GamePartID)
 	session.GamePartID = req.GamePartID
 	
 	if err := db.Save(&session).Error; err != nil {
 		http.Error(w, "Błąd zapisywania sesji", http.StatusInternalServerError)
 		return
 	}
+
+	// Nowa część meczu = nowy segment nagrania
+	if partChanged {
+		h.commandService.SplitOnPeriodChange()
+	}
 	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -137,6 +159,8 @@ func (h *SessionHandler) ClearActiveSession(w http.ResponseWriter, r *http.Reque
 		http.Error(w, "Błąd zapisywania sesji", http.StatusInternalServerError)
 		return
 	}
+
+	h.cameraService.RefreshAppState()
 	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -145,3 +169,11 @@ func (h *SessionHandler) ClearActiveSession(w http.ResponseWriter, r *http.Reque
 		"session": session,
 	})
 }
+
+// sameGamePart - porównuje identyfikatory części meczu (nil = brak części)
+func sameGamePart(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -9,17 +9,20 @@ import (
 	"recorder-server/config"
 	"recorder-server/internal/database"
 	"recorder-server/internal/models"
+	"recorder-server/internal/services"
 )
 
 // SetupHandler - handler dla procesu setup
 type SetupHandler struct {
-	dbManager *database.Manager
+	dbManager     *database.Manager
+	cameraService *services.CameraService
 }
 
 // NewSetupHandler - tworzy nowy handler setup
-func NewSetupHandler(dbManager *database.Manager) *SetupHandler {
+func NewSetupHandler(dbManager *database.Manager, cameraService *services.CameraService) *SetupHandler {
 	return &SetupHandler{
-		dbManager: dbManager,
+		dbManager:     dbManager,
+		cameraService: cameraService,
 	}
 }
 
@@ -375,6 +378,9 @@ func (h *SetupHandler) CreateCompetition(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Załaduj kamery z presetu do stanu aplikacji
+	h.cameraService.RefreshAppState()
+
 	// Przekieruj na stronę zespołów zamiast głównej
 	http.Redirect(w, r, "/teams", http.StatusSeeOther)
 }
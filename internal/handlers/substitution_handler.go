@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// SubstitutionHandler - handler zmian zawodników
+type SubstitutionHandler struct {
+	substitutionService *services.SubstitutionService
+}
+
+// NewSubstitutionHandler - tworzy nowy handler zmian
+func NewSubstitutionHandler(substitutionService *services.SubstitutionService) *SubstitutionHandler {
+	return &SubstitutionHandler{
+		substitutionService: substitutionService,
+	}
+}
+
+// parseGameBlock - odczytuje ID meczu i numer bloku zmian ze ścieżki
+func parseGameBlock(r *http.Request) (uint, uint, error) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	block, err := strconv.ParseUint(vars["block"], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(gameID), uint(block), nil
+}
+
+// ListBlocks - bloki zmian meczu (kolejka i wykonane)
+// GET /api/games/{id}/substitutions
+func (h *SubstitutionHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	blocks, err := h.substitutionService.ListBlocks(uint(gameID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"blocks": blocks,
+		"count":  len(blocks),
+	})
+}
+
+// QueueBlock - dodaje blok zmian do kolejki
+// POST /api/games/{id}/substitutions
+func (h *SubstitutionHandler) QueueBlock(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	var req models.SubstitutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+
+	block, err := h.substitutionService.QueueBlock(uint(gameID), req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"block":  block,
+	})
+}
+
+// ExecuteBlock - wykonuje blok zmian z bieżącym czasem stopera
+// POST /api/games/{id}/substitutions/{block}/execute
+func (h *SubstitutionHandler) ExecuteBlock(w http.ResponseWriter, r *http.Request) {
+	gameID, blockNumber, err := parseGameBlock(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID", http.StatusBadRequest)
+		return
+	}
+
+	block, err := h.substitutionService.ExecuteBlock(gameID, blockNumber, requestActor(r))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"block":  block,
+	})
+}
+
+// CancelBlock - usuwa niewykonany blok zmian z kolejki
+// DELETE /api/games/{id}/substitutions/{block}
+func (h *SubstitutionHandler) CancelBlock(w http.ResponseWriter, r *http.Request) {
+	gameID, blockNumber, err := parseGameBlock(r)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.substitutionService.CancelBlock(gameID, blockNumber); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Blok zmian anulowany",
+	})
+}
+
+// GetOnPitch - zawodnicy drużyny aktualnie na boisku
+// GET /api/games/{id}/on-pitch/{teamId}
+func (h *SubstitutionHandler) GetOnPitch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+	teamID, err := strconv.ParseUint(vars["teamId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID drużyny", http.StatusBadRequest)
+		return
+	}
+
+	playerIDs, err := h.substitutionService.OnPitch(uint(gameID), uint(teamID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"team_id":    teamID,
+		"player_ids": playerIDs,
+	})
+}
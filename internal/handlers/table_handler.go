@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"recorder-server/internal/models"
 	"recorder-server/internal/services"
+	"recorder-server/internal/tables"
 	"strconv"
+
+	"github.com/gorilla/mux"
 )
 
 // TableHandler - handler dla operacji na tabelach
@@ -53,6 +57,101 @@ func (h *TableHandler) CalculateTableForGroup(w http.ResponseWriter, r *http.Req
 	})
 }
 
+// GetGroupHistory - pozycje drużyn po każdej kolejce i ruch względem poprzedniej kolejki
+// GET /api/tables/group/{id}/history
+func (h *TableHandler) GetGroupHistory(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID grupy", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.tableService.GroupHistory(uint(groupID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"history": history,
+	})
+}
+
+// GetGroupProjection - tabela "na żywo": trwające mecze liczone tak, jakby skończyły się obecnym wynikiem
+// GET /api/tables/group/{id}/projection
+func (h *TableHandler) GetGroupProjection(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID grupy", http.StatusBadRequest)
+		return
+	}
+
+	projection, err := h.tableService.ProjectedTableForGroup(uint(groupID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"projection": projection,
+	})
+}
+
+// GetTeamScenarios - możliwe miejsca drużyny po pozostałych meczach i minimalne zestawy wyników
+// GET /api/tables/group/{id}/scenarios?team_id=1&max_margin=1
+func (h *TableHandler) GetTeamScenarios(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID grupy", http.StatusBadRequest)
+		return
+	}
+	teamID, err := strconv.ParseUint(r.URL.Query().Get("team_id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Brak lub nieprawidłowe team_id", http.StatusBadRequest)
+		return
+	}
+	maxMargin := 1
+	if marginStr := r.URL.Query().Get("max_margin"); marginStr != "" {
+		maxMargin, err = strconv.Atoi(marginStr)
+		if err != nil || maxMargin < 1 || maxMargin > 5 {
+			http.Error(w, "max_margin musi być liczbą od 1 do 5", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.tableService.TeamScenarios(uint(groupID), uint(teamID), maxMargin)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, tables.ErrInvalidGroupData) || errors.Is(err, tables.ErrTooManyScenarios) {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"scenarios": report,
+	})
+}
+
 // CalculateTableForStage - endpoint do obliczania tabel dla stage
 // GET /api/tables/stage?stage_id=1
 func (h *TableHandler) CalculateTableForStage(w http.ResponseWriter, r *http.Request) {
@@ -157,7 +256,7 @@ func (h *TableHandler) GetCompetitionAlgorithmInfo(w http.ResponseWriter, r *htt
 	})
 }
 
-// CompareTeams - endpoint do porównania dwóch drużyn (debug)
+// CompareTeams - porównanie dwóch drużyn krok po kroku (dlaczego jedna jest wyżej)
 // GET /api/tables/compare?group_id=1&team1_id=1&team2_id=2
 func (h *TableHandler) CompareTeams(w http.ResponseWriter, r *http.Request) {
 	groupIDStr := r.URL.Query().Get("group_id")
@@ -169,13 +268,21 @@ func (h *TableHandler) CompareTeams(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groupID, _ := strconv.ParseUint(groupIDStr, 10, 32)
-	team1ID, _ := strconv.ParseUint(team1IDStr, 10, 32)
-	team2ID, _ := strconv.ParseUint(team2IDStr, 10, 32)
+	groupID, err1 := strconv.ParseUint(groupIDStr, 10, 32)
+	team1ID, err2 := strconv.ParseUint(team1IDStr, 10, 32)
+	team2ID, err3 := strconv.ParseUint(team2IDStr, 10, 32)
+	if err1 != nil || err2 != nil || err3 != nil {
+		http.Error(w, "Nieprawidłowe ID", http.StatusBadRequest)
+		return
+	}
 
-	result, err := h.tableService.CompareTeamsInGroup(uint(groupID), uint(team1ID), uint(team2ID))
+	comparison, err := h.tableService.CompareTeamsInGroup(uint(groupID), uint(team1ID), uint(team2ID))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, tables.ErrInvalidGroupData) {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(models.APIResponse{
 			Status: "error",
 			Error:  err.Error(),
@@ -184,7 +291,7 @@ func (h *TableHandler) CompareTeams(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var resultText string
-	switch result {
+	switch comparison.Result {
 	case -1:
 		resultText = "Team 1 wyżej"
 	case 0:
@@ -196,7 +303,67 @@ func (h *TableHandler) CompareTeams(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":      "success",
-		"result":      result,
+		"result":      comparison.Result,
 		"result_text": resultText,
+		"comparison":  comparison,
 	})
-}
\ No newline at end of file
+}
+
+// CrossGroupRanking - ranking drużyn z danego miejsca we wszystkich grupach etapu
+// GET /api/tables/stage/ranking?stage_id=1&position=3
+func (h *TableHandler) CrossGroupRanking(w http.ResponseWriter, r *http.Request) {
+	stageID, err := strconv.ParseUint(r.URL.Query().Get("stage_id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe stage_id", http.StatusBadRequest)
+		return
+	}
+	position, err := strconv.Atoi(r.URL.Query().Get("position"))
+	if err != nil || position < 1 {
+		http.Error(w, "Nieprawidłowe position", http.StatusBadRequest)
+		return
+	}
+
+	ranking, err := h.tableService.CrossGroupRanking(uint(stageID), position)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"position": position,
+		"ranking":  ranking,
+	})
+}
+
+// AdvanceStage - przypisuje awansujące drużyny zakończonego etapu do grup następnego etapu
+// POST /api/tables/stage/advance?stage_id=1
+func (h *TableHandler) AdvanceStage(w http.ResponseWriter, r *http.Request) {
+	stageID, err := strconv.ParseUint(r.URL.Query().Get("stage_id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe stage_id", http.StatusBadRequest)
+		return
+	}
+
+	advanced, err := h.tableService.AdvanceStage(uint(stageID))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"advanced": advanced,
+		"count":    len(advanced),
+	})
+}
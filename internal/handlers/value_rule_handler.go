@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"recorder-server/internal/models"
+	"recorder-server/internal/services"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ValueRuleHandler - handler reguł wartości akumulowanych
+type ValueRuleHandler struct {
+	valueRuleService *services.ValueRuleService
+}
+
+// NewValueRuleHandler - tworzy nowy handler reguł wartości
+func NewValueRuleHandler(valueRuleService *services.ValueRuleService) *ValueRuleHandler {
+	return &ValueRuleHandler{
+		valueRuleService: valueRuleService,
+	}
+}
+
+// ListRules - reguły wartości akumulowanych
+// GET /api/value-rules
+func (h *ValueRuleHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.valueRuleService.ListRules()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"rules":  rules,
+		"count":  len(rules),
+	})
+}
+
+// GetRule - reguła dla typu wartości
+// GET /api/value-rules/{valueTypeId}
+func (h *ValueRuleHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	valueTypeID, err := strconv.ParseUint(mux.Vars(r)["valueTypeId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID typu wartości", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.valueRuleService.GetRule(uint(valueTypeID))
+	if err != nil {
+		http.Error(w, "Reguła nie znaleziona", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"rule":   rule,
+	})
+}
+
+// SetRule - zapisuje regułę dla typu wartości
+// PUT /api/value-rules/{valueTypeId}
+func (h *ValueRuleHandler) SetRule(w http.ResponseWriter, r *http.Request) {
+	valueTypeID, err := strconv.ParseUint(mux.Vars(r)["valueTypeId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID typu wartości", http.StatusBadRequest)
+		return
+	}
+
+	var rule models.ValueRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Błąd dekodowania JSON", http.StatusBadRequest)
+		return
+	}
+	rule.ValueTypeID = uint(valueTypeID)
+
+	saved, err := h.valueRuleService.SetRule(rule)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"rule":   saved,
+	})
+}
+
+// DeleteRule - usuwa regułę dla typu wartości
+// DELETE /api/value-rules/{valueTypeId}
+func (h *ValueRuleHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	valueTypeID, err := strconv.ParseUint(mux.Vars(r)["valueTypeId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID typu wartości", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.valueRuleService.DeleteRule(uint(valueTypeID)); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Reguła usunięta",
+	})
+}
+
+// GetAccumulated - wartości akumulowane drużyn w okresach meczu
+// GET /api/games/{id}/accumulated
+func (h *ValueRuleHandler) GetAccumulated(w http.ResponseWriter, r *http.Request) {
+	gameID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Nieprawidłowe ID meczu", http.StatusBadRequest)
+		return
+	}
+
+	accumulated, err := h.valueRuleService.GetAccumulated(uint(gameID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":      "success",
+		"game_id":     gameID,
+		"accumulated": accumulated,
+	})
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Modele używane do komunikacji API (nie są modelami bazy danych)
 
 // RecordStatus - status nagrywania
@@ -7,12 +9,15 @@ type RecordStatus struct {
 	RecordStatus    bool     `json:"record_status"`
 	ActiveCameras   []string `json:"active_cameras"`
 	InactiveCameras []string `json:"inactive_cameras"`
+	AllCameras      []string `json:"all_cameras"`
+	ProgramCamera   string   `json:"program_camera"`
 }
 
 // StartRecordingData - dane dla rozpoczęcia nagrywania
 type StartRecordingData struct {
 	ActiveCameras   []string `json:"active_cameras"`
 	InactiveCameras []string `json:"inactive_cameras"`
+	SessionID       uint     `json:"session_id,omitempty"` // ID sesji nagrywania w bazie
 }
 
 // StopRecordingData - dane dla zatrzymania nagrywania
@@ -35,6 +40,339 @@ type RecordDataResponse struct {
 	CurrentTime     string `json:"current_time"`
 }
 
+// RecordingFileReport - zgłoszenie pliku nagrania przez rejestrator kamery
+type RecordingFileReport struct {
+	SessionID       uint       `json:"session_id"` // 0 = aktualna (lub ostatnia) sesja
+	CameraName      string     `json:"camera_name"`
+	FileName        string     `json:"file_name"`
+	RecordStartTime *time.Time `json:"record_start_time"`
+	RecordStopTime  *time.Time `json:"record_stop_time"`
+	SizeBytes       int64      `json:"size_bytes"`
+	SHA256          string     `json:"sha256,omitempty"` // suma kontrolna pliku (hex)
+}
+
+// FootageUploadInit - rozpoczęcie (lub wznowienie) przesyłania pliku nagrania
+type FootageUploadInit struct {
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// FootageUploadStatus - stan przesyłania pliku (offset do wznowienia)
+type FootageUploadStatus struct {
+	FileID        uint   `json:"file_id"`
+	CameraName    string `json:"camera_name"`
+	FileName      string `json:"file_name"`
+	UploadStatus  string `json:"upload_status"`
+	UploadedBytes int64  `json:"uploaded_bytes"`
+	SizeBytes     int64  `json:"size_bytes"`
+	Percent       int    `json:"percent"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Polecenia dla rejestratorów kamer
+const (
+	RecorderCommandSplitFile    = "split_file"
+	RecorderCommandMarkClip     = "mark_clip"
+	RecorderCommandSetQuality   = "set_quality"
+	RecorderCommandTakeSnapshot = "take_snapshot"
+)
+
+// QualityProfiles - dozwolone profile jakości nagrywania
+var QualityProfiles = []string{"low", "medium", "high", "source"}
+
+// RecorderCommandRequest - żądanie wysłania polecenia do rejestratorów
+type RecorderCommandRequest struct {
+	Command string     `json:"command"`
+	Cameras []string   `json:"cameras"`            // puste = wszystkie aktywne kamery
+	InTime  *time.Time `json:"in_time,omitempty"`  // mark_clip - początek klipu
+	OutTime *time.Time `json:"out_time,omitempty"` // mark_clip - koniec klipu
+	Label   string     `json:"label,omitempty"`    // mark_clip - opis klipu
+	Profile string     `json:"profile,omitempty"`  // set_quality - profil jakości
+}
+
+// RecorderCommandAckData - potwierdzenie polecenia wysyłane przez rejestrator
+type RecorderCommandAckData struct {
+	CommandID  uint   `json:"command_id"`
+	CameraName string `json:"camera_name"`
+	Status     string `json:"status"` // ok, error
+	Message    string `json:"message"`
+}
+
+// EventRequest - utworzenie lub edycja wydarzenia meczu (puste pola uzupełniane automatycznie)
+type EventRequest struct {
+	EventTypeID *uint   `json:"event_type_id"`
+	Name        *string `json:"name"`         // domyślnie nazwa typu wydarzenia
+	GamePartID  *uint   `json:"game_part_id"` // domyślnie aktywna część meczu
+	EventTime   *int    `json:"event_time"`   // domyślnie czas stopera (sekundy)
+	CameraID    *uint   `json:"camera_id"`    // domyślnie kamera na wizji
+	TeamID      *uint   `json:"team_id"`
+	PlayerID    *uint   `json:"player_id"`
+}
+
+// ScoreAdjustRequest - zmiana wartości statystyki drużyny (delta lub wartość bezwzględna)
+type ScoreAdjustRequest struct {
+	TeamID      uint  `json:"team_id"`
+	ValueTypeID uint  `json:"value_type_id"`
+	GamePartID  *uint `json:"game_part_id"` // domyślnie aktywna część meczu
+	Delta       int   `json:"delta"`        // dla adjust
+	Value       *int  `json:"value"`        // dla set
+}
+
+// ScoreGroupTotal - suma wartości w grupie części meczu (GameValueGroup lub TimeGroup)
+type ScoreGroupTotal struct {
+	TeamID      uint `json:"team_id"`
+	ValueTypeID uint `json:"value_type_id"`
+	Group       uint `json:"group"`
+	Value       int  `json:"value"`
+}
+
+// ScoreUpdate - pełny stan wyniku meczu (payload eventu score_update)
+type ScoreUpdate struct {
+	GameID           uint              `json:"game_id"`
+	GameValues       []GameValue       `json:"game_values"`
+	PartValues       []GamePartValue   `json:"part_values"`
+	ValueGroups      []ScoreGroupTotal `json:"value_groups"` // sumy w grupach GameValueGroup
+	TimeGroups       []ScoreGroupTotal `json:"time_groups"`  // sumy w grupach GamePart.TimeGroup
+	ActiveGamePartID *uint             `json:"active_game_part_id"`
+}
+
+// Sposoby zerowania wartości akumulowanych (ValueRule.ResetPer)
+const (
+	ValueResetPerTimeGroup = "time_group" // osobno dla każdej grupy czasowej (np. połowy)
+	ValueResetPerGamePart  = "game_part"  // osobno dla każdej części meczu
+	ValueResetPerGame      = "game"       // bez zerowania w trakcie meczu
+)
+
+// ValueRule - reguła akumulacji typu wartości (Variable "value_rules", np. faule akumulowane w futsalu)
+type ValueRule struct {
+	ValueTypeID uint   `json:"value_type_id"`
+	Label       string `json:"label"`      // np. "Faule akumulowane"
+	ResetPer    string `json:"reset_per"`  // time_group (domyślnie), game_part, game
+	Thresholds  []int  `json:"thresholds"` // progi emitujące threshold_reached, np. [5]
+}
+
+// AccumulatedValue - wartość akumulowana drużyny w okresie (grupie czasowej, części lub całym meczu)
+type AccumulatedValue struct {
+	TeamID        uint   `json:"team_id"`
+	ValueTypeID   uint   `json:"value_type_id"`
+	Label         string `json:"label"`
+	TimeGroup     *uint  `json:"time_group"`
+	GamePartIDs   []uint `json:"game_part_ids"`
+	Value         int    `json:"value"`
+	Reached       []int  `json:"reached"`        // osiągnięte progi
+	NextThreshold *int   `json:"next_threshold"` // najbliższy nieosiągnięty próg
+	IsActive      bool   `json:"is_active"`      // okres zawiera aktywną część meczu
+}
+
+// ThresholdReached - payload eventu threshold_reached
+type ThresholdReached struct {
+	GameID      uint   `json:"game_id"`
+	GamePartID  uint   `json:"game_part_id"`
+	TeamID      uint   `json:"team_id"`
+	ValueTypeID uint   `json:"value_type_id"`
+	Label       string `json:"label"`
+	TimeGroup   *uint  `json:"time_group"`
+	Threshold   int    `json:"threshold"`
+	Value       int    `json:"value"`
+}
+
+// SquadPlayerRequest - zawodnik w kadrze meczowej (puste pola = wartości z karty zawodnika)
+type SquadPlayerRequest struct {
+	PlayerID     uint    `json:"player_id"`
+	Number       *string `json:"number"`
+	PlayerRoleID *uint   `json:"player_role_id"`
+	IsStarter    bool    `json:"is_starter"`
+	IsCaptain    *bool   `json:"is_captain"`
+}
+
+// SquadCoachRequest - trener w kadrze meczowej
+type SquadCoachRequest struct {
+	CoachID     uint  `json:"coach_id"`
+	CoachRoleID *uint `json:"coach_role_id"`
+}
+
+// SquadRequest - pełna kadra meczowa drużyny (zastępuje poprzednią)
+type SquadRequest struct {
+	Players []SquadPlayerRequest `json:"players"`
+	Coaches []SquadCoachRequest  `json:"coaches"`
+}
+
+// SquadLimits - limity kadry meczowej z Variable rozgrywek ("squad"); 0 = bez limitu
+type SquadLimits struct {
+	MaxPlayers  int `json:"max_players"`
+	MaxStarters int `json:"max_starters"`
+	MaxCoaches  int `json:"max_coaches"`
+}
+
+// TeamLineup - skład drużyny w meczu
+type TeamLineup struct {
+	TeamID      uint         `json:"team_id"`
+	Side        int          `json:"side"` // 1=gospodarze, 2=goście
+	Team        Team         `json:"team"`
+	Starters    []GamePlayer `json:"starters"`
+	Substitutes []GamePlayer `json:"substitutes"`
+	Coaches     []GameCoach  `json:"coaches"`
+}
+
+// Lineup - składy obu drużyn (payload eventu lineup_update)
+type Lineup struct {
+	GameID   uint         `json:"game_id"`
+	Teams    []TeamLineup `json:"teams"`
+	Limits   SquadLimits  `json:"limits"`
+	Warnings []string     `json:"warnings,omitempty"` // ostrzeżenia przy zapisie kadry (np. zawieszeni zawodnicy)
+}
+
+// Tryby zmian zawodników (Variable "substitutions.mode")
+const (
+	SubstitutionModeStandard = "standard" // kontrola kto jest na boisku
+	SubstitutionModeRolling  = "rolling"  // zmiany hokejowe (futsal) - bez śledzenia boiska
+)
+
+// SubstitutionPair - para zawodników w bloku zmian
+type SubstitutionPair struct {
+	PlayerInID  uint `json:"player_in_id"`
+	PlayerOutID uint `json:"player_out_id"`
+}
+
+// SubstitutionRequest - blok zmian kolejkowany przez operatora ławki
+type SubstitutionRequest struct {
+	TeamID     uint               `json:"team_id"`
+	GamePartID *uint              `json:"game_part_id"` // domyślnie aktywna część meczu przy wykonaniu
+	Pairs      []SubstitutionPair `json:"pairs"`
+}
+
+// SubstitutionBlock - blok zmian (payload eventu substitution)
+type SubstitutionBlock struct {
+	GameID        uint           `json:"game_id"`
+	TeamID        uint           `json:"team_id"`
+	Block         uint           `json:"block"`
+	GamePartID    uint           `json:"game_part_id"`
+	Time          int            `json:"time"`
+	IsDone        bool           `json:"is_done"`
+	Substitutions []Substitution `json:"substitutions"`
+}
+
+// Rodzaje członków obsady meczu
+const (
+	CrewKindReferee = "referee"
+	CrewKindTVStaff = "tv_staff"
+)
+
+// CrewMemberRequest - dane sędziego lub członka ekipy TV
+type CrewMemberRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	RoleID    uint   `json:"role_id"` // tylko ekipa TV - domyślna rola
+}
+
+// CrewAssignment - przypisanie osoby z rolą do meczu
+type CrewAssignment struct {
+	PersonID uint `json:"person_id"`
+	RoleID   uint `json:"role_id"`
+}
+
+// CrewConflict - osoba przypisana do dwóch nakładających się meczów
+type CrewConflict struct {
+	Kind          string `json:"kind"` // referee, tv_staff
+	PersonID      uint   `json:"person_id"`
+	PersonName    string `json:"person_name"`
+	GameID        uint   `json:"game_id"`
+	OtherGameID   uint   `json:"other_game_id"`
+	OtherDateTime string `json:"other_date_time"`
+}
+
+// CallSheet - karta realizacji meczu (obsada, kamery, godzina rozpoczęcia)
+type CallSheet struct {
+	Game        Game           `json:"game"`
+	Teams       []GameTeam     `json:"teams"`
+	Field       *Field         `json:"field"`
+	KickOff     string         `json:"kick_off"`
+	Referees    []GameReferee  `json:"referees"`
+	TVStaff     []GameTVStaff  `json:"tv_staff"`
+	Cameras     []Camera       `json:"cameras"`
+	Conflicts   []CrewConflict `json:"conflicts"`
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// DisciplineRules - reguły dyscyplinarne rozgrywek (Variable "discipline")
+type DisciplineRules struct {
+	YellowEventTypeIDs []uint `json:"yellow_card_event_types"` // typy wydarzeń liczone jako żółta kartka
+	RedEventTypeIDs    []uint `json:"red_card_event_types"`    // typy wydarzeń liczone jako czerwona kartka
+	YellowCardsPerBan  int    `json:"yellow_cards_per_ban"`    // np. 4 żółte = zawieszenie
+	YellowBanGames     int    `json:"yellow_ban_games"`        // mecze zawieszenia za żółte kartki
+	RedBanGames        int    `json:"red_ban_games"`           // mecze zawieszenia za czerwoną kartkę
+	SecondYellowIsRed  bool   `json:"second_yellow_is_red"`    // dwie żółte w meczu = czerwona
+	WalkoverValueType  uint   `json:"walkover_value_type_id"`  // typ wartości wyniku walkowera (0 = bramki)
+	WalkoverWinner     int    `json:"walkover_winner"`         // wynik walkowera dla drużyny niezdyskwalifikowanej
+	WalkoverLoser      int    `json:"walkover_loser"`          // wynik walkowera dla drużyny zdyskwalifikowanej
+}
+
+// PlayerDiscipline - kartki i zawieszenie zawodnika w rozgrywkach
+type PlayerDiscipline struct {
+	PlayerID       uint   `json:"player_id"`
+	TeamID         uint   `json:"team_id"`
+	PlayerName     string `json:"player_name"`
+	YellowCards    int    `json:"yellow_cards"`
+	RedCards       int    `json:"red_cards"`
+	Bans           int    `json:"bans"`            // łączna liczba meczów zawieszenia
+	GamesRemaining int    `json:"games_remaining"` // mecze zawieszenia do odbycia
+	IsSuspended    bool   `json:"is_suspended"`
+}
+
+// Formaty protokołu meczu
+const (
+	ReportFormatHTML = "html"
+	ReportFormatPDF  = "pdf"
+)
+
+// MatchReportValue - końcowa wartość statystyki drużyny (GameValue)
+type MatchReportValue struct {
+	ValueTypeID uint   `json:"value_type_id"`
+	Name        string `json:"name"`
+	Value       int    `json:"value"`
+	IsDSQ       bool   `json:"is_dsq"`
+}
+
+// MatchReportTeam - drużyna w protokole: skład, trenerzy i wartości końcowe
+type MatchReportTeam struct {
+	TeamLineup
+	Values []MatchReportValue `json:"values"`
+}
+
+// MatchReportEvent - wydarzenie protokołowe (bramka, kartka) z czasem
+type MatchReportEvent struct {
+	ID         uint   `json:"id"`
+	Type       string `json:"type"`
+	PartName   string `json:"part_name"`
+	Time       string `json:"time"` // mm:ss w części meczu
+	TeamName   string `json:"team_name"`
+	Number     string `json:"number"`
+	PlayerName string `json:"player_name"`
+}
+
+// MatchReport - oficjalny protokół meczu
+type MatchReport struct {
+	Game        Game               `json:"game"`
+	Competition string             `json:"competition"`
+	KickOff     string             `json:"kick_off"`
+	Field       *Field             `json:"field"`
+	Teams       []MatchReportTeam  `json:"teams"`
+	Referees    []GameReferee      `json:"referees"`
+	Events      []MatchReportEvent `json:"events"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+// GameRequest - dane nowego meczu (części i sloty wartości tworzone z presetu)
+type GameRequest struct {
+	GroupID    uint    `json:"group_id"`
+	FieldID    uint    `json:"field_id"`
+	DateTime   string  `json:"date_time"` // Format: "2025-10-17_20:45"
+	Round      int     `json:"round"`
+	ForeignID  *string `json:"foreign_id"`
+	HomeTeamID uint    `json:"home_team_id"`
+	AwayTeamID uint    `json:"away_team_id"`
+}
+
 // OBSStatusResponse - odpowiedź ze statusem OBS
 type OBSStatusResponse struct {
 	Connected bool `json:"connected"`
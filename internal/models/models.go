@@ -488,8 +488,10 @@ type GamePlayer struct {
 	GameID       uint   `json:"game_id"`
 	TeamID       uint   `json:"team_id"`
 	PlayerID     uint   `json:"player_id"`
-	Number       string `json:"number"`         // numer zawodnika w tym meczu
-	PlayerRoleID uint   `json:"player_role_id"` // rola/pozycja zawodnika w meczu
+	Number       string `json:"number"`                          // numer zawodnika w tym meczu
+	PlayerRoleID uint   `json:"player_role_id"`                  // rola/pozycja zawodnika w meczu
+	IsStarter    bool   `gorm:"default:false" json:"is_starter"` // pierwszy skład (false = rezerwowy)
+	IsCaptain    bool   `gorm:"default:false" json:"is_captain"` // kapitan w tym meczu
 
 	// Relacje
 	Game       Game       `gorm:"foreignKey:GameID" json:"game,omitempty"`
@@ -600,6 +602,97 @@ type ActiveSession struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// RecordingSession - sesja nagrywania kamer (od startu do zatrzymania)
+type RecordingSession struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	GameID     *uint      `gorm:"index" json:"game_id"` // nullable - mecz aktywny w chwili startu
+	GamePartID *uint      `json:"game_part_id"`         // nullable - część meczu aktywna w chwili startu
+	StartedAt  time.Time  `gorm:"not null" json:"started_at"`
+	StoppedAt  *time.Time `json:"stopped_at"`               // nullable - nil = nagrywanie trwa
+	Cameras    string     `gorm:"type:text" json:"cameras"` // JSON z listą aktywnych kamer
+
+	// Relacje
+	Game     *Game           `gorm:"foreignKey:GameID" json:"game,omitempty"`
+	GamePart *GamePart       `gorm:"foreignKey:GamePartID" json:"game_part,omitempty"`
+	Files    []RecordingFile `gorm:"foreignKey:RecordingSessionID" json:"files,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// RecordingFile - plik nagrania zgłoszony przez rejestrator kamery
+type RecordingFile struct {
+	ID                 uint       `gorm:"primaryKey" json:"id"`
+	RecordingSessionID uint       `gorm:"index" json:"recording_session_id"`
+	CameraName         string     `gorm:"not null" json:"camera_name"`
+	FileName           string     `gorm:"not null" json:"file_name"`
+	RecordStartTime    *time.Time `json:"record_start_time"` // nullable - czas rozpoczęcia pliku wg rejestratora
+	RecordStopTime     *time.Time `json:"record_stop_time"`  // nullable - czas zakończenia pliku wg rejestratora
+	SizeBytes          int64      `json:"size_bytes"`
+
+	// Przesyłanie pliku na serwer
+	SHA256        string     `json:"sha256"`                             // suma kontrolna pliku (hex) podana przez rejestrator
+	UploadStatus  string     `gorm:"default:local" json:"upload_status"` // local, uploading, verified, failed
+	UploadedBytes int64      `json:"uploaded_bytes"`                     // liczba bajtów odebranych przez serwer
+	StoragePath   string     `json:"storage_path"`                       // ścieżka pliku na serwerze
+	UploadedAt    *time.Time `json:"uploaded_at"`                        // nullable - czas weryfikacji pliku
+
+	// Relacje
+	RecordingSession RecordingSession `gorm:"foreignKey:RecordingSessionID" json:"-"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// RecorderCommand - polecenie wysłane do rejestratorów kamer (log poleceń)
+type RecorderCommand struct {
+	ID       uint      `gorm:"primaryKey" json:"id"`
+	Command  string    `gorm:"not null;index" json:"command"` // split_file, mark_clip, set_quality, take_snapshot
+	Cameras  string    `gorm:"type:text" json:"cameras"`      // JSON z listą kamer docelowych
+	Payload  string    `gorm:"type:text" json:"payload"`      // JSON z parametrami polecenia
+	IssuedBy string    `json:"issued_by"`                     // nazwa tokenu lub "system"
+	IssuedAt time.Time `gorm:"not null" json:"issued_at"`
+
+	// Relacje
+	Acks []RecorderCommandAck `gorm:"foreignKey:RecorderCommandID" json:"acks,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// RecorderCommandAck - potwierdzenie wykonania polecenia przez rejestrator kamery
+type RecorderCommandAck struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	RecorderCommandID uint      `gorm:"index" json:"recorder_command_id"`
+	CameraName        string    `gorm:"not null" json:"camera_name"`
+	Status            string    `gorm:"not null" json:"status"` // ok, error
+	Message           string    `json:"message"`
+	AckedAt           time.Time `json:"acked_at"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// GameOperation - wpis dziennika operacji meczu (tylko dopisywanie - cofnięcie i ponowienie to osobne wpisy)
+type GameOperation struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	GameID    uint   `gorm:"not null;index" json:"game_id"`
+	Action    string `gorm:"not null" json:"action"`  // do, undo, redo
+	Kind      string `gorm:"not null" json:"kind"`    // score_adjust, score_set, event_create, event_update, event_delete, substitution
+	TargetID  *uint  `json:"target_id"`               // nullable - dla undo/redo ID cofanej/ponawianej operacji
+	Summary   string `json:"summary"`                 // opis dla operatora, np. "Gol: 0 → 1"
+	Before    string `gorm:"type:text" json:"before"` // JSON stanu przed operacją
+	After     string `gorm:"type:text" json:"after"`  // JSON stanu po operacji
+	Actor     string `json:"actor"`                   // nazwa tokenu lub "anonim"
+	ActorRole string `json:"actor_role"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // GetAllModels - zwraca slice wszystkich modeli do migracji
 func GetAllModels() []interface{} {
 	return []interface{}{
@@ -636,5 +729,10 @@ func GetAllModels() []interface{} {
 		&GameTVStaff{},
 		&GameCamera{},
 		&ActiveSession{},
+		&RecordingSession{},
+		&RecordingFile{},
+		&RecorderCommand{},
+		&RecorderCommandAck{},
+		&GameOperation{},
 	}
 }
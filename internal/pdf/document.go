@@ -0,0 +1,143 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Wymiary strony A4 w punktach
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// polishGlyphs - polskie znaki mapowane na kody 128+ (Differences w kodowaniu czcionki)
+var polishGlyphs = []struct {
+	char  rune
+	glyph string
+}{
+	{'ą', "aogonek"}, {'ć', "cacute"}, {'ę', "eogonek"}, {'ł', "lslash"}, {'ń', "nacute"},
+	{'ś', "sacute"}, {'ź', "zacute"}, {'ż', "zdotaccent"},
+	{'Ą', "Aogonek"}, {'Ć', "Cacute"}, {'Ę', "Eogonek"}, {'Ł', "Lslash"}, {'Ń', "Nacute"},
+	{'Ś', "Sacute"}, {'Ź', "Zacute"}, {'Ż', "Zdotaccent"},
+}
+
+// Document - prosty dokument PDF (tekst i linie, czcionki standardowe Helvetica)
+type Document struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+}
+
+// NewDocument - tworzy pusty dokument
+func NewDocument() *Document {
+	return &Document{}
+}
+
+// AddPage - dodaje nową stronę A4 i ustawia ją jako bieżącą
+func (d *Document) AddPage() {
+	d.current = &bytes.Buffer{}
+	d.pages = append(d.pages, d.current)
+}
+
+// PageCount - liczba stron
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Text - wypisuje tekst; y liczone od górnej krawędzi strony
+func (d *Document) Text(x, y, size float64, bold bool, text string) {
+	if d.current == nil {
+		d.AddPage()
+	}
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.current, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y, encodeText(text))
+}
+
+// Line - rysuje linię; współrzędne y liczone od górnej krawędzi strony
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	if d.current == nil {
+		d.AddPage()
+	}
+	fmt.Fprintf(d.current, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Bytes - serializuje dokument do formatu PDF
+func (d *Document) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: katalog, 2: drzewo stron, 3: kodowanie, 4-5: czcionki, dalej strony i ich treść
+	firstPage := 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	var differences strings.Builder
+	for _, g := range polishGlyphs {
+		differences.WriteString(" /" + g.glyph)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object(fmt.Sprintf("<< /Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [128%s] >>", differences.String()))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding 3 0 R >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding 3 0 R >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// encodeText - koduje tekst do kodowania czcionki i escapuje znaki specjalne PDF
+func encodeText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r < 128 && r >= 32:
+			b.WriteByte(byte(r))
+		case r >= 160 && r < 256:
+			// WinAnsi pokrywa się z Latin-1 w zakresie 160-255 (np. ó, é)
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteString(polishCode(r))
+		}
+	}
+	return b.String()
+}
+
+// polishCode - kod znaku z tabeli Differences lub "?" dla znaków spoza kodowania
+func polishCode(r rune) string {
+	for i, g := range polishGlyphs {
+		if g.char == r {
+			return fmt.Sprintf("\\%03o", 128+i)
+		}
+	}
+	return "?"
+}
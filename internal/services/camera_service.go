@@ -0,0 +1,255 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"recorder-server/internal/state"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GameCameraSelection - wybór kamery dla meczu
+type GameCameraSelection struct {
+	CameraID uint `json:"camera_id"`
+	IsUsed   bool `json:"is_used"`
+}
+
+// CameraService - serwis zarządzający kamerami z bazy danych
+type CameraService struct {
+	dbManager *database.Manager
+	appState  *state.AppState
+}
+
+// NewCameraService - tworzy nowy serwis kamer
+func NewCameraService(dbManager *database.Manager, appState *state.AppState) *CameraService {
+	return &CameraService{
+		dbManager: dbManager,
+		appState:  appState,
+	}
+}
+
+// ListCameras - zwraca wszystkie kamery rozgrywek
+func (s *CameraService) ListCameras() ([]models.Camera, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var cameras []models.Camera
+	if err := db.Order("id ASC").Find(&cameras).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania kamer: %w", err)
+	}
+	return cameras, nil
+}
+
+// GetCamera - zwraca kamerę po ID
+func (s *CameraService) GetCamera(cameraID uint) (*models.Camera, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var camera models.Camera
+	if err := db.First(&camera, cameraID).Error; err != nil {
+		return nil, err
+	}
+	return &camera, nil
+}
+
+// validateCamera - sprawdza nazwę kamery (wymagana i unikalna)
+func (s *CameraService) validateCamera(db *gorm.DB, name string, cameraID uint) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("nazwa kamery jest wymagana")
+	}
+
+	var count int64
+	db.Model(&models.Camera{}).Where("name = ? AND id != ?", name, cameraID).Count(&count)
+	if count > 0 {
+		return fmt.Errorf("kamera o nazwie %s już istnieje", name)
+	}
+	return nil
+}
+
+// CreateCamera - dodaje nową kamerę
+func (s *CameraService) CreateCamera(name, location string) (*models.Camera, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	if err := s.validateCamera(db, name, 0); err != nil {
+		return nil, err
+	}
+
+	camera := models.Camera{
+		Name:     name,
+		Location: location,
+	}
+	if err := db.Create(&camera).Error; err != nil {
+		return nil, fmt.Errorf("błąd tworzenia kamery: %w", err)
+	}
+
+	s.RefreshAppState()
+	return &camera, nil
+}
+
+// UpdateCamera - aktualizuje kamerę
+func (s *CameraService) UpdateCamera(cameraID uint, name, location string) (*models.Camera, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var camera models.Camera
+	if err := db.First(&camera, cameraID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.validateCamera(db, name, cameraID); err != nil {
+		return nil, err
+	}
+
+	camera.Name = name
+	camera.Location = location
+	if err := db.Save(&camera).Error; err != nil {
+		return nil, fmt.Errorf("błąd aktualizacji kamery: %w", err)
+	}
+
+	s.RefreshAppState()
+	return &camera, nil
+}
+
+// DeleteCamera - usuwa kamerę wraz z jej przypisaniami do meczów
+func (s *CameraService) DeleteCamera(cameraID uint) error {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var camera models.Camera
+	if err := db.First(&camera, cameraID).Error; err != nil {
+		return err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("camera_id = ?", cameraID).Delete(&models.GameCamera{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&camera).Error
+	})
+	if err != nil {
+		return fmt.Errorf("błąd usuwania kamery: %w", err)
+	}
+
+	s.RefreshAppState()
+	return nil
+}
+
+// GetGameCameras - zwraca przypisania kamer do meczu
+func (s *CameraService) GetGameCameras(gameID uint) ([]models.GameCamera, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var gameCameras []models.GameCamera
+	if err := db.Preload("Camera").Where("game_id = ?", gameID).Order("camera_id ASC").Find(&gameCameras).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania kamer meczu: %w", err)
+	}
+	return gameCameras, nil
+}
+
+// SetGameCameras - zastępuje wybór kamer dla meczu
+func (s *CameraService) SetGameCameras(gameID uint, selections []GameCameraSelection) ([]models.GameCamera, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var game models.Game
+	if err := db.First(&game, gameID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono meczu: %w", err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("game_id = ?", gameID).Delete(&models.GameCamera{}).Error; err != nil {
+			return err
+		}
+
+		for _, selection := range selections {
+			var count int64
+			tx.Model(&models.Camera{}).Where("id = ?", selection.CameraID).Count(&count)
+			if count == 0 {
+				return fmt.Errorf("kamera ID=%d nie istnieje", selection.CameraID)
+			}
+
+			gameCamera := models.GameCamera{
+				GameID:   gameID,
+				CameraID: selection.CameraID,
+				IsUsed:   selection.IsUsed,
+			}
+			if err := tx.Create(&gameCamera).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.RefreshAppState()
+	return s.GetGameCameras(gameID)
+}
+
+// resolveCameraNames - ustala listę kamer: wybrane dla aktywnego meczu lub wszystkie kamery rozgrywek
+func (s *CameraService) resolveCameraNames(db *gorm.DB) ([]string, error) {
+	var activeSession models.ActiveSession
+	if err := db.First(&activeSession).Error; err == nil && activeSession.GameID != nil {
+		var gameCameras []models.GameCamera
+		db.Preload("Camera").Where("game_id = ?", *activeSession.GameID).Order("camera_id ASC").Find(&gameCameras)
+
+		// Jeśli mecz ma przypisania, używamy tylko kamer oznaczonych jako używane
+		if len(gameCameras) > 0 {
+			names := []string{}
+			for _, gc := range gameCameras {
+				if gc.IsUsed && gc.Camera.ID != 0 {
+					names = append(names, gc.Camera.Name)
+				}
+			}
+			return names, nil
+		}
+	}
+
+	cameras, err := s.ListCameras()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(cameras))
+	for _, camera := range cameras {
+		names = append(names, camera.Name)
+	}
+	return names, nil
+}
+
+// RefreshAppState - przeładowuje listę kamer w stanie aplikacji
+func (s *CameraService) RefreshAppState() {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		s.appState.SetAllCameras([]string{})
+		return
+	}
+
+	names, err := s.resolveCameraNames(db)
+	if err != nil {
+		log.Printf("CameraService: Błąd odświeżania listy kamer: %v", err)
+		return
+	}
+
+	s.appState.SetAllCameras(names)
+	log.Printf("CameraService: Lista kamer: %v", names)
+}
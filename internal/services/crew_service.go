@@ -0,0 +1,551 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrCrewConflict - przypisanie koliduje z innym meczem (zapis wymaga wymuszenia)
+var ErrCrewConflict = errors.New("osoba przypisana do nakładającego się meczu")
+
+// defaultGameDuration - czas zajętości obsady, gdy nie da się go wyliczyć z części meczu
+const defaultGameDuration = 2 * time.Hour
+
+// CrewService - sędziowie i ekipa TV: kartoteka, obsada meczów, kolizje terminów i karta realizacji
+type CrewService struct {
+	dbManager     *database.Manager
+	cameraService *CameraService
+}
+
+// NewCrewService - tworzy nowy serwis obsady
+func NewCrewService(dbManager *database.Manager, cameraService *CameraService) *CrewService {
+	return &CrewService{
+		dbManager:     dbManager,
+		cameraService: cameraService,
+	}
+}
+
+// validateName - imię i nazwisko są wymagane
+func validateName(req models.CrewMemberRequest) error {
+	if strings.TrimSpace(req.FirstName) == "" || strings.TrimSpace(req.LastName) == "" {
+		return fmt.Errorf("imię i nazwisko są wymagane")
+	}
+	return nil
+}
+
+// ListReferees - sędziowie
+func (s *CrewService) ListReferees() ([]models.Referee, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var referees []models.Referee
+	if err := db.Order("last_name ASC, first_name ASC").Find(&referees).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania sędziów: %w", err)
+	}
+	return referees, nil
+}
+
+// ListRefereeRoles - role sędziów (z presetu)
+func (s *CrewService) ListRefereeRoles() ([]models.RefereeRole, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var roles []models.RefereeRole
+	if err := db.Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania ról sędziów: %w", err)
+	}
+	return roles, nil
+}
+
+// CreateReferee - dodaje sędziego
+func (s *CrewService) CreateReferee(req models.CrewMemberRequest) (*models.Referee, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+	if err := validateName(req); err != nil {
+		return nil, err
+	}
+
+	referee := models.Referee{
+		FirstName: strings.TrimSpace(req.FirstName),
+		LastName:  strings.TrimSpace(req.LastName),
+	}
+	if err := db.Create(&referee).Error; err != nil {
+		return nil, fmt.Errorf("błąd tworzenia sędziego: %w", err)
+	}
+	return &referee, nil
+}
+
+// UpdateReferee - aktualizuje dane sędziego
+func (s *CrewService) UpdateReferee(refereeID uint, req models.CrewMemberRequest) (*models.Referee, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+	if err := validateName(req); err != nil {
+		return nil, err
+	}
+
+	var referee models.Referee
+	if err := db.First(&referee, refereeID).Error; err != nil {
+		return nil, err
+	}
+	referee.FirstName = strings.TrimSpace(req.FirstName)
+	referee.LastName = strings.TrimSpace(req.LastName)
+	if err := db.Save(&referee).Error; err != nil {
+		return nil, fmt.Errorf("błąd aktualizacji sędziego: %w", err)
+	}
+	return &referee, nil
+}
+
+// DeleteReferee - usuwa sędziego wraz z przypisaniami do meczów
+func (s *CrewService) DeleteReferee(refereeID uint) error {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var referee models.Referee
+	if err := db.First(&referee, refereeID).Error; err != nil {
+		return err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("referee_id = ?", refereeID).Delete(&models.GameReferee{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&referee).Error
+	})
+	if err != nil {
+		return fmt.Errorf("błąd usuwania sędziego: %w", err)
+	}
+	return nil
+}
+
+// ListTVStaff - członkowie ekipy TV
+func (s *CrewService) ListTVStaff() ([]models.TVStaff, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var staff []models.TVStaff
+	if err := db.Preload("TVStaffRole").Order("last_name ASC, first_name ASC").Find(&staff).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania ekipy TV: %w", err)
+	}
+	return staff, nil
+}
+
+// ListTVStaffRoles - role ekipy TV (z presetu)
+func (s *CrewService) ListTVStaffRoles() ([]models.TVStaffRole, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var roles []models.TVStaffRole
+	if err := db.Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania ról ekipy TV: %w", err)
+	}
+	return roles, nil
+}
+
+// CreateTVStaff - dodaje członka ekipy TV
+func (s *CrewService) CreateTVStaff(req models.CrewMemberRequest) (*models.TVStaff, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+	if err := validateName(req); err != nil {
+		return nil, err
+	}
+	if err := db.First(&models.TVStaffRole{}, req.RoleID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono roli ekipy TV ID=%d", req.RoleID)
+	}
+
+	staff := models.TVStaff{
+		FirstName:     strings.TrimSpace(req.FirstName),
+		LastName:      strings.TrimSpace(req.LastName),
+		TVStaffRoleID: req.RoleID,
+	}
+	if err := db.Omit(clause.Associations).Create(&staff).Error; err != nil {
+		return nil, fmt.Errorf("błąd tworzenia członka ekipy TV: %w", err)
+	}
+	return &staff, nil
+}
+
+// UpdateTVStaff - aktualizuje dane członka ekipy TV
+func (s *CrewService) UpdateTVStaff(staffID uint, req models.CrewMemberRequest) (*models.TVStaff, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+	if err := validateName(req); err != nil {
+		return nil, err
+	}
+	if err := db.First(&models.TVStaffRole{}, req.RoleID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono roli ekipy TV ID=%d", req.RoleID)
+	}
+
+	var staff models.TVStaff
+	if err := db.First(&staff, staffID).Error; err != nil {
+		return nil, err
+	}
+	staff.FirstName = strings.TrimSpace(req.FirstName)
+	staff.LastName = strings.TrimSpace(req.LastName)
+	staff.TVStaffRoleID = req.RoleID
+	if err := db.Omit(clause.Associations).Save(&staff).Error; err != nil {
+		return nil, fmt.Errorf("błąd aktualizacji członka ekipy TV: %w", err)
+	}
+	return &staff, nil
+}
+
+// DeleteTVStaff - usuwa członka ekipy TV wraz z przypisaniami do meczów
+func (s *CrewService) DeleteTVStaff(staffID uint) error {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var staff models.TVStaff
+	if err := db.First(&staff, staffID).Error; err != nil {
+		return err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tv_staff_id = ?", staffID).Delete(&models.GameTVStaff{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&staff).Error
+	})
+	if err != nil {
+		return fmt.Errorf("błąd usuwania członka ekipy TV: %w", err)
+	}
+	return nil
+}
+
+// GetGameReferees - sędziowie meczu z rolami
+func (s *CrewService) GetGameReferees(gameID uint) ([]models.GameReferee, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var referees []models.GameReferee
+	if err := db.Preload("Referee").Preload("RefereeRole").Where("game_id = ?", gameID).
+		Order("referee_role_id ASC, id ASC").Find(&referees).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania sędziów meczu: %w", err)
+	}
+	return referees, nil
+}
+
+// GetGameTVStaff - ekipa TV meczu z rolami
+func (s *CrewService) GetGameTVStaff(gameID uint) ([]models.GameTVStaff, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var staff []models.GameTVStaff
+	if err := db.Preload("TVStaff").Preload("TVStaffRole").Where("game_id = ?", gameID).
+		Order("tv_staff_role_id ASC, id ASC").Find(&staff).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania ekipy TV meczu: %w", err)
+	}
+	return staff, nil
+}
+
+// SetGameReferees - zastępuje sędziów meczu; przy kolizji terminów zapis tylko z force
+func (s *CrewService) SetGameReferees(gameID uint, assignments []models.CrewAssignment, force bool) ([]models.GameReferee, []models.CrewConflict, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var game models.Game
+	if err := db.First(&game, gameID).Error; err != nil {
+		return nil, nil, fmt.Errorf("nie znaleziono meczu: %w", err)
+	}
+
+	rows := make([]models.GameReferee, 0, len(assignments))
+	seen := map[uint]bool{}
+	for _, assignment := range assignments {
+		if seen[assignment.PersonID] {
+			return nil, nil, fmt.Errorf("sędzia ID=%d przypisany więcej niż raz", assignment.PersonID)
+		}
+		seen[assignment.PersonID] = true
+		if err := db.First(&models.Referee{}, assignment.PersonID).Error; err != nil {
+			return nil, nil, fmt.Errorf("nie znaleziono sędziego ID=%d", assignment.PersonID)
+		}
+		if err := db.First(&models.RefereeRole{}, assignment.RoleID).Error; err != nil {
+			return nil, nil, fmt.Errorf("nie znaleziono roli sędziego ID=%d", assignment.RoleID)
+		}
+		rows = append(rows, models.GameReferee{GameID: gameID, RefereeID: assignment.PersonID, RefereeRoleID: assignment.RoleID})
+	}
+
+	conflicts, err := s.findConflicts(db, game, models.CrewKindReferee, idSet(seen))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(conflicts) > 0 && !force {
+		return nil, conflicts, ErrCrewConflict
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("game_id = ?", gameID).Delete(&models.GameReferee{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Omit(clause.Associations).Create(&rows).Error
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("błąd zapisywania sędziów meczu: %w", err)
+	}
+
+	log.Printf("CrewService: Mecz ID=%d - przypisano %d sędziów (kolizje: %d)", gameID, len(rows), len(conflicts))
+	referees, err := s.GetGameReferees(gameID)
+	return referees, conflicts, err
+}
+
+// SetGameTVStaff - zastępuje ekipę TV meczu; przy kolizji terminów zapis tylko z force
+func (s *CrewService) SetGameTVStaff(gameID uint, assignments []models.CrewAssignment, force bool) ([]models.GameTVStaff, []models.CrewConflict, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var game models.Game
+	if err := db.First(&game, gameID).Error; err != nil {
+		return nil, nil, fmt.Errorf("nie znaleziono meczu: %w", err)
+	}
+
+	rows := make([]models.GameTVStaff, 0, len(assignments))
+	seen := map[uint]bool{}
+	for _, assignment := range assignments {
+		if seen[assignment.PersonID] {
+			return nil, nil, fmt.Errorf("członek ekipy TV ID=%d przypisany więcej niż raz", assignment.PersonID)
+		}
+		seen[assignment.PersonID] = true
+
+		var staff models.TVStaff
+		if err := db.First(&staff, assignment.PersonID).Error; err != nil {
+			return nil, nil, fmt.Errorf("nie znaleziono członka ekipy TV ID=%d", assignment.PersonID)
+		}
+		roleID := assignment.RoleID
+		if roleID == 0 {
+			roleID = staff.TVStaffRoleID
+		}
+		if err := db.First(&models.TVStaffRole{}, roleID).Error; err != nil {
+			return nil, nil, fmt.Errorf("nie znaleziono roli ekipy TV ID=%d", roleID)
+		}
+		rows = append(rows, models.GameTVStaff{GameID: gameID, TVStaffID: staff.ID, TVStaffRoleID: roleID})
+	}
+
+	conflicts, err := s.findConflicts(db, game, models.CrewKindTVStaff, idSet(seen))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(conflicts) > 0 && !force {
+		return nil, conflicts, ErrCrewConflict
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("game_id = ?", gameID).Delete(&models.GameTVStaff{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Omit(clause.Associations).Create(&rows).Error
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("błąd zapisywania ekipy TV meczu: %w", err)
+	}
+
+	log.Printf("CrewService: Mecz ID=%d - przypisano %d osób ekipy TV (kolizje: %d)", gameID, len(rows), len(conflicts))
+	staff, err := s.GetGameTVStaff(gameID)
+	return staff, conflicts, err
+}
+
+// idSet - identyfikatory ze zbioru jako slice
+func idSet(set map[uint]bool) []uint {
+	result := make([]uint, 0, len(set))
+	for key := range set {
+		result = append(result, key)
+	}
+	return result
+}
+
+// gameWindow - przedział czasu zajęty przez mecz (rozpoczęcie + suma części meczu lub domyślnie 2h)
+func gameWindow(db *gorm.DB, game models.Game) (time.Time, time.Time, bool) {
+	start, ok := parseGameDateTime(game.DateTime)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	var totalSeconds int64
+	db.Model(&models.GamePart{}).Where("game_id = ?", game.ID).
+		Select("COALESCE(SUM(length), 0)").Scan(&totalSeconds)
+
+	duration := defaultGameDuration
+	if totalSeconds > 0 {
+		// Czas gry + przerwy i przygotowanie realizacji
+		duration = time.Duration(totalSeconds)*time.Second + time.Hour
+	}
+	return start, start.Add(duration), true
+}
+
+// findConflicts - osoby przypisane do innych meczów nakładających się w czasie
+func (s *CrewService) findConflicts(db *gorm.DB, game models.Game, kind string, personIDs []uint) ([]models.CrewConflict, error) {
+	conflicts := []models.CrewConflict{}
+	if len(personIDs) == 0 {
+		return conflicts, nil
+	}
+
+	start, end, ok := gameWindow(db, game)
+	if !ok {
+		return conflicts, nil
+	}
+
+	type assignment struct {
+		PersonID uint
+		GameID   uint
+	}
+	var others []assignment
+	var err error
+	if kind == models.CrewKindReferee {
+		err = db.Model(&models.GameReferee{}).Select("referee_id AS person_id, game_id").
+			Where("referee_id IN ? AND game_id != ?", personIDs, game.ID).Scan(&others).Error
+	} else {
+		err = db.Model(&models.GameTVStaff{}).Select("tv_staff_id AS person_id, game_id").
+			Where("tv_staff_id IN ? AND game_id != ?", personIDs, game.ID).Scan(&others).Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd sprawdzania kolizji obsady: %w", err)
+	}
+
+	for _, other := range others {
+		var otherGame models.Game
+		if err := db.First(&otherGame, other.GameID).Error; err != nil {
+			continue
+		}
+		otherStart, otherEnd, ok := gameWindow(db, otherGame)
+		if !ok || !otherStart.Before(end) || !start.Before(otherEnd) {
+			continue
+		}
+		conflicts = append(conflicts, models.CrewConflict{
+			Kind:          kind,
+			PersonID:      other.PersonID,
+			PersonName:    s.personName(db, kind, other.PersonID),
+			GameID:        game.ID,
+			OtherGameID:   otherGame.ID,
+			OtherDateTime: otherGame.DateTime,
+		})
+	}
+	return conflicts, nil
+}
+
+// personName - imię i nazwisko sędziego lub członka ekipy TV
+func (s *CrewService) personName(db *gorm.DB, kind string, personID uint) string {
+	if kind == models.CrewKindReferee {
+		var referee models.Referee
+		if db.First(&referee, personID).Error == nil {
+			return referee.FirstName + " " + referee.LastName
+		}
+	} else {
+		var staff models.TVStaff
+		if db.First(&staff, personID).Error == nil {
+			return staff.FirstName + " " + staff.LastName
+		}
+	}
+	return fmt.Sprintf("ID=%d", personID)
+}
+
+// GetCallSheet - karta realizacji meczu: drużyny, obiekt, godzina, obsada i kamery
+func (s *CrewService) GetCallSheet(gameID uint) (*models.CallSheet, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var game models.Game
+	if err := db.First(&game, gameID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono meczu: %w", err)
+	}
+
+	sheet := &models.CallSheet{
+		Game:        game,
+		KickOff:     game.DateTime,
+		Cameras:     []models.Camera{},
+		GeneratedAt: time.Now(),
+	}
+	if start, ok := parseGameDateTime(game.DateTime); ok {
+		sheet.KickOff = start.Format("02.01.2006 15:04")
+	}
+
+	var field models.Field
+	if game.FieldID != 0 && db.First(&field, game.FieldID).Error == nil {
+		sheet.Field = &field
+	}
+
+	if err := db.Preload("Team").Where("game_id = ?", gameID).Order("side ASC").Find(&sheet.Teams).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania drużyn meczu: %w", err)
+	}
+
+	var err error
+	if sheet.Referees, err = s.GetGameReferees(gameID); err != nil {
+		return nil, err
+	}
+	if sheet.TVStaff, err = s.GetGameTVStaff(gameID); err != nil {
+		return nil, err
+	}
+
+	// Kamery wybrane dla meczu, a bez wyboru - wszystkie kamery rozgrywek
+	gameCameras, err := s.cameraService.GetGameCameras(gameID)
+	if err != nil {
+		return nil, err
+	}
+	for _, gameCamera := range gameCameras {
+		if gameCamera.IsUsed {
+			sheet.Cameras = append(sheet.Cameras, gameCamera.Camera)
+		}
+	}
+	if len(gameCameras) == 0 {
+		if sheet.Cameras, err = s.cameraService.ListCameras(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Kolizje obsady z innymi meczami
+	var refereeIDs, staffIDs []uint
+	for _, referee := range sheet.Referees {
+		refereeIDs = append(refereeIDs, referee.RefereeID)
+	}
+	for _, staff := range sheet.TVStaff {
+		staffIDs = append(staffIDs, staff.TVStaffID)
+	}
+	if sheet.Conflicts, err = s.findConflicts(db, game, models.CrewKindReferee, refereeIDs); err != nil {
+		return nil, err
+	}
+	staffConflicts, err := s.findConflicts(db, game, models.CrewKindTVStaff, staffIDs)
+	if err != nil {
+		return nil, err
+	}
+	sheet.Conflicts = append(sheet.Conflicts, staffConflicts...)
+
+	return sheet, nil
+}
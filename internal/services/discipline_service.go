@@ -0,0 +1,440 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DisciplineService - kartki, automatyczne zawieszenia i dyskwalifikacje drużyn (walkower)
+type DisciplineService struct {
+	dbManager      *database.Manager
+	scoringService *ScoringService
+}
+
+// NewDisciplineService - tworzy nowy serwis dyscyplinarny
+func NewDisciplineService(dbManager *database.Manager, scoringService *ScoringService) *DisciplineService {
+	return &DisciplineService{
+		dbManager:      dbManager,
+		scoringService: scoringService,
+	}
+}
+
+// defaultDisciplineRules - reguły domyślne (4 żółte = 1 mecz, czerwona = 1 mecz, walkower 3:0)
+func defaultDisciplineRules() models.DisciplineRules {
+	return models.DisciplineRules{
+		YellowCardsPerBan: 4,
+		YellowBanGames:    1,
+		RedBanGames:       1,
+		SecondYellowIsRed: true,
+		WalkoverWinner:    3,
+		WalkoverLoser:     0,
+	}
+}
+
+// getDisciplineRulesFromVariable - reguły z Variable ("discipline"); bez typów kartek - rozpoznanie po nazwie typu wydarzenia
+func getDisciplineRulesFromVariable(db *gorm.DB, variableData map[string]interface{}) models.DisciplineRules {
+	rules := defaultDisciplineRules()
+
+	if raw, ok := variableData["discipline"]; ok {
+		if data, err := json.Marshal(raw); err == nil {
+			if err := json.Unmarshal(data, &rules); err != nil {
+				log.Printf("DisciplineService: Nieprawidłowe reguły dyscyplinarne: %v", err)
+			}
+		}
+	}
+
+	if len(rules.YellowEventTypeIDs) == 0 && len(rules.RedEventTypeIDs) == 0 {
+		var eventTypes []models.EventType
+		db.Find(&eventTypes)
+		for _, eventType := range eventTypes {
+			switch {
+			case isYellowCardName(eventType.Name):
+				rules.YellowEventTypeIDs = append(rules.YellowEventTypeIDs, eventType.ID)
+			case isRedCardName(eventType.Name):
+				rules.RedEventTypeIDs = append(rules.RedEventTypeIDs, eventType.ID)
+			}
+		}
+	}
+
+	if rules.WalkoverValueType == 0 {
+		var valueTypes []models.ValueType
+		db.Find(&valueTypes)
+		for _, valueType := range valueTypes {
+			if isGoalName(valueType.Name) {
+				rules.WalkoverValueType = valueType.ID
+				log.Printf("DisciplineService: Brak \"discipline.walkover_value_type_id\" - wynik walkowera w '%s' (ID=%d), rozpoznanego po nazwie",
+					valueType.Name, valueType.ID)
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// isYellowCardName - czy nazwa oznacza żółtą kartkę
+func isYellowCardName(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "żółt") || strings.Contains(lower, "yellow")
+}
+
+// isRedCardName - czy nazwa oznacza czerwoną kartkę
+func isRedCardName(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "czerw") || strings.Contains(lower, "red card")
+}
+
+// loadDisciplineRules - reguły dyscyplinarne aktualnych rozgrywek
+func loadDisciplineRules(db *gorm.DB) models.DisciplineRules {
+	_, variableData, err := loadVariable(db)
+	if err != nil {
+		variableData = map[string]interface{}{}
+	}
+	return getDisciplineRulesFromVariable(db, variableData)
+}
+
+// GetRules - reguły dyscyplinarne aktualnych rozgrywek
+func (s *DisciplineService) GetRules() (*models.DisciplineRules, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+	rules := loadDisciplineRules(db)
+	return &rules, nil
+}
+
+// gameCards - kartki zawodnika w jednym meczu
+type gameCards struct {
+	yellow int
+	red    int
+}
+
+// disciplineGame - mecz w kolejności rozgrywek
+type disciplineGame struct {
+	game  models.Game
+	teams map[uint]bool
+}
+
+// orderedGames - mecze rozgrywek w kolejności rozegrania (data, kolejka, ID)
+func orderedGames(db *gorm.DB) ([]disciplineGame, error) {
+	var games []models.Game
+	if err := db.Find(&games).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania meczów: %w", err)
+	}
+	var gameTeams []models.GameTeam
+	if err := db.Find(&gameTeams).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania drużyn meczów: %w", err)
+	}
+
+	teams := map[uint]map[uint]bool{}
+	for _, gameTeam := range gameTeams {
+		if teams[gameTeam.GameID] == nil {
+			teams[gameTeam.GameID] = map[uint]bool{}
+		}
+		teams[gameTeam.GameID][gameTeam.TeamID] = true
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		a, aok := parseGameDateTime(games[i].DateTime)
+		b, bok := parseGameDateTime(games[j].DateTime)
+		if aok && bok && !a.Equal(b) {
+			return a.Before(b)
+		}
+		if games[i].Round != games[j].Round {
+			return games[i].Round < games[j].Round
+		}
+		return games[i].ID < games[j].ID
+	})
+
+	result := make([]disciplineGame, len(games))
+	for i, game := range games {
+		result[i] = disciplineGame{game: game, teams: teams[game.ID]}
+	}
+	return result, nil
+}
+
+// loadCards - kartki zawodników w meczach (zawodnik -> mecz -> kartki)
+func loadCards(db *gorm.DB, rules models.DisciplineRules) (map[uint]map[uint]*gameCards, error) {
+	cards := map[uint]map[uint]*gameCards{}
+	typeIDs := append(append([]uint{}, rules.YellowEventTypeIDs...), rules.RedEventTypeIDs...)
+	if len(typeIDs) == 0 {
+		return cards, nil
+	}
+
+	var events []models.Event
+	if err := db.Where("event_type_id IN ? AND player_id IS NOT NULL", typeIDs).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania kartek: %w", err)
+	}
+
+	red := map[uint]bool{}
+	for _, id := range rules.RedEventTypeIDs {
+		red[id] = true
+	}
+	for _, event := range events {
+		playerID := *event.PlayerID
+		if cards[playerID] == nil {
+			cards[playerID] = map[uint]*gameCards{}
+		}
+		if cards[playerID][event.GameID] == nil {
+			cards[playerID][event.GameID] = &gameCards{}
+		}
+		if red[event.EventTypeID] {
+			cards[playerID][event.GameID].red++
+		} else {
+			cards[playerID][event.GameID].yellow++
+		}
+	}
+	return cards, nil
+}
+
+// isFinished - czy mecz został zakończony
+func isFinished(game models.Game) bool {
+	return game.IsFinished != nil && *game.IsFinished
+}
+
+// computeDiscipline - kartki i zawieszenie zawodnika
+//
+// Zawieszenie odbywa się w kolejnych meczach drużyny zawodnika. Dla targetGameID != 0 wynik dotyczy
+// tego meczu (wcześniejsze mecze drużyny uznaje się za rozegrane), dla 0 - stanu po zakończonych meczach.
+func computeDiscipline(player models.Player, games []disciplineGame, cards map[uint]*gameCards, rules models.DisciplineRules, targetGameID uint) models.PlayerDiscipline {
+	result := models.PlayerDiscipline{
+		PlayerID:   player.ID,
+		TeamID:     player.TeamID,
+		PlayerName: player.FirstName + " " + player.LastName,
+	}
+
+	pending := 0
+	accumulated := 0
+	for _, entry := range games {
+		if targetGameID != 0 && entry.game.ID == targetGameID {
+			break
+		}
+
+		// Mecz drużyny odbyty w zawieszeniu
+		if pending > 0 && entry.teams[player.TeamID] && (targetGameID != 0 || isFinished(entry.game)) {
+			pending--
+		}
+
+		gameCard := cards[entry.game.ID]
+		if gameCard == nil {
+			continue
+		}
+		yellow, red := gameCard.yellow, gameCard.red
+		result.YellowCards += yellow
+		result.RedCards += red
+		if rules.SecondYellowIsRed && yellow >= 2 {
+			yellow -= 2
+			red++
+		}
+
+		bans := red * rules.RedBanGames
+		accumulated += yellow
+		if rules.YellowCardsPerBan > 0 {
+			for accumulated >= rules.YellowCardsPerBan {
+				accumulated -= rules.YellowCardsPerBan
+				bans += rules.YellowBanGames
+			}
+		}
+		pending += bans
+		result.Bans += bans
+	}
+
+	result.GamesRemaining = pending
+	result.IsSuspended = pending > 0
+	return result
+}
+
+// playersDiscipline - kartki i zawieszenia wybranych zawodników (nil = wszyscy z kartkami)
+func playersDiscipline(db *gorm.DB, playerIDs []uint, targetGameID uint) ([]models.PlayerDiscipline, error) {
+	rules := loadDisciplineRules(db)
+	cards, err := loadCards(db, rules)
+	if err != nil {
+		return nil, err
+	}
+	games, err := orderedGames(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if playerIDs == nil {
+		for playerID := range cards {
+			playerIDs = append(playerIDs, playerID)
+		}
+	}
+	result := []models.PlayerDiscipline{}
+	if len(playerIDs) == 0 {
+		return result, nil
+	}
+
+	var players []models.Player
+	if err := db.Where("id IN ?", playerIDs).Order("team_id ASC, last_name ASC, first_name ASC").Find(&players).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania zawodników: %w", err)
+	}
+	for _, player := range players {
+		result = append(result, computeDiscipline(player, games, cards[player.ID], rules, targetGameID))
+	}
+	return result, nil
+}
+
+// ListDiscipline - kartki i zawieszenia zawodników z kartkami (opcjonalnie tylko z danej drużyny)
+func (s *DisciplineService) ListDiscipline(teamID uint) ([]models.PlayerDiscipline, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	all, err := playersDiscipline(db, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if teamID == 0 {
+		return all, nil
+	}
+	result := []models.PlayerDiscipline{}
+	for _, entry := range all {
+		if entry.TeamID == teamID {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// GetPlayerDiscipline - kartki i zawieszenie zawodnika
+func (s *DisciplineService) GetPlayerDiscipline(playerID uint) (*models.PlayerDiscipline, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	result, err := playersDiscipline(db, []uint{playerID}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("nie znaleziono zawodnika ID=%d: %w", playerID, gorm.ErrRecordNotFound)
+	}
+	return &result[0], nil
+}
+
+// SuspendedForGame - zawodnicy drużyn meczu zawieszeni na ten mecz
+func (s *DisciplineService) SuspendedForGame(gameID uint) ([]models.PlayerDiscipline, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var playerIDs []uint
+	if err := db.Model(&models.Player{}).
+		Where("team_id IN (?)", db.Model(&models.GameTeam{}).Select("team_id").Where("game_id = ?", gameID)).
+		Pluck("id", &playerIDs).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania zawodników meczu: %w", err)
+	}
+	return suspendedPlayers(db, gameID, playerIDs)
+}
+
+// suspendedPlayers - zawieszeni na dany mecz spośród podanych zawodników
+func suspendedPlayers(db *gorm.DB, gameID uint, playerIDs []uint) ([]models.PlayerDiscipline, error) {
+	suspended := []models.PlayerDiscipline{}
+	if len(playerIDs) == 0 {
+		return suspended, nil
+	}
+
+	all, err := playersDiscipline(db, playerIDs, gameID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range all {
+		if entry.IsSuspended {
+			suspended = append(suspended, entry)
+		}
+	}
+	return suspended, nil
+}
+
+// Disqualify - dyskwalifikuje drużynę w meczu: IsDSQ w wartościach drużyny, wynik walkowera i zakończenie meczu
+func (s *DisciplineService) Disqualify(gameID, teamID uint) (*models.ScoreUpdate, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var gameTeams []models.GameTeam
+	if err := db.Where("game_id = ?", gameID).Find(&gameTeams).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania drużyn meczu: %w", err)
+	}
+	playing := false
+	for _, gameTeam := range gameTeams {
+		if gameTeam.TeamID == teamID {
+			playing = true
+		}
+	}
+	if !playing {
+		return nil, fmt.Errorf("drużyna ID=%d nie gra w meczu ID=%d", teamID, gameID)
+	}
+
+	rules := loadDisciplineRules(db)
+	rows, err := loadPartValues(db, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.GamePartValue{}).Where("game_id = ? AND team_id = ?", gameID, teamID).
+			Update("is_dsq", true).Error; err != nil {
+			return err
+		}
+
+		// Wynik walkowera w pierwszej części meczu, pozostałe części zerowane
+		if rules.WalkoverValueType != 0 {
+			walkoverSet := map[uint]bool{}
+			for _, row := range rows {
+				if row.ValueTypeID != rules.WalkoverValueType {
+					continue
+				}
+				value := 0
+				if !walkoverSet[row.TeamID] {
+					walkoverSet[row.TeamID] = true
+					value = rules.WalkoverWinner
+					if row.TeamID == teamID {
+						value = rules.WalkoverLoser
+					}
+				}
+				if err := tx.Model(&models.GamePartValue{}).Where("id = ?", row.ID).Update("value", value).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		finished := true
+		return tx.Model(&models.Game{}).Where("id = ?", gameID).Update("is_finished", &finished).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("błąd zapisu dyskwalifikacji: %w", err)
+	}
+
+	log.Printf("DisciplineService: Mecz ID=%d - dyskwalifikacja drużyny ID=%d (walkower %d:%d)",
+		gameID, teamID, rules.WalkoverWinner, rules.WalkoverLoser)
+	return s.scoringService.Recompute(gameID)
+}
+
+// ClearDisqualification - zdejmuje dyskwalifikację drużyny (wynik pozostaje do ręcznej korekty)
+func (s *DisciplineService) ClearDisqualification(gameID, teamID uint) (*models.ScoreUpdate, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	if err := db.Model(&models.GamePartValue{}).Where("game_id = ? AND team_id = ?", gameID, teamID).
+		Update("is_dsq", false).Error; err != nil {
+		return nil, fmt.Errorf("błąd zdejmowania dyskwalifikacji: %w", err)
+	}
+
+	log.Printf("DisciplineService: Mecz ID=%d - zdjęto dyskwalifikację drużyny ID=%d", gameID, teamID)
+	return s.scoringService.Recompute(gameID)
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"recorder-server/internal/state"
+	"recorder-server/internal/timer"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// EventListener - funkcja wywoływana po zmianie wydarzenia (action: created, updated, deleted);
+// previous to stan sprzed edycji (tylko dla updated)
+type EventListener func(action string, event *models.Event, previous *models.Event)
+
+// EventService - serwis rejestrowania wydarzeń meczu (uzupełnia część meczu, czas i kamerę)
+type EventService struct {
+	dbManager     *database.Manager
+	appState      *state.AppState
+	timerService  *TimerService
+	socketService *SocketIOService
+
+	mu        sync.RWMutex
+	listeners []EventListener
+}
+
+// NewEventService - tworzy nowy serwis wydarzeń
+func NewEventService(dbManager *database.Manager, appState *state.AppState, timerService *TimerService, socketService *SocketIOService) *EventService {
+	return &EventService{
+		dbManager:     dbManager,
+		appState:      appState,
+		timerService:  timerService,
+		socketService: socketService,
+	}
+}
+
+// AddListener - rejestruje funkcję wywoływaną po każdej zmianie wydarzenia
+func (s *EventService) AddListener(listener EventListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// notify - rozgłasza zmianę wydarzenia przez Socket.IO i powiadamia słuchaczy
+func (s *EventService) notify(action string, event *models.Event, previous *models.Event) {
+	s.socketService.BroadcastToPanel("event_"+action, event)
+
+	s.mu.RLock()
+	listeners := append([]EventListener{}, s.listeners...)
+	s.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(action, event, previous)
+	}
+}
+
+// ListEventTypes - lista typów wydarzeń
+func (s *EventService) ListEventTypes() ([]models.EventType, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var eventTypes []models.EventType
+	if err := db.Order("id ASC").Find(&eventTypes).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania typów wydarzeń: %w", err)
+	}
+	return eventTypes, nil
+}
+
+// CreateEventType - tworzy typ wydarzenia
+func (s *EventService) CreateEventType(eventType models.EventType) (*models.EventType, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	if eventType.Name == "" {
+		return nil, fmt.Errorf("nazwa typu wydarzenia jest wymagana")
+	}
+	eventType.ID = 0
+
+	if err := db.Create(&eventType).Error; err != nil {
+		return nil, fmt.Errorf("błąd tworzenia typu wydarzenia: %w", err)
+	}
+	return &eventType, nil
+}
+
+// ListEvents - wydarzenia meczu w kolejności części meczu i czasu
+func (s *EventService) ListEvents(gameID uint) ([]models.Event, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var events []models.Event
+	err := db.Preload("EventType").Preload("Team").Preload("Player").
+		Select("events.*").
+		Joins("LEFT JOIN game_parts ON game_parts.id = events.game_part_id").
+		Where("events.game_id = ?", gameID).
+		Order("game_parts.match_order ASC, events.event_time ASC, events.id ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wydarzeń: %w", err)
+	}
+	return events, nil
+}
+
+// GetEvent - pobiera wydarzenie meczu
+func (s *EventService) GetEvent(gameID, eventID uint) (*models.Event, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var event models.Event
+	if err := db.Preload("EventType").Preload("Team").Preload("Player").
+		Where("game_id = ?", gameID).First(&event, eventID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono wydarzenia ID=%d: %w", eventID, err)
+	}
+	return &event, nil
+}
+
+// CreateEvent - zapisuje wydarzenie; brakujące pola uzupełnia z aktywnej sesji, stopera i kamery na wizji
+func (s *EventService) CreateEvent(gameID uint, req models.EventRequest) (*models.Event, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	if req.EventTypeID == nil {
+		return nil, fmt.Errorf("event_type_id jest wymagany")
+	}
+
+	var game models.Game
+	if err := db.First(&game, gameID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono meczu ID=%d: %w", gameID, err)
+	}
+
+	event := models.Event{GameID: gameID}
+	if err := s.applyRequest(db, &event, req); err != nil {
+		return nil, err
+	}
+
+	// Część meczu - z aktywnej sesji, jeśli dotyczy tego meczu
+	if req.GamePartID == nil {
+		var session models.ActiveSession
+		if err := db.First(&session).Error; err != nil || session.GameID == nil || *session.GameID != gameID || session.GamePartID == nil {
+			return nil, fmt.Errorf("brak aktywnej części meczu - podaj game_part_id")
+		}
+		event.GamePartID = *session.GamePartID
+	}
+
+	if req.EventTime == nil {
+		event.EventTime = s.currentEventTime()
+	}
+
+	if req.CameraID == nil {
+		event.CameraID = s.programCameraID(db)
+	}
+
+	if err := db.Create(&event).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania wydarzenia: %w", err)
+	}
+
+	created, err := s.GetEvent(gameID, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("EventService: Dodano wydarzenie %s (mecz ID=%d, część ID=%d, czas %ds)",
+		created.Name, gameID, created.GamePartID, created.EventTime)
+	s.notify("created", created, nil)
+	return created, nil
+}
+
+// UpdateEvent - aktualizuje podane pola wydarzenia
+func (s *EventService) UpdateEvent(gameID, eventID uint, req models.EventRequest) (*models.Event, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var event models.Event
+	if err := db.Where("game_id = ?", gameID).First(&event, eventID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono wydarzenia ID=%d: %w", eventID, err)
+	}
+
+	previous := event
+	if err := s.applyRequest(db, &event, req); err != nil {
+		return nil, err
+	}
+
+	if err := db.Save(&event).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania wydarzenia: %w", err)
+	}
+
+	updated, err := s.GetEvent(gameID, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("EventService: Zaktualizowano wydarzenie ID=%d", event.ID)
+	s.notify("updated", updated, &previous)
+	return updated, nil
+}
+
+// DeleteEvent - usuwa wydarzenie meczu
+func (s *EventService) DeleteEvent(gameID, eventID uint) error {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	event, err := s.GetEvent(gameID, eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Delete(&models.Event{}, eventID).Error; err != nil {
+		return fmt.Errorf("błąd usuwania wydarzenia: %w", err)
+	}
+
+	log.Printf("EventService: Usunięto wydarzenie ID=%d", eventID)
+	s.notify("deleted", event, nil)
+	return nil
+}
+
+// RestoreEvent - przywraca usunięte wydarzenie z tym samym ID (cofnięcie usunięcia)
+func (s *EventService) RestoreEvent(gameID, eventID uint) (*models.Event, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	result := db.Unscoped().Model(&models.Event{}).
+		Where("id = ? AND game_id = ? AND deleted_at IS NOT NULL", eventID, gameID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, fmt.Errorf("błąd przywracania wydarzenia: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("brak usuniętego wydarzenia ID=%d", eventID)
+	}
+
+	restored, err := s.GetEvent(gameID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("EventService: Przywrócono wydarzenie ID=%d", eventID)
+	s.notify("created", restored, nil)
+	return restored, nil
+}
+
+// RevertEvent - przywraca pola wydarzenia ze stanu zapisanego wcześniej (cofnięcie edycji)
+func (s *EventService) RevertEvent(gameID uint, snapshot models.Event) (*models.Event, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var event models.Event
+	if err := db.Where("game_id = ?", gameID).First(&event, snapshot.ID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono wydarzenia ID=%d: %w", snapshot.ID, err)
+	}
+
+	previous := event
+	event.Name = snapshot.Name
+	event.EventTypeID = snapshot.EventTypeID
+	event.GamePartID = snapshot.GamePartID
+	event.EventTime = snapshot.EventTime
+	event.CameraID = snapshot.CameraID
+	event.TeamID = snapshot.TeamID
+	event.PlayerID = snapshot.PlayerID
+
+	if err := db.Save(&event).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania wydarzenia: %w", err)
+	}
+
+	reverted, err := s.GetEvent(gameID, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("EventService: Przywrócono stan wydarzenia ID=%d", event.ID)
+	s.notify("updated", reverted, &previous)
+	return reverted, nil
+}
+
+// applyRequest - przepisuje podane pola żądania do wydarzenia (z walidacją powiązań)
+func (s *EventService) applyRequest(db *gorm.DB, event *models.Event, req models.EventRequest) error {
+	if req.EventTypeID != nil {
+		var eventType models.EventType
+		if err := db.First(&eventType, *req.EventTypeID).Error; err != nil {
+			return fmt.Errorf("nie znaleziono typu wydarzenia ID=%d", *req.EventTypeID)
+		}
+		// Nazwa podąża za typem, chyba że podano własną
+		if event.Name == "" || event.EventTypeID != eventType.ID {
+			event.Name = eventType.Name
+		}
+		event.EventTypeID = eventType.ID
+	}
+	if req.Name != nil && *req.Name != "" {
+		event.Name = *req.Name
+	}
+	if req.GamePartID != nil {
+		var part models.GamePart
+		if err := db.Where("game_id = ?", event.GameID).First(&part, *req.GamePartID).Error; err != nil {
+			return fmt.Errorf("część meczu ID=%d nie należy do meczu ID=%d", *req.GamePartID, event.GameID)
+		}
+		event.GamePartID = part.ID
+	}
+	if req.EventTime != nil {
+		if *req.EventTime < 0 {
+			return fmt.Errorf("event_time nie może być ujemny")
+		}
+		event.EventTime = *req.EventTime
+	}
+	if req.CameraID != nil {
+		event.CameraID = *req.CameraID
+	}
+	if req.TeamID != nil {
+		event.TeamID = req.TeamID
+	}
+	if req.PlayerID != nil {
+		event.PlayerID = req.PlayerID
+	}
+	return nil
+}
+
+// currentEventTime - czas od początku części meczu wg stopera (w sekundach)
+func (s *EventService) currentEventTime() int {
+	return matchClockSeconds(s.timerService)
+}
+
+// matchClockSeconds - czas od początku części meczu wg stopera (w sekundach)
+func matchClockSeconds(timerService *TimerService) int {
+	return clockSeconds(timerService.GetState())
+}
+
+// clockSeconds - czas od początku części meczu dla stanu stopera (w sekundach), łącznie z doliczonym czasem
+func clockSeconds(timerState timer.State) int {
+	elapsedMs := timerState.ElapsedMs
+
+	// Przy odliczaniu w dół stoper pokazuje czas pozostały
+	if timerState.Direction == "down" && timerState.MaxDurationMs != nil {
+		elapsedMs = *timerState.MaxDurationMs - timerState.ElapsedMs + timerState.OverflowMs
+	}
+	// Przy odliczaniu w górę stoper zatrzymuje się na czasie regulaminowym, a nadwyżkę podaje osobno
+	if timerState.Direction == "up" && timerState.IsOverflow {
+		elapsedMs += timerState.OverflowMs
+	}
+	if elapsedMs < 0 {
+		elapsedMs = 0
+	}
+	return int(elapsedMs / 1000)
+}
+
+// programCameraID - ID kamery aktualnie na wizji (0 gdy nieznana)
+func (s *EventService) programCameraID(db *gorm.DB) uint {
+	name := s.appState.GetProgramCamera()
+	if name == "" {
+		return 0
+	}
+
+	var camera models.Camera
+	if err := db.Where("name = ?", name).First(&camera).Error; err != nil {
+		return 0
+	}
+	return camera.ID
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"recorder-server/internal/timer"
+	"testing"
+)
+
+func TestClockSeconds(t *testing.T) {
+	maxMs := int64(45 * 60 * 1000)
+	cases := []struct {
+		name  string
+		state timer.State
+		want  int
+	}{
+		{"w górę", timer.State{Direction: "up", ElapsedMs: 754000, MaxDurationMs: &maxMs}, 754},
+		{"w górę, czas doliczony", timer.State{Direction: "up", ElapsedMs: maxMs, MaxDurationMs: &maxMs, IsOverflow: true, OverflowMs: 95000}, 2795},
+		{"w dół", timer.State{Direction: "down", ElapsedMs: maxMs - 60000, MaxDurationMs: &maxMs}, 60},
+		{"w dół, czas doliczony", timer.State{Direction: "down", ElapsedMs: 0, MaxDurationMs: &maxMs, IsOverflow: true, OverflowMs: 30000}, 2730},
+	}
+	for _, c := range cases {
+		if got := clockSeconds(c.state); got != c.want {
+			t.Errorf("%s: %d s, oczekiwano %d s", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Statusy przesyłania pliku nagrania
+const (
+	UploadStatusLocal     = "local"
+	UploadStatusUploading = "uploading"
+	UploadStatusVerified  = "verified"
+	UploadStatusFailed    = "failed"
+)
+
+// ErrUploadOffset - fragment nie zaczyna się od aktualnego offsetu (klient powinien wznowić od UploadedBytes)
+type ErrUploadOffset struct {
+	Expected int64
+}
+
+func (e *ErrUploadOffset) Error() string {
+	return fmt.Sprintf("nieprawidłowy offset fragmentu - oczekiwano %d", e.Expected)
+}
+
+// FootageService - serwis przyjmujący pliki nagrań od rejestratorów (przesyłanie fragmentami z wznawianiem)
+type FootageService struct {
+	dbManager     *database.Manager
+	socketService *SocketIOService
+
+	mu    sync.Mutex
+	locks map[uint]*sync.Mutex // blokady per plik - fragmenty jednego pliku zapisywane po kolei
+}
+
+// NewFootageService - tworzy nowy serwis przesyłania nagrań
+func NewFootageService(dbManager *database.Manager, socketService *SocketIOService) *FootageService {
+	return &FootageService{
+		dbManager:     dbManager,
+		socketService: socketService,
+		locks:         make(map[uint]*sync.Mutex),
+	}
+}
+
+// lockFile - zwraca zablokowany mutex dla pliku
+func (s *FootageService) lockFile(fileID uint) *sync.Mutex {
+	s.mu.Lock()
+	lock, ok := s.locks[fileID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[fileID] = lock
+	}
+	s.mu.Unlock()
+
+	lock.Lock()
+	return lock
+}
+
+// getFile - pobiera plik nagrania z sesją
+func (s *FootageService) getFile(fileID uint) (*models.RecordingFile, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var file models.RecordingFile
+	if err := db.Preload("RecordingSession").First(&file, fileID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono pliku nagrania ID=%d: %w", fileID, err)
+	}
+	return &file, nil
+}
+
+// footagePath - ścieżka docelowa pliku: competitions/<id>/footage/<mecz>/<kamera>_<plik>
+func (s *FootageService) footagePath(file *models.RecordingFile) (string, error) {
+	competitionID := s.dbManager.GetCurrentDatabaseName()
+	if competitionID == "" {
+		return "", fmt.Errorf("brak aktywnych rozgrywek")
+	}
+
+	gameDir := "unassigned"
+	if file.RecordingSession.GameID != nil {
+		gameDir = strconv.FormatUint(uint64(*file.RecordingSession.GameID), 10)
+	}
+
+	name := sanitizeFileName(file.CameraName) + "_" + filepath.Base(file.FileName)
+	return filepath.Join("./competitions", competitionID, "footage", gameDir, name), nil
+}
+
+// InitUpload - rozpoczyna lub wznawia przesyłanie; zwraca stan z offsetem, od którego należy kontynuować
+func (s *FootageService) InitUpload(fileID uint, init models.FootageUploadInit) (*models.FootageUploadStatus, error) {
+	lock := s.lockFile(fileID)
+	defer lock.Unlock()
+
+	file, err := s.getFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if init.SizeBytes <= 0 {
+		return nil, fmt.Errorf("size_bytes jest wymagany")
+	}
+	checksum := strings.ToLower(init.SHA256)
+	if len(checksum) != sha256.Size*2 {
+		return nil, fmt.Errorf("sha256 musi być sumą SHA-256 w postaci hex")
+	}
+
+	if file.UploadStatus == UploadStatusVerified && file.SHA256 == checksum {
+		return uploadStatus(file), nil
+	}
+
+	path, err := s.footagePath(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("błąd tworzenia katalogu nagrań: %w", err)
+	}
+
+	// Zmiana pliku po stronie rejestratora = przesyłanie od początku
+	partPath := path + ".part"
+	if file.SHA256 != checksum || file.SizeBytes != init.SizeBytes || file.UploadStatus == UploadStatusFailed {
+		os.Remove(partPath)
+	}
+
+	// Offset wynika z faktycznego rozmiaru pliku częściowego
+	file.UploadedBytes = 0
+	if info, err := os.Stat(partPath); err == nil {
+		file.UploadedBytes = info.Size()
+	}
+
+	file.SizeBytes = init.SizeBytes
+	file.SHA256 = checksum
+	file.StoragePath = path
+	file.UploadStatus = UploadStatusUploading
+	file.UploadedAt = nil
+
+	if err := s.dbManager.GetDB().Omit(clause.Associations).Save(file).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania pliku nagrania: %w", err)
+	}
+
+	log.Printf("FootageService: Przesyłanie pliku %s kamery %s od offsetu %d/%d",
+		file.FileName, file.CameraName, file.UploadedBytes, file.SizeBytes)
+
+	status := uploadStatus(file)
+	s.socketService.BroadcastToPanel("footage_upload_progress", status)
+	return status, nil
+}
+
+// WriteChunk - dopisuje fragment pliku od podanego offsetu (opcjonalnie weryfikuje sumę SHA-256 fragmentu)
+func (s *FootageService) WriteChunk(fileID uint, offset int64, chunkSHA256 string, chunk io.Reader) (*models.FootageUploadStatus, error) {
+	lock := s.lockFile(fileID)
+	defer lock.Unlock()
+
+	file, err := s.getFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.UploadStatus != UploadStatusUploading {
+		return nil, fmt.Errorf("przesyłanie nie zostało rozpoczęte (status: %s)", file.UploadStatus)
+	}
+	if offset != file.UploadedBytes {
+		return nil, &ErrUploadOffset{Expected: file.UploadedBytes}
+	}
+
+	data, err := io.ReadAll(chunk)
+	if err != nil {
+		return nil, fmt.Errorf("błąd odczytu fragmentu: %w", err)
+	}
+	if offset+int64(len(data)) > file.SizeBytes {
+		return nil, fmt.Errorf("fragment przekracza zadeklarowany rozmiar pliku")
+	}
+	if chunkSHA256 != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), chunkSHA256) {
+			return nil, fmt.Errorf("niezgodna suma kontrolna fragmentu")
+		}
+	}
+
+	part, err := os.OpenFile(file.StoragePath+".part", os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("błąd otwierania pliku: %w", err)
+	}
+	defer part.Close()
+
+	if _, err := part.WriteAt(data, offset); err != nil {
+		return nil, fmt.Errorf("błąd zapisu fragmentu: %w", err)
+	}
+
+	file.UploadedBytes = offset + int64(len(data))
+	if err := s.dbManager.GetDB().Model(file).Update("uploaded_bytes", file.UploadedBytes).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania postępu: %w", err)
+	}
+
+	status := uploadStatus(file)
+	s.socketService.BroadcastToPanel("footage_upload_progress", status)
+	return status, nil
+}
+
+// CompleteUpload - weryfikuje rozmiar i sumę SHA-256 i przenosi plik na miejsce docelowe
+func (s *FootageService) CompleteUpload(fileID uint) (*models.FootageUploadStatus, error) {
+	lock := s.lockFile(fileID)
+	defer lock.Unlock()
+
+	file, err := s.getFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.UploadStatus == UploadStatusVerified {
+		return uploadStatus(file), nil
+	}
+	if file.UploadStatus != UploadStatusUploading {
+		return nil, fmt.Errorf("przesyłanie nie zostało rozpoczęte (status: %s)", file.UploadStatus)
+	}
+
+	partPath := file.StoragePath + ".part"
+	verifyErr := verifyFile(partPath, file.SizeBytes, file.SHA256)
+
+	db := s.dbManager.GetDB()
+	if verifyErr != nil {
+		// Uszkodzony plik - rejestrator musi przesłać go ponownie
+		os.Remove(partPath)
+		file.UploadStatus = UploadStatusFailed
+		file.UploadedBytes = 0
+		db.Omit(clause.Associations).Save(file)
+
+		status := uploadStatus(file)
+		status.Error = verifyErr.Error()
+		s.socketService.BroadcastToPanel("footage_upload_failed", status)
+
+		log.Printf("FootageService: Weryfikacja pliku %s kamery %s nieudana: %v",
+			file.FileName, file.CameraName, verifyErr)
+		return status, verifyErr
+	}
+
+	if err := os.Rename(partPath, file.StoragePath); err != nil {
+		return nil, fmt.Errorf("błąd przenoszenia pliku: %w", err)
+	}
+
+	now := time.Now()
+	file.UploadStatus = UploadStatusVerified
+	file.UploadedAt = &now
+	if err := db.Omit(clause.Associations).Save(file).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania pliku nagrania: %w", err)
+	}
+
+	log.Printf("FootageService: Zweryfikowano plik %s kamery %s (%d B) -> %s",
+		file.FileName, file.CameraName, file.SizeBytes, file.StoragePath)
+
+	status := uploadStatus(file)
+	s.socketService.BroadcastToPanel("footage_upload_complete", status)
+	return status, nil
+}
+
+// GetUploadStatus - zwraca stan przesyłania (do wznowienia po przerwaniu)
+func (s *FootageService) GetUploadStatus(fileID uint) (*models.FootageUploadStatus, error) {
+	file, err := s.getFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+	return uploadStatus(file), nil
+}
+
+// verifyFile - sprawdza rozmiar i sumę SHA-256 pliku
+func verifyFile(path string, expectedSize int64, expectedSHA256 string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("brak przesłanego pliku: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return fmt.Errorf("błąd odczytu pliku: %w", err)
+	}
+	if size != expectedSize {
+		return fmt.Errorf("niezgodny rozmiar pliku: %d zamiast %d", size, expectedSize)
+	}
+
+	expected, err := hex.DecodeString(expectedSHA256)
+	if err != nil || !bytes.Equal(hash.Sum(nil), expected) {
+		return fmt.Errorf("niezgodna suma kontrolna SHA-256")
+	}
+	return nil
+}
+
+// uploadStatus - buduje stan przesyłania dla API i Socket.IO
+func uploadStatus(file *models.RecordingFile) *models.FootageUploadStatus {
+	percent := 0
+	if file.SizeBytes > 0 {
+		percent = int(file.UploadedBytes * 100 / file.SizeBytes)
+	}
+	return &models.FootageUploadStatus{
+		FileID:        file.ID,
+		CameraName:    file.CameraName,
+		FileName:      file.FileName,
+		UploadStatus:  file.UploadStatus,
+		UploadedBytes: file.UploadedBytes,
+		SizeBytes:     file.SizeBytes,
+		Percent:       percent,
+	}
+}
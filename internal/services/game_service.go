@@ -0,0 +1,324 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"recorder-server/config"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GameDateTimeLayout - format pola Game.DateTime ("2025-10-17_20:45")
+const GameDateTimeLayout = "2006-01-02_15:04"
+
+// parseGameDateTime - godzina rozpoczęcia meczu (false gdy brak lub nieprawidłowy format)
+func parseGameDateTime(value string) (time.Time, bool) {
+	start, err := time.ParseInLocation(GameDateTimeLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// GameService - serwis meczów (tworzenie meczu z częściami i slotami wartości z presetu)
+type GameService struct {
+	dbManager      *database.Manager
+	scoringService *ScoringService
+}
+
+// NewGameService - tworzy nowy serwis meczów
+func NewGameService(dbManager *database.Manager, scoringService *ScoringService) *GameService {
+	return &GameService{
+		dbManager:      dbManager,
+		scoringService: scoringService,
+	}
+}
+
+// ListGames - lista meczów (opcjonalnie tylko z danej grupy)
+func (s *GameService) ListGames(groupID uint) ([]models.Game, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	query := db.Order("round ASC, date_time ASC, id ASC")
+	if groupID != 0 {
+		query = query.Where("group_id = ?", groupID)
+	}
+
+	var games []models.Game
+	if err := query.Find(&games).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania meczów: %w", err)
+	}
+	return games, nil
+}
+
+// GetGame - pobiera mecz razem z częściami meczu
+func (s *GameService) GetGame(gameID uint) (*models.Game, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var game models.Game
+	err := db.Preload("GameParts", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("match_order ASC")
+	}).First(&game, gameID).Error
+	if err != nil {
+		return nil, fmt.Errorf("nie znaleziono meczu ID=%d: %w", gameID, err)
+	}
+	return &game, nil
+}
+
+// GetGameTeams - drużyny meczu (strona 1 = gospodarze, 2 = goście)
+func (s *GameService) GetGameTeams(gameID uint) ([]models.GameTeam, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var gameTeams []models.GameTeam
+	if err := db.Preload("Team").Where("game_id = ?", gameID).Order("side ASC").Find(&gameTeams).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania drużyn meczu: %w", err)
+	}
+	return gameTeams, nil
+}
+
+// CreateGame - tworzy mecz z drużynami obu stron, a następnie części meczu i sloty wartości z presetu
+func (s *GameService) CreateGame(req models.GameRequest) (*models.Game, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	if req.GroupID == 0 {
+		return nil, fmt.Errorf("group_id jest wymagany")
+	}
+	if req.HomeTeamID == 0 || req.AwayTeamID == 0 {
+		return nil, fmt.Errorf("home_team_id i away_team_id są wymagane")
+	}
+	if req.HomeTeamID == req.AwayTeamID {
+		return nil, fmt.Errorf("drużyna nie może grać sama ze sobą")
+	}
+	if req.Round == 0 {
+		req.Round = 1
+	}
+
+	var group models.Group
+	if err := db.First(&group, req.GroupID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono grupy ID=%d", req.GroupID)
+	}
+	for _, teamID := range []uint{req.HomeTeamID, req.AwayTeamID} {
+		var team models.Team
+		if err := db.First(&team, teamID).Error; err != nil {
+			return nil, fmt.Errorf("nie znaleziono drużyny ID=%d", teamID)
+		}
+	}
+
+	game := models.Game{
+		ForeignID: req.ForeignID,
+		GroupID:   req.GroupID,
+		FieldID:   req.FieldID,
+		DateTime:  req.DateTime,
+		Round:     req.Round,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(&game).Error; err != nil {
+			return fmt.Errorf("błąd tworzenia meczu: %w", err)
+		}
+		for side, teamID := range []uint{req.HomeTeamID, req.AwayTeamID} {
+			gameTeam := models.GameTeam{GameID: game.ID, TeamID: teamID, Side: side + 1}
+			if err := tx.Omit(clause.Associations).Create(&gameTeam).Error; err != nil {
+				return fmt.Errorf("błąd przypisywania drużyny do meczu: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("GameService: Utworzono mecz ID=%d (grupa ID=%d, kolejka %d)", game.ID, game.GroupID, game.Round)
+
+	// Mecz istnieje także przy błędzie presetu - inicjalizację można powtórzyć przez /initialize
+	if err := s.InitializeGame(game.ID); err != nil {
+		log.Printf("GameService: Błąd inicjalizacji meczu ID=%d z presetu: %v", game.ID, err)
+	}
+	return s.GetGame(game.ID)
+}
+
+// InitializeGame - tworzy brakujące części meczu i sloty GamePartValue z presetu aktualnych rozgrywek.
+// Operacja jest idempotentna - istniejące części i wartości nie są zmieniane.
+func (s *GameService) InitializeGame(gameID uint) error {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	preset, err := s.currentPreset()
+	if err != nil {
+		return err
+	}
+
+	var game models.Game
+	if err := db.First(&game, gameID).Error; err != nil {
+		return fmt.Errorf("nie znaleziono meczu ID=%d: %w", gameID, err)
+	}
+
+	var gameTeams []models.GameTeam
+	if err := db.Where("game_id = ?", gameID).Order("side ASC").Find(&gameTeams).Error; err != nil {
+		return fmt.Errorf("błąd pobierania drużyn meczu: %w", err)
+	}
+
+	createdParts, createdValues := 0, 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var parts []models.GamePart
+		if err := tx.Where("game_id = ?", gameID).Order("match_order ASC").Find(&parts).Error; err != nil {
+			return fmt.Errorf("błąd pobierania części meczu: %w", err)
+		}
+
+		// Bez części meczu nie ma slotów wartości - wynik, walkowery i tabele nie działają
+		if len(parts) == 0 && len(preset.Constant.GameParts) == 0 {
+			return fmt.Errorf("preset %s nie definiuje \"game_parts\" - mecz ID=%d nie ma części ani slotów wartości", preset.Name, gameID)
+		}
+
+		// Części meczu tylko dla meczu bez części (ręcznie utworzone zostają nietknięte)
+		if len(parts) == 0 {
+			for _, partData := range preset.Constant.GameParts {
+				part := models.GamePart{
+					GameID:             gameID,
+					Name:               partData.Name,
+					Length:             partData.Length,
+					MatchOrder:         partData.MatchOrder,
+					TimeGroup:          partData.TimeGroup,
+					IsAddedTimeAllowed: partData.IsAddedTimeAllowed,
+				}
+				if err := tx.Omit(clause.Associations).Create(&part).Error; err != nil {
+					return fmt.Errorf("błąd tworzenia części meczu %s: %w", partData.Name, err)
+				}
+				parts = append(parts, part)
+				createdParts++
+			}
+		}
+
+		for _, part := range parts {
+			for _, valueData := range partValueTemplates(preset, part.Name) {
+				for _, gameTeam := range gameTeams {
+					created, err := ensurePartValue(tx, part, gameTeam.TeamID, valueData)
+					if err != nil {
+						return err
+					}
+					if created {
+						createdValues++
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("GameService: Mecz ID=%d - utworzono %d części i %d wartości z presetu %s",
+		gameID, createdParts, createdValues, preset.Name)
+
+	if createdValues > 0 {
+		if _, err := s.scoringService.Recompute(gameID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkGameInProgress - oznacza nierozpoczęty mecz jako trwający (is_finished = false)
+//
+// Wywoływana przy ustawieniu meczu jako aktywnego - od tej chwili tabela na żywo liczy go z obecnym
+// wynikiem. Mecz zakończony pozostaje zakończony.
+func MarkGameInProgress(db *gorm.DB, gameID uint) error {
+	inProgress := false
+	result := db.Model(&models.Game{}).Where("id = ? AND is_finished IS NULL", gameID).Update("is_finished", &inProgress)
+	if result.Error != nil {
+		return fmt.Errorf("błąd oznaczania meczu ID=%d jako trwającego: %w", gameID, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("GameService: Mecz ID=%d rozpoczęty (aktywny mecz sesji)", gameID)
+	}
+	return nil
+}
+
+// currentPreset - preset użyty do utworzenia aktualnych rozgrywek
+func (s *GameService) currentPreset() (*config.CompetitionPresetFull, error) {
+	dbConfig, err := config.LoadDatabaseConfig()
+	if err != nil {
+		return nil, fmt.Errorf("błąd wczytywania konfiguracji baz danych: %w", err)
+	}
+
+	currentName := s.dbManager.GetCurrentDatabaseName()
+	presetName := ""
+	for _, comp := range dbConfig.Competitions {
+		if comp.ID == currentName {
+			presetName = comp.PresetID
+			break
+		}
+	}
+	if presetName == "" {
+		return nil, fmt.Errorf("brak presetu dla rozgrywek %s", currentName)
+	}
+
+	presetsConfig, err := config.LoadPresetsConfig()
+	if err != nil {
+		return nil, fmt.Errorf("błąd wczytywania presetów: %w", err)
+	}
+	preset := presetsConfig.GetPresetByName(presetName)
+	if preset == nil {
+		return nil, fmt.Errorf("nie znaleziono presetu %s", presetName)
+	}
+	return preset, nil
+}
+
+// partValueTemplates - szablony wartości dla części meczu (nadpisanie w części lub game_part_values)
+func partValueTemplates(preset *config.CompetitionPresetFull, partName string) []config.GamePartValueData {
+	for _, partData := range preset.Constant.GameParts {
+		if partData.Name == partName && len(partData.Values) > 0 {
+			return partData.Values
+		}
+	}
+	return preset.Constant.GamePartValues
+}
+
+// ensurePartValue - tworzy slot GamePartValue, jeśli jeszcze nie istnieje
+func ensurePartValue(tx *gorm.DB, part models.GamePart, teamID uint, valueData config.GamePartValueData) (bool, error) {
+	var count int64
+	if err := tx.Model(&models.GamePartValue{}).
+		Where("game_part_id = ? AND team_id = ? AND value_type_id = ?", part.ID, teamID, valueData.ValueTypeID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("błąd sprawdzania wartości części meczu: %w", err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	partValue := models.GamePartValue{
+		GameID:         part.GameID,
+		GamePartID:     part.ID,
+		TeamID:         teamID,
+		ValueTypeID:    valueData.ValueTypeID,
+		GameValueGroup: valueData.GameValueGroup,
+		MinValue:       valueData.MinValue,
+		MaxValue:       valueData.MaxValue,
+	}
+	if valueData.Value != nil {
+		partValue.Value = *valueData.Value
+	}
+
+	if err := tx.Omit(clause.Associations).Create(&partValue).Error; err != nil {
+		return false, fmt.Errorf("błąd tworzenia wartości części meczu: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LineupService - kadry meczowe i składy drużyn (GamePlayer/GameCoach)
+type LineupService struct {
+	dbManager     *database.Manager
+	socketService *SocketIOService
+}
+
+// NewLineupService - tworzy nowy serwis składów
+func NewLineupService(dbManager *database.Manager, socketService *SocketIOService) *LineupService {
+	return &LineupService{
+		dbManager:     dbManager,
+		socketService: socketService,
+	}
+}
+
+// getSquadLimitsFromVariable - limity kadry z Variable ("squad": {"max_players", "max_starters", "max_coaches"})
+func getSquadLimitsFromVariable(variableData map[string]interface{}) models.SquadLimits {
+	limits := models.SquadLimits{}
+
+	squad, ok := variableData["squad"].(map[string]interface{})
+	if !ok {
+		return limits
+	}
+	if value, ok := squad["max_players"].(float64); ok {
+		limits.MaxPlayers = int(value)
+	}
+	if value, ok := squad["max_starters"].(float64); ok {
+		limits.MaxStarters = int(value)
+	}
+	if value, ok := squad["max_coaches"].(float64); ok {
+		limits.MaxCoaches = int(value)
+	}
+	return limits
+}
+
+// loadSquadLimits - limity kadry aktualnych rozgrywek (bez rozgrywek - bez limitów)
+func loadSquadLimits(db *gorm.DB) models.SquadLimits {
+	_, variableData, err := loadVariable(db)
+	if err != nil {
+		return models.SquadLimits{}
+	}
+	return getSquadLimitsFromVariable(variableData)
+}
+
+// GetLineup - składy obu drużyn meczu
+func (s *LineupService) GetLineup(gameID uint) (*models.Lineup, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+	return buildLineup(db, gameID)
+}
+
+// buildLineup - składy drużyn meczu w kolejności stron
+func buildLineup(db *gorm.DB, gameID uint) (*models.Lineup, error) {
+	var gameTeams []models.GameTeam
+	if err := db.Preload("Team").Where("game_id = ?", gameID).Order("side ASC").Find(&gameTeams).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania drużyn meczu: %w", err)
+	}
+
+	var players []models.GamePlayer
+	if err := db.Preload("Player").Preload("PlayerRole").Where("game_id = ?", gameID).
+		Order("id ASC").Find(&players).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania kadry meczowej: %w", err)
+	}
+
+	var coaches []models.GameCoach
+	if err := db.Preload("Coach").Preload("CoachRole").Where("game_id = ?", gameID).
+		Order("id ASC").Find(&coaches).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania trenerów meczu: %w", err)
+	}
+
+	lineup := &models.Lineup{
+		GameID: gameID,
+		Teams:  []models.TeamLineup{},
+		Limits: loadSquadLimits(db),
+	}
+	for _, gameTeam := range gameTeams {
+		teamLineup := models.TeamLineup{
+			TeamID:      gameTeam.TeamID,
+			Side:        gameTeam.Side,
+			Team:        gameTeam.Team,
+			Starters:    []models.GamePlayer{},
+			Substitutes: []models.GamePlayer{},
+			Coaches:     []models.GameCoach{},
+		}
+		for _, player := range players {
+			if player.TeamID != gameTeam.TeamID {
+				continue
+			}
+			if player.IsStarter {
+				teamLineup.Starters = append(teamLineup.Starters, player)
+			} else {
+				teamLineup.Substitutes = append(teamLineup.Substitutes, player)
+			}
+		}
+		for _, coach := range coaches {
+			if coach.TeamID == gameTeam.TeamID {
+				teamLineup.Coaches = append(teamLineup.Coaches, coach)
+			}
+		}
+		lineup.Teams = append(lineup.Teams, teamLineup)
+	}
+	return lineup, nil
+}
+
+// SetSquad - zastępuje kadrę meczową drużyny (z walidacją numerów, kapitana i limitów) i rozgłasza lineup_update
+func (s *LineupService) SetSquad(gameID, teamID uint, req models.SquadRequest) (*models.Lineup, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var gameTeam models.GameTeam
+	if err := db.Where("game_id = ? AND team_id = ?", gameID, teamID).First(&gameTeam).Error; err != nil {
+		return nil, fmt.Errorf("drużyna ID=%d nie gra w meczu ID=%d", teamID, gameID)
+	}
+
+	gamePlayers, err := s.buildSquadPlayers(db, gameID, teamID, req.Players)
+	if err != nil {
+		return nil, err
+	}
+	gameCoaches, err := s.buildSquadCoaches(db, gameID, teamID, req.Coaches)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := loadSquadLimits(db)
+	starters := 0
+	for _, gamePlayer := range gamePlayers {
+		if gamePlayer.IsStarter {
+			starters++
+		}
+	}
+	if limits.MaxPlayers > 0 && len(gamePlayers) > limits.MaxPlayers {
+		return nil, fmt.Errorf("kadra meczowa może liczyć najwyżej %d zawodników (podano %d)", limits.MaxPlayers, len(gamePlayers))
+	}
+	if limits.MaxStarters > 0 && starters > limits.MaxStarters {
+		return nil, fmt.Errorf("pierwszy skład może liczyć najwyżej %d zawodników (podano %d)", limits.MaxStarters, starters)
+	}
+	if limits.MaxCoaches > 0 && len(gameCoaches) > limits.MaxCoaches {
+		return nil, fmt.Errorf("kadra meczowa może mieć najwyżej %d trenerów (podano %d)", limits.MaxCoaches, len(gameCoaches))
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("game_id = ? AND team_id = ?", gameID, teamID).Delete(&models.GamePlayer{}).Error; err != nil {
+			return fmt.Errorf("błąd usuwania poprzedniej kadry: %w", err)
+		}
+		if err := tx.Where("game_id = ? AND team_id = ?", gameID, teamID).Delete(&models.GameCoach{}).Error; err != nil {
+			return fmt.Errorf("błąd usuwania poprzednich trenerów: %w", err)
+		}
+		if len(gamePlayers) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&gamePlayers).Error; err != nil {
+				return fmt.Errorf("błąd zapisywania kadry meczowej: %w", err)
+			}
+		}
+		if len(gameCoaches) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&gameCoaches).Error; err != nil {
+				return fmt.Errorf("błąd zapisywania trenerów meczu: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("LineupService: Mecz ID=%d, drużyna ID=%d - kadra %d zawodników (%d w pierwszym składzie), %d trenerów",
+		gameID, teamID, len(gamePlayers), starters, len(gameCoaches))
+
+	lineup, err := s.Broadcast(gameID)
+	if err != nil {
+		return nil, err
+	}
+	lineup.Warnings = squadWarnings(db, gameID, gamePlayers)
+	return lineup, nil
+}
+
+// squadWarnings - ostrzeżenia o zawodnikach kadry zawieszonych na ten mecz (zapis kadry nie jest blokowany)
+func squadWarnings(db *gorm.DB, gameID uint, gamePlayers []models.GamePlayer) []string {
+	playerIDs := make([]uint, 0, len(gamePlayers))
+	for _, gamePlayer := range gamePlayers {
+		playerIDs = append(playerIDs, gamePlayer.PlayerID)
+	}
+
+	suspended, err := suspendedPlayers(db, gameID, playerIDs)
+	if err != nil {
+		log.Printf("LineupService: Błąd sprawdzania zawieszeń: %v", err)
+		return nil
+	}
+
+	var warnings []string
+	for _, entry := range suspended {
+		warnings = append(warnings, fmt.Sprintf("zawodnik %s jest zawieszony (pozostało meczów: %d)", entry.PlayerName, entry.GamesRemaining))
+		log.Printf("LineupService: Mecz ID=%d - w kadrze zawieszony zawodnik %s", gameID, entry.PlayerName)
+	}
+	return warnings
+}
+
+// Broadcast - rozgłasza aktualne składy meczu (lineup_update)
+func (s *LineupService) Broadcast(gameID uint) (*models.Lineup, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	lineup, err := buildLineup(db, gameID)
+	if err != nil {
+		return nil, err
+	}
+	s.socketService.BroadcastToPanel("lineup_update", lineup)
+	return lineup, nil
+}
+
+// buildSquadPlayers - zawodnicy kadry z uzupełnieniem numeru, roli i kapitana z karty zawodnika
+func (s *LineupService) buildSquadPlayers(db *gorm.DB, gameID, teamID uint, requests []models.SquadPlayerRequest) ([]models.GamePlayer, error) {
+	gamePlayers := []models.GamePlayer{}
+	seenPlayers := map[uint]bool{}
+	seenNumbers := map[string]uint{}
+	captains := 0
+
+	for _, req := range requests {
+		if seenPlayers[req.PlayerID] {
+			return nil, fmt.Errorf("zawodnik ID=%d występuje w kadrze więcej niż raz", req.PlayerID)
+		}
+		seenPlayers[req.PlayerID] = true
+
+		var player models.Player
+		if err := db.First(&player, req.PlayerID).Error; err != nil {
+			return nil, fmt.Errorf("nie znaleziono zawodnika ID=%d", req.PlayerID)
+		}
+		if player.TeamID != teamID {
+			return nil, fmt.Errorf("zawodnik %s %s nie należy do drużyny ID=%d", player.FirstName, player.LastName, teamID)
+		}
+
+		gamePlayer := models.GamePlayer{
+			GameID:       gameID,
+			TeamID:       teamID,
+			PlayerID:     player.ID,
+			Number:       player.Number,
+			PlayerRoleID: player.PlayerRoleID,
+			IsStarter:    req.IsStarter,
+			IsCaptain:    player.IsCaptain,
+		}
+		if req.Number != nil {
+			gamePlayer.Number = strings.TrimSpace(*req.Number)
+		}
+		if req.PlayerRoleID != nil {
+			var role models.PlayerRole
+			if err := db.First(&role, *req.PlayerRoleID).Error; err != nil {
+				return nil, fmt.Errorf("nie znaleziono roli zawodnika ID=%d", *req.PlayerRoleID)
+			}
+			gamePlayer.PlayerRoleID = role.ID
+		}
+		if req.IsCaptain != nil {
+			gamePlayer.IsCaptain = *req.IsCaptain
+		}
+
+		if gamePlayer.Number != "" {
+			if other, ok := seenNumbers[gamePlayer.Number]; ok {
+				return nil, fmt.Errorf("numer %s jest już przypisany zawodnikowi ID=%d", gamePlayer.Number, other)
+			}
+			seenNumbers[gamePlayer.Number] = player.ID
+		}
+		if gamePlayer.IsCaptain {
+			captains++
+		}
+		gamePlayers = append(gamePlayers, gamePlayer)
+	}
+
+	if captains > 1 {
+		return nil, fmt.Errorf("drużyna może mieć tylko jednego kapitana (podano %d)", captains)
+	}
+	return gamePlayers, nil
+}
+
+// buildSquadCoaches - trenerzy kadry z uzupełnieniem roli z karty trenera
+func (s *LineupService) buildSquadCoaches(db *gorm.DB, gameID, teamID uint, requests []models.SquadCoachRequest) ([]models.GameCoach, error) {
+	gameCoaches := []models.GameCoach{}
+	seen := map[uint]bool{}
+
+	for _, req := range requests {
+		if seen[req.CoachID] {
+			return nil, fmt.Errorf("trener ID=%d występuje w kadrze więcej niż raz", req.CoachID)
+		}
+		seen[req.CoachID] = true
+
+		var coach models.Coach
+		if err := db.First(&coach, req.CoachID).Error; err != nil {
+			return nil, fmt.Errorf("nie znaleziono trenera ID=%d", req.CoachID)
+		}
+		if coach.TeamID != teamID {
+			return nil, fmt.Errorf("trener %s %s nie należy do drużyny ID=%d", coach.FirstName, coach.LastName, teamID)
+		}
+
+		gameCoach := models.GameCoach{
+			GameID:      gameID,
+			TeamID:      teamID,
+			CoachID:     coach.ID,
+			CoachRoleID: coach.CoachRoleID,
+		}
+		if req.CoachRoleID != nil {
+			var role models.CoachRole
+			if err := db.First(&role, *req.CoachRoleID).Error; err != nil {
+				return nil, fmt.Errorf("nie znaleziono roli trenera ID=%d", *req.CoachRoleID)
+			}
+			gameCoach.CoachRoleID = role.ID
+		}
+		gameCoaches = append(gameCoaches, gameCoach)
+	}
+	return gameCoaches, nil
+}
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"sync"
+	"time"
+)
+
+// Rodzaje operacji w dzienniku meczu
+const (
+	OperationScoreAdjust = "score_adjust"
+	OperationScoreSet    = "score_set"
+	OperationEventCreate = "event_create"
+	OperationEventUpdate = "event_update"
+	OperationEventDelete = "event_delete"
+)
+
+// Akcje wpisów dziennika
+const (
+	OperationActionDo   = "do"
+	OperationActionUndo = "undo"
+	OperationActionRedo = "redo"
+)
+
+// UndoDepth - liczba ostatnich operacji, które można cofnąć
+const UndoDepth = 20
+
+// Actor - kto wykonał operację (z tożsamości tokenu API)
+type Actor struct {
+	Name string
+	Role string
+}
+
+// OperationHandler - cofanie i ponawianie operacji danego rodzaju
+type OperationHandler struct {
+	Undo func(op models.GameOperation) error
+	Redo func(op models.GameOperation) error
+}
+
+// OperationLogService - dziennik operacji meczu (wynik, wydarzenia, zmiany) z cofaniem i ponawianiem
+//
+// Dziennik jest tylko dopisywany: cofnięcie i ponowienie zapisują nowe wpisy wskazujące operację.
+// Stosy cofania/ponawiania są odtwarzane z dziennika, więc przetrwają restart serwera.
+type OperationLogService struct {
+	dbManager      *database.Manager
+	eventService   *EventService
+	scoringService *ScoringService
+	socketService  *SocketIOService
+
+	mu       sync.Mutex // operacje z dziennikiem wykonywane po kolei
+	handlers map[string]OperationHandler
+}
+
+// NewOperationLogService - tworzy nowy serwis dziennika operacji
+func NewOperationLogService(dbManager *database.Manager, eventService *EventService, scoringService *ScoringService, socketService *SocketIOService) *OperationLogService {
+	s := &OperationLogService{
+		dbManager:      dbManager,
+		eventService:   eventService,
+		scoringService: scoringService,
+		socketService:  socketService,
+		handlers:       map[string]OperationHandler{},
+	}
+
+	scoreHandler := OperationHandler{
+		Undo: func(op models.GameOperation) error { return s.restoreScore(op, op.Before) },
+		Redo: func(op models.GameOperation) error { return s.restoreScore(op, op.After) },
+	}
+	s.handlers[OperationScoreAdjust] = scoreHandler
+	s.handlers[OperationScoreSet] = scoreHandler
+
+	s.handlers[OperationEventCreate] = OperationHandler{
+		Undo: func(op models.GameOperation) error { return s.deleteEvent(op, op.After) },
+		Redo: func(op models.GameOperation) error { return s.restoreEvent(op, op.After) },
+	}
+	s.handlers[OperationEventUpdate] = OperationHandler{
+		Undo: func(op models.GameOperation) error { return s.revertEvent(op, op.Before) },
+		Redo: func(op models.GameOperation) error { return s.revertEvent(op, op.After) },
+	}
+	s.handlers[OperationEventDelete] = OperationHandler{
+		Undo: func(op models.GameOperation) error { return s.restoreEvent(op, op.Before) },
+		Redo: func(op models.GameOperation) error { return s.deleteEvent(op, op.Before) },
+	}
+	return s
+}
+
+// RegisterKind - rejestruje cofanie/ponawianie dla rodzaju operacji z innego serwisu
+// (funkcje wywoływane pod blokadą dziennika - nie mogą zapisywać operacji)
+func (s *OperationLogService) RegisterKind(kind string, handler OperationHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = handler
+}
+
+// Record - dopisuje wykonaną operację do dziennika meczu
+func (s *OperationLogService) Record(gameID uint, kind, summary string, before, after interface{}, actor Actor) (*models.GameOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.record(gameID, OperationActionDo, kind, nil, summary, before, after, actor)
+}
+
+// record - zapisuje wpis dziennika i rozgłasza game_operation (wywoływane pod blokadą)
+func (s *OperationLogService) record(gameID uint, action, kind string, targetID *uint, summary string, before, after interface{}, actor Actor) (*models.GameOperation, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	op := models.GameOperation{
+		GameID:    gameID,
+		Action:    action,
+		Kind:      kind,
+		TargetID:  targetID,
+		Summary:   summary,
+		Actor:     actor.Name,
+		ActorRole: actor.Role,
+		CreatedAt: time.Now(),
+	}
+	if op.Actor == "" {
+		op.Actor = "anonim"
+	}
+
+	for _, field := range []struct {
+		value interface{}
+		dest  *string
+	}{{before, &op.Before}, {after, &op.After}} {
+		if field.value == nil {
+			continue
+		}
+		if raw, ok := field.value.(string); ok {
+			*field.dest = raw
+			continue
+		}
+		data, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("błąd serializacji stanu operacji: %w", err)
+		}
+		*field.dest = string(data)
+	}
+
+	if err := db.Create(&op).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania operacji: %w", err)
+	}
+
+	log.Printf("OperationLog: Mecz ID=%d - %s %s przez %s: %s", gameID, action, kind, op.Actor, summary)
+	s.socketService.BroadcastToPanel("game_operation", op)
+	return &op, nil
+}
+
+// ListOperations - ostatnie wpisy dziennika meczu (najnowsze pierwsze)
+func (s *OperationLogService) ListOperations(gameID uint, limit int) ([]models.GameOperation, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var ops []models.GameOperation
+	if err := db.Where("game_id = ?", gameID).Order("id DESC").Limit(limit).Find(&ops).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania dziennika operacji: %w", err)
+	}
+	return ops, nil
+}
+
+// stacks - odtwarza stosy cofania i ponawiania (ID operacji "do") z dziennika meczu
+func (s *OperationLogService) stacks(gameID uint) ([]uint, []uint, map[uint]models.GameOperation, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, nil, nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var ops []models.GameOperation
+	if err := db.Where("game_id = ?", gameID).Order("id ASC").Find(&ops).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("błąd pobierania dziennika operacji: %w", err)
+	}
+
+	byID := map[uint]models.GameOperation{}
+	var done, undone []uint
+	for _, op := range ops {
+		switch op.Action {
+		case OperationActionDo:
+			byID[op.ID] = op
+			done = append(done, op.ID)
+			if len(done) > UndoDepth {
+				done = done[len(done)-UndoDepth:]
+			}
+			undone = nil
+		case OperationActionUndo:
+			if len(done) > 0 {
+				undone = append(undone, done[len(done)-1])
+				done = done[:len(done)-1]
+			}
+		case OperationActionRedo:
+			if len(undone) > 0 {
+				done = append(done, undone[len(undone)-1])
+				undone = undone[:len(undone)-1]
+			}
+		}
+	}
+	return done, undone, byID, nil
+}
+
+// Undo - cofa ostatnie operacje meczu (maksymalnie steps, w kolejności od najnowszej)
+func (s *OperationLogService) Undo(gameID uint, steps int, actor Actor) ([]models.GameOperation, error) {
+	return s.step(gameID, steps, actor, OperationActionUndo)
+}
+
+// Redo - ponawia ostatnio cofnięte operacje meczu
+func (s *OperationLogService) Redo(gameID uint, steps int, actor Actor) ([]models.GameOperation, error) {
+	return s.step(gameID, steps, actor, OperationActionRedo)
+}
+
+// step - wspólna logika cofania i ponawiania
+func (s *OperationLogService) step(gameID uint, steps int, actor Actor, action string) ([]models.GameOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if steps <= 0 {
+		steps = 1
+	}
+
+	var result []models.GameOperation
+	for i := 0; i < steps; i++ {
+		done, undone, byID, err := s.stacks(gameID)
+		if err != nil {
+			return result, err
+		}
+
+		stack := done
+		if action == OperationActionRedo {
+			stack = undone
+		}
+		if len(stack) == 0 {
+			if len(result) > 0 {
+				break
+			}
+			if action == OperationActionRedo {
+				return nil, fmt.Errorf("brak operacji do ponowienia")
+			}
+			return nil, fmt.Errorf("brak operacji do cofnięcia")
+		}
+
+		target := byID[stack[len(stack)-1]]
+		handler, ok := s.handlers[target.Kind]
+		if !ok {
+			return result, fmt.Errorf("operacji %s nie można cofnąć", target.Kind)
+		}
+
+		apply := handler.Undo
+		prefix := "Cofnięto"
+		if action == OperationActionRedo {
+			apply = handler.Redo
+			prefix = "Ponowiono"
+		}
+		if err := apply(target); err != nil {
+			return result, fmt.Errorf("błąd operacji #%d: %w", target.ID, err)
+		}
+
+		targetID := target.ID
+		entry, err := s.record(gameID, action, target.Kind, &targetID, prefix+": "+target.Summary, nil, nil, actor)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, *entry)
+	}
+	return result, nil
+}
+
+// AdjustScore - zmiana wyniku o delta z wpisem w dzienniku
+func (s *OperationLogService) AdjustScore(gameID uint, req models.ScoreAdjustRequest, actor Actor) (*models.ScoreUpdate, error) {
+	update, change, err := s.scoringService.adjust(gameID, req)
+	if err != nil || change == nil {
+		return update, err
+	}
+	s.recordScore(OperationScoreAdjust, change, actor)
+	return update, nil
+}
+
+// SetScore - ustawienie wyniku z wpisem w dzienniku
+func (s *OperationLogService) SetScore(gameID uint, req models.ScoreAdjustRequest, actor Actor) (*models.ScoreUpdate, error) {
+	update, change, err := s.scoringService.set(gameID, req)
+	if err != nil || change == nil {
+		return update, err
+	}
+	s.recordScore(OperationScoreSet, change, actor)
+	return update, nil
+}
+
+// recordScore - zapisuje zmianę wartości jako stan przed/po (ScoreAdjustRequest z value)
+func (s *OperationLogService) recordScore(kind string, change *ValueChange, actor Actor) {
+	partID := change.GamePartID
+	before, after := change.Previous, change.Current
+	state := func(value *int) models.ScoreAdjustRequest {
+		return models.ScoreAdjustRequest{
+			TeamID:      change.TeamID,
+			ValueTypeID: change.ValueTypeID,
+			GamePartID:  &partID,
+			Value:       value,
+		}
+	}
+
+	summary := fmt.Sprintf("%s: drużyna #%d, część #%d: %d → %d",
+		s.valueTypeName(change.ValueTypeID), change.TeamID, change.GamePartID, before, after)
+	if _, err := s.Record(change.GameID, kind, summary, state(&before), state(&after), actor); err != nil {
+		log.Printf("OperationLog: %v", err)
+	}
+}
+
+// restoreScore - ustawia wartość części meczu ze stanu zapisanego w dzienniku
+func (s *OperationLogService) restoreScore(op models.GameOperation, state string) error {
+	var req models.ScoreAdjustRequest
+	if err := json.Unmarshal([]byte(state), &req); err != nil {
+		return fmt.Errorf("nieprawidłowy stan wyniku: %w", err)
+	}
+	_, _, err := s.scoringService.set(op.GameID, req)
+	return err
+}
+
+// valueTypeName - nazwa typu wartości do opisu operacji
+func (s *OperationLogService) valueTypeName(valueTypeID uint) string {
+	var valueType models.ValueType
+	if db := s.dbManager.GetDB(); db != nil && db.First(&valueType, valueTypeID).Error == nil {
+		return valueType.Name
+	}
+	return fmt.Sprintf("Wartość #%d", valueTypeID)
+}
+
+// CreateEvent - dodanie wydarzenia z wpisem w dzienniku
+func (s *OperationLogService) CreateEvent(gameID uint, req models.EventRequest, actor Actor) (*models.Event, error) {
+	event, err := s.eventService.CreateEvent(gameID, req)
+	if err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf("Dodano wydarzenie %s (%s)", event.Name, formatEventTime(event.EventTime))
+	if _, err := s.Record(gameID, OperationEventCreate, summary, nil, eventState(event), actor); err != nil {
+		log.Printf("OperationLog: %v", err)
+	}
+	return event, nil
+}
+
+// UpdateEvent - edycja wydarzenia z wpisem w dzienniku
+func (s *OperationLogService) UpdateEvent(gameID, eventID uint, req models.EventRequest, actor Actor) (*models.Event, error) {
+	previous, err := s.eventService.GetEvent(gameID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.eventService.UpdateEvent(gameID, eventID, req)
+	if err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf("Zmieniono wydarzenie %s (%s)", event.Name, formatEventTime(event.EventTime))
+	if _, err := s.Record(gameID, OperationEventUpdate, summary, eventState(previous), eventState(event), actor); err != nil {
+		log.Printf("OperationLog: %v", err)
+	}
+	return event, nil
+}
+
+// DeleteEvent - usunięcie wydarzenia z wpisem w dzienniku
+func (s *OperationLogService) DeleteEvent(gameID, eventID uint, actor Actor) error {
+	event, err := s.eventService.GetEvent(gameID, eventID)
+	if err != nil {
+		return err
+	}
+	if err := s.eventService.DeleteEvent(gameID, eventID); err != nil {
+		return err
+	}
+	summary := fmt.Sprintf("Usunięto wydarzenie %s (%s)", event.Name, formatEventTime(event.EventTime))
+	if _, err := s.Record(gameID, OperationEventDelete, summary, eventState(event), nil, actor); err != nil {
+		log.Printf("OperationLog: %v", err)
+	}
+	return nil
+}
+
+// eventState - wydarzenie bez relacji (stan zapisywany w dzienniku)
+func eventState(event *models.Event) models.Event {
+	return models.Event{
+		ID:          event.ID,
+		Name:        event.Name,
+		EventTypeID: event.EventTypeID,
+		GameID:      event.GameID,
+		GamePartID:  event.GamePartID,
+		EventTime:   event.EventTime,
+		CameraID:    event.CameraID,
+		TeamID:      event.TeamID,
+		PlayerID:    event.PlayerID,
+	}
+}
+
+// parseEventState - odczytuje wydarzenie ze stanu zapisanego w dzienniku
+func parseEventState(state string) (models.Event, error) {
+	var event models.Event
+	if err := json.Unmarshal([]byte(state), &event); err != nil {
+		return event, fmt.Errorf("nieprawidłowy stan wydarzenia: %w", err)
+	}
+	return event, nil
+}
+
+// deleteEvent - usuwa wydarzenie wskazane w stanie operacji
+func (s *OperationLogService) deleteEvent(op models.GameOperation, state string) error {
+	event, err := parseEventState(state)
+	if err != nil {
+		return err
+	}
+	return s.eventService.DeleteEvent(op.GameID, event.ID)
+}
+
+// restoreEvent - przywraca usunięte wydarzenie wskazane w stanie operacji
+func (s *OperationLogService) restoreEvent(op models.GameOperation, state string) error {
+	event, err := parseEventState(state)
+	if err != nil {
+		return err
+	}
+	_, err = s.eventService.RestoreEvent(op.GameID, event.ID)
+	return err
+}
+
+// revertEvent - przywraca pola wydarzenia ze stanu operacji
+func (s *OperationLogService) revertEvent(op models.GameOperation, state string) error {
+	event, err := parseEventState(state)
+	if err != nil {
+		return err
+	}
+	_, err = s.eventService.RevertEvent(op.GameID, event)
+	return err
+}
+
+// formatEventTime - czas wydarzenia w formacie mm:ss
+func formatEventTime(seconds int) string {
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"recorder-server/internal/state"
+	"strings"
+	"time"
+)
+
+// RecorderCommandService - serwis poleceń dla rejestratorów kamer (dzielenie pliku, klipy, jakość, miniatury)
+type RecorderCommandService struct {
+	dbManager     *database.Manager
+	appState      *state.AppState
+	socketService *SocketIOService
+}
+
+// NewRecorderCommandService - tworzy nowy serwis poleceń rejestratorów
+func NewRecorderCommandService(dbManager *database.Manager, appState *state.AppState, socketService *SocketIOService) *RecorderCommandService {
+	return &RecorderCommandService{
+		dbManager:     dbManager,
+		appState:      appState,
+		socketService: socketService,
+	}
+}
+
+// Issue - zapisuje polecenie w logu i rozsyła je do rejestratorów
+func (s *RecorderCommandService) Issue(req models.RecorderCommandRequest, issuedBy string) (*models.RecorderCommand, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	payload := map[string]interface{}{}
+	switch req.Command {
+	case models.RecorderCommandSplitFile, models.RecorderCommandTakeSnapshot:
+		// Brak parametrów
+	case models.RecorderCommandMarkClip:
+		if req.InTime == nil || req.OutTime == nil {
+			return nil, fmt.Errorf("mark_clip wymaga in_time i out_time")
+		}
+		if !req.OutTime.After(*req.InTime) {
+			return nil, fmt.Errorf("out_time musi być późniejszy niż in_time")
+		}
+		payload["in_time"] = req.InTime
+		payload["out_time"] = req.OutTime
+		payload["label"] = req.Label
+	case models.RecorderCommandSetQuality:
+		if !isQualityProfile(req.Profile) {
+			return nil, fmt.Errorf("nieznany profil jakości: %s (dostępne: %s)",
+				req.Profile, strings.Join(models.QualityProfiles, ", "))
+		}
+		payload["profile"] = req.Profile
+	default:
+		return nil, fmt.Errorf("nieznane polecenie: %s", req.Command)
+	}
+
+	cameras := req.Cameras
+	if len(cameras) == 0 {
+		cameras = s.appState.GetActiveCameras()
+	}
+	if len(cameras) == 0 {
+		return nil, fmt.Errorf("brak kamer docelowych")
+	}
+
+	if issuedBy == "" {
+		issuedBy = "system"
+	}
+
+	camerasJSON, _ := json.Marshal(cameras)
+	payloadJSON, _ := json.Marshal(payload)
+
+	command := models.RecorderCommand{
+		Command:  req.Command,
+		Cameras:  string(camerasJSON),
+		Payload:  string(payloadJSON),
+		IssuedBy: issuedBy,
+		IssuedAt: time.Now(),
+	}
+	if err := db.Create(&command).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania polecenia: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"command_id": command.ID,
+		"cameras":    cameras,
+	}
+	for k, v := range payload {
+		data[k] = v
+	}
+	s.socketService.BroadcastRecorderCommand(req.Command, data)
+
+	log.Printf("RecorderCommandService: Wysłano polecenie %s ID=%d do kamer %v (%s)",
+		command.Command, command.ID, cameras, issuedBy)
+	return &command, nil
+}
+
+// SplitOnPeriodChange - dzieli pliki nagrań przy zmianie części meczu (tylko podczas nagrywania)
+func (s *RecorderCommandService) SplitOnPeriodChange() {
+	if !s.appState.IsRecording() {
+		return
+	}
+	if _, err := s.Issue(models.RecorderCommandRequest{Command: models.RecorderCommandSplitFile}, "system"); err != nil {
+		log.Printf("RecorderCommandService: Błąd dzielenia pliku przy zmianie części meczu: %v", err)
+	}
+}
+
+// Ack - zapisuje potwierdzenie polecenia od rejestratora kamery
+func (s *RecorderCommandService) Ack(data models.RecorderCommandAckData) (*models.RecorderCommandAck, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	if data.CameraName == "" {
+		return nil, fmt.Errorf("nazwa kamery jest wymagana")
+	}
+	if data.Status == "" {
+		data.Status = "ok"
+	}
+
+	var command models.RecorderCommand
+	if err := db.First(&command, data.CommandID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono polecenia ID=%d: %w", data.CommandID, err)
+	}
+
+	ack := models.RecorderCommandAck{
+		RecorderCommandID: command.ID,
+		CameraName:        data.CameraName,
+		Status:            data.Status,
+		Message:           data.Message,
+		AckedAt:           time.Now(),
+	}
+	if err := db.Create(&ack).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania potwierdzenia: %w", err)
+	}
+
+	s.socketService.BroadcastToPanel("recorder_command_ack", map[string]interface{}{
+		"command_id":  command.ID,
+		"command":     command.Command,
+		"camera_name": ack.CameraName,
+		"status":      ack.Status,
+		"message":     ack.Message,
+	})
+
+	log.Printf("RecorderCommandService: Kamera %s potwierdziła polecenie %s ID=%d (%s)",
+		ack.CameraName, command.Command, command.ID, ack.Status)
+	return &ack, nil
+}
+
+// ListCommands - zwraca ostatnie polecenia z potwierdzeniami
+func (s *RecorderCommandService) ListCommands(limit int) ([]models.RecorderCommand, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var commands []models.RecorderCommand
+	if err := db.Preload("Acks").Order("issued_at DESC").Limit(limit).Find(&commands).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania poleceń: %w", err)
+	}
+	return commands, nil
+}
+
+// snapshotDir - katalog miniatur aktualnych rozgrywek
+func (s *RecorderCommandService) snapshotDir() (string, error) {
+	competitionID := s.dbManager.GetCurrentDatabaseName()
+	if competitionID == "" {
+		return "", fmt.Errorf("brak aktywnych rozgrywek")
+	}
+	return filepath.Join("./competitions", competitionID, "snapshots"), nil
+}
+
+// SaveSnapshot - zapisuje miniaturę przesłaną przez rejestrator i powiadamia panel operatora
+func (s *RecorderCommandService) SaveSnapshot(cameraName string, commandID uint, ext string, src io.Reader) (string, error) {
+	if cameraName == "" {
+		return "", fmt.Errorf("nazwa kamery jest wymagana")
+	}
+	ext = strings.ToLower(ext)
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+		return "", fmt.Errorf("nieobsługiwany format miniatury: %s", ext)
+	}
+
+	dir, err := s.snapshotDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("błąd tworzenia katalogu miniatur: %w", err)
+	}
+
+	fileName := fmt.Sprintf("%s_%s%s", sanitizeFileName(cameraName), time.Now().Format("20060102_150405"), ext)
+	dst, err := os.Create(filepath.Join(dir, fileName))
+	if err != nil {
+		return "", fmt.Errorf("błąd zapisu miniatury: %w", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return "", fmt.Errorf("błąd zapisu miniatury: %w", err)
+	}
+
+	s.socketService.BroadcastToPanel("snapshot_ready", map[string]interface{}{
+		"command_id":  commandID,
+		"camera_name": cameraName,
+		"url":         "/api/recorders/snapshots/" + fileName,
+	})
+
+	log.Printf("RecorderCommandService: Zapisano miniaturę kamery %s: %s", cameraName, fileName)
+	return fileName, nil
+}
+
+// SnapshotPath - zwraca ścieżkę do pliku miniatury (bez możliwości wyjścia poza katalog)
+func (s *RecorderCommandService) SnapshotPath(fileName string) (string, error) {
+	dir, err := s.snapshotDir()
+	if err != nil {
+		return "", err
+	}
+	if fileName == "" || fileName != filepath.Base(fileName) || strings.HasPrefix(fileName, ".") {
+		return "", fmt.Errorf("nieprawidłowa nazwa pliku")
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// isQualityProfile - sprawdza czy profil jakości jest dozwolony
+func isQualityProfile(profile string) bool {
+	for _, p := range models.QualityProfiles {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeFileName - zamienia znaki niedozwolone w nazwie pliku na podkreślenia
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
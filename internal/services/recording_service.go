@@ -0,0 +1,166 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"strings"
+	"time"
+)
+
+// RecordingService - serwis zapisujący sesje nagrywania i pliki kamer w bazie
+type RecordingService struct {
+	dbManager *database.Manager
+}
+
+// NewRecordingService - tworzy nowy serwis nagrań
+func NewRecordingService(dbManager *database.Manager) *RecordingService {
+	return &RecordingService{
+		dbManager: dbManager,
+	}
+}
+
+// StartSession - zapisuje początek sesji nagrywania dla aktywnego meczu i części meczu
+func (s *RecordingService) StartSession(activeCameras []string) (*models.RecordingSession, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	// Zamknij sesje, które nie zostały zatrzymane (np. po restarcie serwera)
+	now := time.Now()
+	db.Model(&models.RecordingSession{}).Where("stopped_at IS NULL").Update("stopped_at", now)
+
+	camerasJSON, _ := json.Marshal(activeCameras)
+
+	session := models.RecordingSession{
+		StartedAt: now,
+		Cameras:   string(camerasJSON),
+	}
+
+	var activeSession models.ActiveSession
+	if err := db.First(&activeSession).Error; err == nil {
+		session.GameID = activeSession.GameID
+		session.GamePartID = activeSession.GamePartID
+	}
+
+	if err := db.Create(&session).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania sesji nagrywania: %w", err)
+	}
+
+	log.Printf("RecordingService: Rozpoczęto sesję nagrywania ID=%d (mecz: %v, część: %v)",
+		session.ID, session.GameID, session.GamePartID)
+	return &session, nil
+}
+
+// StopSession - zapisuje zakończenie aktualnej sesji nagrywania
+func (s *RecordingService) StopSession() (*models.RecordingSession, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var session models.RecordingSession
+	if err := db.Where("stopped_at IS NULL").Order("started_at DESC").First(&session).Error; err != nil {
+		return nil, fmt.Errorf("brak aktywnej sesji nagrywania: %w", err)
+	}
+
+	now := time.Now()
+	session.StoppedAt = &now
+	if err := db.Save(&session).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania sesji nagrywania: %w", err)
+	}
+
+	log.Printf("RecordingService: Zakończono sesję nagrywania ID=%d", session.ID)
+	return &session, nil
+}
+
+// AddFile - zapisuje plik zgłoszony przez rejestrator kamery
+func (s *RecordingService) AddFile(report models.RecordingFileReport) (*models.RecordingFile, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	if report.CameraName == "" || report.FileName == "" {
+		return nil, fmt.Errorf("camera_name i file_name są wymagane")
+	}
+
+	// Bez ID sesji przypisz plik do ostatniej rozpoczętej sesji
+	var session models.RecordingSession
+	query := db.Order("started_at DESC")
+	if report.SessionID != 0 {
+		query = db.Where("id = ?", report.SessionID)
+	}
+	if err := query.First(&session).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono sesji nagrywania: %w", err)
+	}
+
+	// Ponowne zgłoszenie tego samego pliku aktualizuje istniejący wpis
+	var file models.RecordingFile
+	err := db.Where("recording_session_id = ? AND camera_name = ? AND file_name = ?",
+		session.ID, report.CameraName, report.FileName).First(&file).Error
+	if err != nil {
+		file = models.RecordingFile{
+			RecordingSessionID: session.ID,
+			CameraName:         report.CameraName,
+			FileName:           report.FileName,
+		}
+	}
+
+	if report.RecordStartTime != nil {
+		file.RecordStartTime = report.RecordStartTime
+	}
+	if report.RecordStopTime != nil {
+		file.RecordStopTime = report.RecordStopTime
+	}
+	if report.SizeBytes > 0 {
+		file.SizeBytes = report.SizeBytes
+	}
+	if report.SHA256 != "" {
+		file.SHA256 = strings.ToLower(report.SHA256)
+	}
+
+	if err := db.Save(&file).Error; err != nil {
+		return nil, fmt.Errorf("błąd zapisywania pliku nagrania: %w", err)
+	}
+
+	log.Printf("RecordingService: Zapisano plik %s kamery %s (sesja ID=%d)",
+		file.FileName, file.CameraName, session.ID)
+	return &file, nil
+}
+
+// ListSessions - zwraca sesje nagrywania (opcjonalnie tylko dla danego meczu)
+func (s *RecordingService) ListSessions(gameID *uint) ([]models.RecordingSession, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	query := db.Preload("GamePart").Preload("Files").Order("started_at ASC")
+	if gameID != nil {
+		query = query.Where("game_id = ?", *gameID)
+	}
+
+	var sessions []models.RecordingSession
+	if err := query.Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania sesji nagrywania: %w", err)
+	}
+	return sessions, nil
+}
+
+// GetSession - zwraca sesję nagrywania z plikami
+func (s *RecordingService) GetSession(sessionID uint) (*models.RecordingSession, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var session models.RecordingSession
+	if err := db.Preload("Game").Preload("GamePart").Preload("Files").First(&session, sessionID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono sesji nagrywania: %w", err)
+	}
+	return &session, nil
+}
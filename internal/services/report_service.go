@@ -0,0 +1,304 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"recorder-server/internal/pdf"
+	"sort"
+	"time"
+)
+
+// reportTemplatePath - szablon HTML protokołu meczu
+const reportTemplatePath = "web/templates/match_report.html"
+
+// ReportService - oficjalny protokół meczu (HTML i PDF) zapisywany w katalogu rozgrywek
+type ReportService struct {
+	dbManager   *database.Manager
+	crewService *CrewService
+}
+
+// NewReportService - tworzy nowy serwis protokołów
+func NewReportService(dbManager *database.Manager, crewService *CrewService) *ReportService {
+	return &ReportService{
+		dbManager:   dbManager,
+		crewService: crewService,
+	}
+}
+
+// BuildReport - zbiera dane protokołu: składy, trenerzy, sędziowie, wydarzenia protokołowe i wartości końcowe
+func (s *ReportService) BuildReport(gameID uint) (*models.MatchReport, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var game models.Game
+	if err := db.First(&game, gameID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono meczu: %w", err)
+	}
+
+	report := &models.MatchReport{
+		Game:        game,
+		KickOff:     game.DateTime,
+		Teams:       []models.MatchReportTeam{},
+		Events:      []models.MatchReportEvent{},
+		GeneratedAt: time.Now(),
+	}
+	if start, ok := parseGameDateTime(game.DateTime); ok {
+		report.KickOff = start.Format("02.01.2006 15:04")
+	}
+	if competition, _, err := loadVariable(db); err == nil {
+		report.Competition = competition.Name
+	}
+
+	var field models.Field
+	if game.FieldID != 0 && db.First(&field, game.FieldID).Error == nil {
+		report.Field = &field
+	}
+
+	lineup, err := buildLineup(db, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []models.GameValue
+	if err := db.Preload("ValueType").Where("game_id = ?", gameID).Order("value_type_id ASC").Find(&values).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania wartości meczu: %w", err)
+	}
+
+	teamNames := map[uint]string{}
+	numbers := map[uint]string{}
+	for _, teamLineup := range lineup.Teams {
+		teamNames[teamLineup.TeamID] = teamLineup.Team.Name
+		for _, players := range [][]models.GamePlayer{teamLineup.Starters, teamLineup.Substitutes} {
+			for _, player := range players {
+				numbers[player.PlayerID] = player.Number
+			}
+		}
+
+		reportTeam := models.MatchReportTeam{TeamLineup: teamLineup, Values: []models.MatchReportValue{}}
+		for _, value := range values {
+			if value.TeamID != teamLineup.TeamID {
+				continue
+			}
+			reportTeam.Values = append(reportTeam.Values, models.MatchReportValue{
+				ValueTypeID: value.ValueTypeID,
+				Name:        value.ValueType.Name,
+				Value:       value.Value,
+				IsDSQ:       value.IsDSQ,
+			})
+		}
+		report.Teams = append(report.Teams, reportTeam)
+	}
+
+	if report.Referees, err = s.crewService.GetGameReferees(gameID); err != nil {
+		return nil, err
+	}
+
+	// Wydarzenia protokołowe w kolejności części meczu i czasu
+	var events []models.Event
+	if err := db.Preload("EventType").Preload("GamePart").Preload("Player").
+		Joins("JOIN event_types ON event_types.id = events.event_type_id AND event_types.is_in_protocol = ?", true).
+		Joins("LEFT JOIN game_parts ON game_parts.id = events.game_part_id").
+		Where("events.game_id = ?", gameID).
+		Order("game_parts.match_order ASC, events.event_time ASC, events.id ASC").
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania wydarzeń protokołowych: %w", err)
+	}
+	for _, event := range events {
+		reportEvent := models.MatchReportEvent{
+			ID:       event.ID,
+			Type:     event.EventType.Name,
+			PartName: event.GamePart.Name,
+			Time:     formatEventTime(event.EventTime),
+		}
+		if event.TeamID != nil {
+			reportEvent.TeamName = teamNames[*event.TeamID]
+		}
+		if event.Player != nil {
+			reportEvent.PlayerName = event.Player.FirstName + " " + event.Player.LastName
+			reportEvent.Number = numbers[event.Player.ID]
+		}
+		report.Events = append(report.Events, reportEvent)
+	}
+
+	return report, nil
+}
+
+// Generate - tworzy protokół w formacie html/pdf, zapisuje go w katalogu rozgrywek i zwraca ścieżkę pliku
+func (s *ReportService) Generate(gameID uint, format string) (string, error) {
+	competitionID := s.dbManager.GetCurrentDatabaseName()
+	if competitionID == "" {
+		return "", fmt.Errorf("brak aktywnych rozgrywek")
+	}
+
+	report, err := s.BuildReport(gameID)
+	if err != nil {
+		return "", err
+	}
+
+	var content []byte
+	switch format {
+	case models.ReportFormatHTML:
+		content, err = RenderReportHTML(report)
+	case models.ReportFormatPDF:
+		content = RenderReportPDF(report)
+	default:
+		return "", fmt.Errorf("nieznany format protokołu: %s", format)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join("./competitions", competitionID, "reports")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("błąd tworzenia katalogu protokołów: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("game_%d.%s", gameID, format))
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", fmt.Errorf("błąd zapisu protokołu: %w", err)
+	}
+
+	log.Printf("ReportService: Zapisano protokół meczu ID=%d: %s", gameID, path)
+	return path, nil
+}
+
+// RenderReportHTML - protokół jako strona HTML
+func RenderReportHTML(report *models.MatchReport) ([]byte, error) {
+	tmpl, err := template.ParseFiles(reportTemplatePath)
+	if err != nil {
+		return nil, fmt.Errorf("błąd wczytywania szablonu protokołu: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("błąd renderowania protokołu: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// reportWriter - kursor wierszy protokołu PDF z automatycznym łamaniem stron
+type reportWriter struct {
+	doc *pdf.Document
+	y   float64
+}
+
+const (
+	reportMargin     = 40.0
+	reportLineHeight = 14.0
+)
+
+// ensure - przechodzi na nową stronę, gdy brakuje miejsca na kolejne wiersze
+func (w *reportWriter) ensure(lines int) {
+	if w.doc.PageCount() == 0 || w.y+float64(lines)*reportLineHeight > pdf.PageHeight-reportMargin {
+		w.doc.AddPage()
+		w.y = reportMargin
+	}
+}
+
+// heading - nagłówek sekcji z linią
+func (w *reportWriter) heading(text string) {
+	w.ensure(3)
+	w.y += reportLineHeight / 2
+	w.doc.Text(reportMargin, w.y, 12, true, text)
+	w.y += 4
+	w.doc.Line(reportMargin, w.y, pdf.PageWidth-reportMargin, w.y)
+	w.y += reportLineHeight
+}
+
+// row - wiersz tekstu w kolumnach (x względem lewego marginesu)
+func (w *reportWriter) row(columns map[float64]string) {
+	w.ensure(1)
+	positions := make([]float64, 0, len(columns))
+	for x := range columns {
+		positions = append(positions, x)
+	}
+	sort.Float64s(positions)
+	for _, x := range positions {
+		w.doc.Text(reportMargin+x, w.y, 10, false, columns[x])
+	}
+	w.y += reportLineHeight
+}
+
+// RenderReportPDF - protokół jako dokument PDF
+func RenderReportPDF(report *models.MatchReport) []byte {
+	w := &reportWriter{doc: pdf.NewDocument()}
+	w.ensure(1)
+
+	title := "Protokół meczu"
+	for i, team := range report.Teams {
+		if i == 0 {
+			title += ": "
+		} else {
+			title += " - "
+		}
+		title += team.Team.Name
+	}
+	w.y += 6
+	w.doc.Text(reportMargin, w.y, 16, true, title)
+	w.y += reportLineHeight * 1.5
+
+	if report.Competition != "" {
+		w.row(map[float64]string{0: "Rozgrywki:", 90: report.Competition})
+	}
+	w.row(map[float64]string{0: "Rozpoczęcie:", 90: report.KickOff})
+	w.row(map[float64]string{0: "Kolejka:", 90: fmt.Sprint(report.Game.Round)})
+	if report.Field != nil {
+		w.row(map[float64]string{0: "Obiekt:", 90: report.Field.Name + " " + report.Field.City})
+	}
+
+	w.heading("Wynik")
+	for _, team := range report.Teams {
+		for _, value := range team.Values {
+			text := fmt.Sprint(value.Value)
+			if value.IsDSQ {
+				text += " (DSQ)"
+			}
+			w.row(map[float64]string{0: team.Team.Name, 200: value.Name, 380: text})
+		}
+	}
+
+	for _, team := range report.Teams {
+		w.heading("Skład: " + team.Team.Name)
+		for _, players := range [][]models.GamePlayer{team.Starters, team.Substitutes} {
+			for _, player := range players {
+				name := player.Player.FirstName + " " + player.Player.LastName
+				if player.IsCaptain {
+					name += " (K)"
+				}
+				status := "rezerwowy"
+				if player.IsStarter {
+					status = "podstawowy"
+				}
+				w.row(map[float64]string{0: player.Number, 30: name, 260: player.PlayerRole.Name, 400: status})
+			}
+		}
+		for _, coach := range team.Coaches {
+			w.row(map[float64]string{30: coach.Coach.FirstName + " " + coach.Coach.LastName, 260: coach.CoachRole.Name, 400: "trener"})
+		}
+	}
+
+	w.heading("Sędziowie")
+	for _, referee := range report.Referees {
+		w.row(map[float64]string{0: referee.RefereeRole.Name, 200: referee.Referee.FirstName + " " + referee.Referee.LastName})
+	}
+
+	w.heading("Przebieg meczu")
+	for _, event := range report.Events {
+		player := event.PlayerName
+		if event.Number != "" {
+			player = event.Number + ". " + player
+		}
+		w.row(map[float64]string{0: event.PartName, 60: event.Time, 110: event.Type, 220: event.TeamName, 360: player})
+	}
+
+	w.y += reportLineHeight
+	w.row(map[float64]string{0: "Wygenerowano " + report.GeneratedAt.Format("02.01.2006 15:04")})
+	return w.doc.Bytes()
+}
@@ -0,0 +1,486 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ScoringService - serwis wyniku na żywo (GamePartValue -> GameValue)
+//
+// Zasady agregacji:
+//   - wartości części meczu z GameValueGroup = nil lub z najniższą grupą tworzą GameValue (wynik główny),
+//     pozostałe grupy (np. rzuty karne) są raportowane osobno w value_groups
+//   - sumy w grupach GamePart.TimeGroup (np. połowa + jej dogrywka) są raportowane w time_groups
+type ScoringService struct {
+	dbManager     *database.Manager
+	socketService *SocketIOService
+
+	mu        sync.Mutex // zmiany wyniku wykonywane po kolei
+	listeners []ValueChangeListener
+}
+
+// ValueChange - zmiana wartości statystyki drużyny w części meczu
+type ValueChange struct {
+	GameID      uint
+	GamePartID  uint
+	TeamID      uint
+	ValueTypeID uint
+	Previous    int
+	Current     int
+}
+
+// ValueChangeListener - funkcja wywoływana po zapisaniu zmiany wartości
+// (wywoływana pod blokadą serwisu - nie może zmieniać wyniku)
+type ValueChangeListener func(change ValueChange)
+
+// NewScoringService - tworzy nowy serwis wyniku
+func NewScoringService(dbManager *database.Manager, socketService *SocketIOService) *ScoringService {
+	return &ScoringService{
+		dbManager:     dbManager,
+		socketService: socketService,
+	}
+}
+
+// AddListener - rejestruje funkcję wywoływaną po każdej zmianie wartości części meczu
+func (s *ScoringService) AddListener(listener ValueChangeListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// Adjust - zmienia wartość statystyki drużyny o delta w części meczu
+func (s *ScoringService) Adjust(gameID uint, req models.ScoreAdjustRequest) (*models.ScoreUpdate, error) {
+	update, _, err := s.adjust(gameID, req)
+	return update, err
+}
+
+// Set - ustawia wartość statystyki drużyny w części meczu
+func (s *ScoringService) Set(gameID uint, req models.ScoreAdjustRequest) (*models.ScoreUpdate, error) {
+	update, _, err := s.set(gameID, req)
+	return update, err
+}
+
+// adjust - Adjust zwracający także zapisaną zmianę (nil gdy wartość się nie zmieniła)
+func (s *ScoringService) adjust(gameID uint, req models.ScoreAdjustRequest) (*models.ScoreUpdate, *ValueChange, error) {
+	return s.change(gameID, req, func(current int) int { return current + req.Delta })
+}
+
+// set - Set zwracający także zapisaną zmianę (nil gdy wartość się nie zmieniła)
+func (s *ScoringService) set(gameID uint, req models.ScoreAdjustRequest) (*models.ScoreUpdate, *ValueChange, error) {
+	if req.Value == nil {
+		return nil, nil, fmt.Errorf("value jest wymagane")
+	}
+	return s.change(gameID, req, func(int) int { return *req.Value })
+}
+
+// change - wspólna logika zmiany wartości z kontrolą zakresu i przeliczeniem GameValue
+func (s *ScoringService) change(gameID uint, req models.ScoreAdjustRequest, apply func(int) int) (*models.ScoreUpdate, *ValueChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+	if req.TeamID == 0 || req.ValueTypeID == 0 {
+		return nil, nil, fmt.Errorf("team_id i value_type_id są wymagane")
+	}
+
+	partID, err := s.resolveGamePart(db, gameID, req.GamePartID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var partValue models.GamePartValue
+	err = db.Where("game_id = ? AND game_part_id = ? AND team_id = ? AND value_type_id = ?",
+		gameID, partID, req.TeamID, req.ValueTypeID).First(&partValue).Error
+	if err != nil {
+		partValue = models.GamePartValue{
+			GameID:      gameID,
+			GamePartID:  partID,
+			TeamID:      req.TeamID,
+			ValueTypeID: req.ValueTypeID,
+		}
+	}
+
+	previousValue := partValue.Value
+	newValue := apply(partValue.Value)
+	if err := checkBounds(&partValue, newValue); err != nil {
+		return nil, nil, err
+	}
+	partValue.Value = newValue
+
+	if err := db.Omit("Game", "GamePart", "Team", "ValueType").Save(&partValue).Error; err != nil {
+		return nil, nil, fmt.Errorf("błąd zapisywania wartości: %w", err)
+	}
+
+	if err := s.recomputeGameValue(db, gameID, req.TeamID, req.ValueTypeID); err != nil {
+		return nil, nil, err
+	}
+
+	log.Printf("ScoringService: Mecz ID=%d, część ID=%d, drużyna ID=%d, typ ID=%d: %d",
+		gameID, partID, req.TeamID, req.ValueTypeID, newValue)
+
+	update, err := s.broadcast(db, gameID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if previousValue == newValue {
+		return update, nil, nil
+	}
+
+	change := ValueChange{
+		GameID:      gameID,
+		GamePartID:  partID,
+		TeamID:      req.TeamID,
+		ValueTypeID: req.ValueTypeID,
+		Previous:    previousValue,
+		Current:     newValue,
+	}
+	for _, listener := range s.listeners {
+		listener(change)
+	}
+	return update, &change, nil
+}
+
+// checkBounds - sprawdza zakres wartości (bez MinValue wartość nie może być ujemna)
+func checkBounds(partValue *models.GamePartValue, value int) error {
+	minValue := 0
+	if partValue.MinValue != nil {
+		minValue = *partValue.MinValue
+	}
+	if value < minValue {
+		return fmt.Errorf("wartość %d poniżej minimum %d", value, minValue)
+	}
+	if partValue.MaxValue != nil && value > *partValue.MaxValue {
+		return fmt.Errorf("wartość %d powyżej maksimum %d", value, *partValue.MaxValue)
+	}
+	return nil
+}
+
+// resolveGamePart - część meczu z żądania lub aktywna część meczu
+func (s *ScoringService) resolveGamePart(db *gorm.DB, gameID uint, partID *uint) (uint, error) {
+	if partID == nil {
+		var session models.ActiveSession
+		if err := db.First(&session).Error; err != nil || session.GameID == nil || *session.GameID != gameID || session.GamePartID == nil {
+			return 0, fmt.Errorf("brak aktywnej części meczu - podaj game_part_id")
+		}
+		partID = session.GamePartID
+	}
+
+	var part models.GamePart
+	if err := db.Where("game_id = ?", gameID).First(&part, *partID).Error; err != nil {
+		return 0, fmt.Errorf("część meczu ID=%d nie należy do meczu ID=%d", *partID, gameID)
+	}
+	return part.ID, nil
+}
+
+// loadPartValues - wartości części meczu (z częścią meczu) w kolejności części
+func loadPartValues(db *gorm.DB, gameID uint) ([]models.GamePartValue, error) {
+	var rows []models.GamePartValue
+	if err := db.Preload("GamePart").Where("game_id = ?", gameID).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania wartości części meczu: %w", err)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].GamePart.MatchOrder < rows[j].GamePart.MatchOrder
+	})
+	return rows, nil
+}
+
+// recomputeGameValue - przelicza GameValue drużyny dla typu wartości z wartości części meczu
+func (s *ScoringService) recomputeGameValue(db *gorm.DB, gameID, teamID, valueTypeID uint) error {
+	rows, err := loadPartValues(db, gameID)
+	if err != nil {
+		return err
+	}
+
+	total, isDSQ := mainScore(rows, teamID, valueTypeID)
+
+	var gameValue models.GameValue
+	err = db.Where("game_id = ? AND team_id = ? AND value_type_id = ?", gameID, teamID, valueTypeID).First(&gameValue).Error
+	if err != nil {
+		gameValue = models.GameValue{
+			GameID:      gameID,
+			TeamID:      teamID,
+			ValueTypeID: valueTypeID,
+		}
+	}
+	gameValue.Value = total
+	gameValue.IsDSQ = isDSQ
+
+	if err := db.Omit("Game", "Team", "ValueType").Save(&gameValue).Error; err != nil {
+		return fmt.Errorf("błąd zapisywania wyniku: %w", err)
+	}
+	return nil
+}
+
+// mainScore - wynik główny drużyny dla typu wartości i informacja o walkowerze
+//
+// Wynik główny tworzą wartości części bez grupy wartości (np. połowy); części z grupą (np. rzuty
+// karne w grupie 2) są liczone osobno. Najniższa grupa jest wynikiem głównym tylko wtedy,
+// gdy wszystkie części meczu mają grupę.
+func mainScore(rows []models.GamePartValue, teamID, valueTypeID uint) (int, bool) {
+	ungrouped := false
+	var primary *int
+	for _, row := range rows {
+		if row.TeamID != teamID || row.ValueTypeID != valueTypeID {
+			continue
+		}
+		if row.GameValueGroup == nil {
+			ungrouped = true
+		} else if primary == nil || *row.GameValueGroup < *primary {
+			primary = row.GameValueGroup
+		}
+	}
+
+	total := 0
+	isDSQ := false
+	for _, row := range rows {
+		if row.TeamID != teamID || row.ValueTypeID != valueTypeID {
+			continue
+		}
+		if row.IsDSQ {
+			isDSQ = true
+		}
+		if ungrouped && row.GameValueGroup == nil || !ungrouped && *row.GameValueGroup == *primary {
+			total += row.Value
+		}
+	}
+	return total, isDSQ
+}
+
+// Recompute - przelicza wszystkie GameValue meczu i rozgłasza wynik
+func (s *ScoringService) Recompute(gameID uint) (*models.ScoreUpdate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	rows, err := loadPartValues(db, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ team, valueType uint }
+	seen := map[key]bool{}
+	for _, row := range rows {
+		k := key{row.TeamID, row.ValueTypeID}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		if err := s.recomputeGameValue(db, gameID, row.TeamID, row.ValueTypeID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.broadcast(db, gameID)
+}
+
+// GetScore - zwraca aktualny stan wyniku meczu
+func (s *ScoringService) GetScore(gameID uint) (*models.ScoreUpdate, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+	return buildScoreUpdate(db, gameID)
+}
+
+// broadcast - buduje stan wyniku i rozgłasza score_update
+func (s *ScoringService) broadcast(db *gorm.DB, gameID uint) (*models.ScoreUpdate, error) {
+	update, err := buildScoreUpdate(db, gameID)
+	if err != nil {
+		return nil, err
+	}
+	s.socketService.BroadcastToPanel("score_update", update)
+	return update, nil
+}
+
+// buildScoreUpdate - zbiera wartości meczu i sumy grup
+func buildScoreUpdate(db *gorm.DB, gameID uint) (*models.ScoreUpdate, error) {
+	update := &models.ScoreUpdate{
+		GameID:      gameID,
+		GameValues:  []models.GameValue{},
+		PartValues:  []models.GamePartValue{},
+		ValueGroups: []models.ScoreGroupTotal{},
+		TimeGroups:  []models.ScoreGroupTotal{},
+	}
+
+	if err := db.Preload("ValueType").Where("game_id = ?", gameID).
+		Order("team_id ASC, value_type_id ASC").Find(&update.GameValues).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania wyniku: %w", err)
+	}
+
+	rows, err := loadPartValues(db, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	valueGroups := map[models.ScoreGroupTotal]int{}
+	timeGroups := map[models.ScoreGroupTotal]int{}
+	for _, row := range rows {
+		update.PartValues = append(update.PartValues, row)
+		if row.GameValueGroup != nil {
+			valueGroups[models.ScoreGroupTotal{TeamID: row.TeamID, ValueTypeID: row.ValueTypeID, Group: uint(*row.GameValueGroup)}] += row.Value
+		}
+		if row.GamePart.TimeGroup != nil {
+			timeGroups[models.ScoreGroupTotal{TeamID: row.TeamID, ValueTypeID: row.ValueTypeID, Group: *row.GamePart.TimeGroup}] += row.Value
+		}
+	}
+	update.ValueGroups = groupTotals(valueGroups)
+	update.TimeGroups = groupTotals(timeGroups)
+
+	var session models.ActiveSession
+	if err := db.First(&session).Error; err == nil && session.GameID != nil && *session.GameID == gameID {
+		update.ActiveGamePartID = session.GamePartID
+	}
+
+	return update, nil
+}
+
+// groupTotals - zamienia mapę sum na posortowaną listę
+func groupTotals(totals map[models.ScoreGroupTotal]int) []models.ScoreGroupTotal {
+	result := make([]models.ScoreGroupTotal, 0, len(totals))
+	for k, v := range totals {
+		k.Value = v
+		result = append(result, k)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.TeamID != b.TeamID {
+			return a.TeamID < b.TeamID
+		}
+		if a.ValueTypeID != b.ValueTypeID {
+			return a.ValueTypeID < b.ValueTypeID
+		}
+		return a.Group < b.Group
+	})
+	return result
+}
+
+// getEventValueTypes - mapowanie typ wydarzenia -> typ wartości z Variable ("scoring.event_value_types")
+//
+// Bez konfiguracji wydarzenia o nazwie bramki (goalNames) zwiększają typ wartości o nazwie bramek.
+func (s *ScoringService) getEventValueTypes(db *gorm.DB) map[uint]uint {
+	mapping := map[uint]uint{}
+
+	var competition models.Competition
+	if err := db.First(&competition).Error; err == nil && competition.Variable != "" {
+		var variableData map[string]interface{}
+		if err := json.Unmarshal([]byte(competition.Variable), &variableData); err == nil {
+			if scoring, ok := variableData["scoring"].(map[string]interface{}); ok {
+				if eventValueTypes, ok := scoring["event_value_types"].(map[string]interface{}); ok {
+					for eventTypeID, valueTypeID := range eventValueTypes {
+						id, err := strconv.ParseUint(eventTypeID, 10, 32)
+						vt, ok := valueTypeID.(float64)
+						if err == nil && ok {
+							mapping[uint(id)] = uint(vt)
+						}
+					}
+					return mapping
+				}
+			}
+		}
+	}
+
+	var valueTypes []models.ValueType
+	db.Find(&valueTypes)
+	var goalValueType models.ValueType
+	for _, vt := range valueTypes {
+		if isGoalName(vt.Name) {
+			goalValueType = vt
+			break
+		}
+	}
+	if goalValueType.ID == 0 {
+		return mapping
+	}
+
+	var eventTypes []models.EventType
+	db.Find(&eventTypes)
+	names := []string{}
+	for _, et := range eventTypes {
+		if isGoalName(et.Name) {
+			mapping[et.ID] = goalValueType.ID
+			names = append(names, et.Name)
+		}
+	}
+	log.Printf("ScoringService: Brak \"scoring.event_value_types\" - wydarzenia %v zwiększają '%s' (ID=%d), rozpoznane po nazwie",
+		names, goalValueType.Name, goalValueType.ID)
+	return mapping
+}
+
+// goalNames - nazwy typów wartości i wydarzeń rozpoznawane jako bramki bez konfiguracji w Variable
+var goalNames = map[string]bool{"gol": true, "gole": true, "bramka": true, "bramki": true, "goal": true, "goals": true}
+
+// isGoalName - czy nazwa oznacza bramkę (dokładna nazwa z goalNames, bez rozróżniania wielkości liter)
+//
+// Nazwy takie jak "Gol samobójczy" czy "Bramka nieuznana" nie są bramkami - wymagają konfiguracji.
+func isGoalName(name string) bool {
+	return goalNames[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// HandleEvent - aktualizuje wynik po dodaniu, edycji lub usunięciu wydarzenia (np. bramki)
+func (s *ScoringService) HandleEvent(action string, event *models.Event, previous *models.Event) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return
+	}
+	mapping := s.getEventValueTypes(db)
+
+	apply := func(e *models.Event, delta int) {
+		valueTypeID, ok := mapping[e.EventTypeID]
+		if !ok || e.TeamID == nil {
+			return
+		}
+		partID := e.GamePartID
+		_, err := s.Adjust(e.GameID, models.ScoreAdjustRequest{
+			TeamID:      *e.TeamID,
+			ValueTypeID: valueTypeID,
+			GamePartID:  &partID,
+			Delta:       delta,
+		})
+		if err != nil {
+			log.Printf("ScoringService: Błąd aktualizacji wyniku z wydarzenia ID=%d: %v", e.ID, err)
+		}
+	}
+
+	switch action {
+	case "created":
+		apply(event, 1)
+	case "deleted":
+		apply(event, -1)
+	case "updated":
+		if previous != nil && sameScoringTarget(previous, event) {
+			return
+		}
+		if previous != nil {
+			apply(previous, -1)
+		}
+		apply(event, 1)
+	}
+}
+
+// sameScoringTarget - czy edycja nie zmienia typu, drużyny ani części meczu wydarzenia
+func sameScoringTarget(a, b *models.Event) bool {
+	if a.EventTypeID != b.EventTypeID || a.GamePartID != b.GamePartID {
+		return false
+	}
+	if a.TeamID == nil || b.TeamID == nil {
+		return a.TeamID == b.TeamID
+	}
+	return *a.TeamID == *b.TeamID
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"recorder-server/internal/models"
+	"testing"
+)
+
+// partValue - wartość części meczu (bramki drużyny) z opcjonalną grupą wartości
+func partValue(teamID uint, value int, group *int) models.GamePartValue {
+	return models.GamePartValue{TeamID: teamID, ValueTypeID: 1, Value: value, GameValueGroup: group}
+}
+
+func TestMainScoreRegulationTimeAndPenalties(t *testing.T) {
+	penalties := 2
+	// Połowy bez grupy, rzuty karne w grupie 2: 2:2 po czasie gry, 4:3 w rzutach karnych
+	rows := []models.GamePartValue{
+		partValue(1, 1, nil), partValue(2, 0, nil),
+		partValue(1, 1, nil), partValue(2, 2, nil),
+		partValue(1, 4, &penalties), partValue(2, 3, &penalties),
+	}
+	for teamID, want := range map[uint]int{1: 2, 2: 2} {
+		if got, _ := mainScore(rows, teamID, 1); got != want {
+			t.Errorf("drużyna ID=%d: wynik %d, oczekiwano %d (bez rzutów karnych)", teamID, got, want)
+		}
+	}
+}
+
+func TestMainScoreAllPartsGrouped(t *testing.T) {
+	regular, extra := 1, 2
+	rows := []models.GamePartValue{
+		partValue(1, 2, &regular), partValue(1, 1, &regular), partValue(1, 5, &extra),
+	}
+	rows[1].IsDSQ = true
+	total, isDSQ := mainScore(rows, 1, 1)
+	if total != 3 || !isDSQ {
+		t.Fatalf("wynik %d (walkower %v), oczekiwano 3 z najniższej grupy i walkowera", total, isDSQ)
+	}
+}
+
+func TestIsGoalNameExactNames(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Bramki": true, "Gol": true, " bramka ": true, "Goals": true,
+		"Gol samobójczy": false, "Bramka nieuznana": false, "Golkiper": false, "Strzały": false,
+	} {
+		if got := isGoalName(name); got != want {
+			t.Errorf("isGoalName(%q) = %v, oczekiwano %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"recorder-server/internal/state"
+	"recorder-server/internal/timer"
+	"time"
+)
+
+// obsStatusTimeout - maksymalny czas oczekiwania na odpowiedź OBS przy budowaniu snapshotu
+const obsStatusTimeout = time.Second
+
+// StateSnapshot - pełny stan aplikacji potrzebny klientowi do wyrenderowania panelu
+type StateSnapshot struct {
+	Recording     models.RecordStatus      `json:"recording"`
+	Cameras       []models.Camera          `json:"cameras"`
+	OBS           models.OBSStatusResponse `json:"obs"`
+	Timers        map[string]timer.State   `json:"timers"`
+	ActiveSession *models.ActiveSession    `json:"active_session"`
+	Score         *ScoreSnapshot           `json:"score"`
+	GeneratedAt   time.Time                `json:"generated_at"`
+}
+
+// ScoreSnapshot - aktualny wynik aktywnego meczu
+type ScoreSnapshot struct {
+	GameID uint               `json:"game_id"`
+	Teams  []models.GameTeam  `json:"teams"`
+	Values []models.GameValue `json:"values"`
+}
+
+// SnapshotService - serwis budujący snapshot stanu dla klientów Socket.IO
+type SnapshotService struct {
+	dbManager     *database.Manager
+	appState      *state.AppState
+	cameraService *CameraService
+	timerService  *TimerService
+	obsClient     *OBSClient
+}
+
+// NewSnapshotService - tworzy nowy serwis snapshotów stanu
+func NewSnapshotService(dbManager *database.Manager, appState *state.AppState, cameraService *CameraService,
+	timerService *TimerService, obsClient *OBSClient) *SnapshotService {
+	return &SnapshotService{
+		dbManager:     dbManager,
+		appState:      appState,
+		cameraService: cameraService,
+		timerService:  timerService,
+		obsClient:     obsClient,
+	}
+}
+
+// Build - buduje aktualny snapshot stanu (brakujące części są pomijane, a nie zgłaszane jako błąd)
+func (s *SnapshotService) Build() StateSnapshot {
+	snapshot := StateSnapshot{
+		Recording:   s.appState.GetStatus(),
+		Cameras:     []models.Camera{},
+		OBS:         s.obsStatus(),
+		Timers:      map[string]timer.State{"main": s.timerService.GetState()},
+		GeneratedAt: time.Now(),
+	}
+
+	if cameras, err := s.cameraService.ListCameras(); err == nil {
+		snapshot.Cameras = cameras
+	}
+
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return snapshot
+	}
+
+	var session models.ActiveSession
+	if err := db.Preload("Game").Preload("GamePart").First(&session).Error; err != nil {
+		return snapshot
+	}
+	snapshot.ActiveSession = &session
+
+	if session.GameID != nil {
+		score := &ScoreSnapshot{GameID: *session.GameID}
+		db.Preload("Team").Where("game_id = ?", *session.GameID).Order("side ASC").Find(&score.Teams)
+		db.Preload("ValueType").Where("game_id = ?", *session.GameID).Find(&score.Values)
+		snapshot.Score = score
+	}
+
+	return snapshot
+}
+
+// obsStatus - status OBS z limitem czasu (brak odpowiedzi nie blokuje snapshotu)
+func (s *SnapshotService) obsStatus() models.OBSStatusResponse {
+	status := models.OBSStatusResponse{Connected: s.obsClient.IsConnected()}
+	if !status.Connected {
+		return status
+	}
+
+	result := make(chan bool, 1)
+	go func() {
+		if recording, err := s.obsClient.GetRecordingStatus(); err == nil {
+			result <- recording
+		}
+	}()
+
+	select {
+	case recording := <-result:
+		status.Recording = recording
+	case <-time.After(obsStatusTimeout):
+	}
+	return status
+}
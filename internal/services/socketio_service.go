@@ -1,7 +1,10 @@
 package services
 
 import (
+	"errors"
 	"log"
+	"recorder-server/config"
+	"recorder-server/internal/auth"
 	"recorder-server/internal/models"
 	"recorder-server/internal/state"
 
@@ -10,17 +13,22 @@ import (
 
 // SocketIOService - serwis Socket.IO
 type SocketIOService struct {
-	server   *socketio.Server
-	appState *state.AppState
+	server           *socketio.Server
+	appState         *state.AppState
+	authenticator    *auth.Authenticator
+	recordingService *RecordingService
+	commandService   *RecorderCommandService
+	snapshotService  *SnapshotService
 }
 
 // NewSocketIOService - tworzy nowy serwis Socket.IO
-func NewSocketIOService(appState *state.AppState) *SocketIOService {
+func NewSocketIOService(appState *state.AppState, authenticator *auth.Authenticator) *SocketIOService {
 	server := socketio.NewServer(nil)
 
 	service := &SocketIOService{
-		server:   server,
-		appState: appState,
+		server:        server,
+		appState:      appState,
+		authenticator: authenticator,
 	}
 
 	service.setupHandlers()
@@ -30,8 +38,23 @@ func NewSocketIOService(appState *state.AppState) *SocketIOService {
 // setupHandlers - konfiguruje handlery Socket.IO
 func (s *SocketIOService) setupHandlers() {
 	s.server.OnConnect("/", func(conn socketio.Conn) error {
-		log.Printf("Socket.IO: Nowe połączenie: %s", conn.ID())
+		// Uwierzytelnianie tokenem (nagłówek, ciasteczko lub ?token=)
+		if s.authenticator.IsEnabled() {
+			url := conn.URL()
+			identity := s.authenticator.Authenticate(auth.TokenFromRequest(conn.RemoteHeader(), url.Query()))
+			if identity == nil {
+				log.Printf("Socket.IO: Odrzucono połączenie bez ważnego tokenu: %s", conn.ID())
+				return errors.New("unauthorized")
+			}
+			conn.SetContext(identity)
+			log.Printf("Socket.IO: Nowe połączenie: %s (%s, rola: %s)", conn.ID(), identity.Name, identity.Role)
+		} else {
+			log.Printf("Socket.IO: Nowe połączenie: %s", conn.ID())
+		}
 		conn.Join("room1") // Dołącz do pokoju
+
+		// Pełny stan od razu po połączeniu - klient nie czeka na kolejny broadcast
+		go s.emitSnapshot(conn)
 		return nil
 	})
 
@@ -45,11 +68,91 @@ func (s *SocketIOService) setupHandlers() {
 		log.Printf("Socket.IO: Wysłano status do klienta: %+v", status)
 	})
 
+	// Klient prosi o pełny stan (np. po ponownym wyrenderowaniu panelu)
+	s.server.OnEvent("/", "get_state_snapshot", func(conn socketio.Conn) {
+		s.emitSnapshot(conn)
+	})
+
+	// Rejestrator kamery zgłasza plik nagrania
+	s.server.OnEvent("/", "recording_file", func(conn socketio.Conn, report models.RecordingFileReport) {
+		if !s.hasRole(conn, config.RoleAdmin, config.RoleOperator, config.RoleRecorder) {
+			conn.Emit("recording_file_error", map[string]interface{}{"error": "forbidden"})
+			return
+		}
+		if s.recordingService == nil {
+			log.Println("Socket.IO: Brak serwisu nagrań - pominięto recording_file")
+			return
+		}
+		file, err := s.recordingService.AddFile(report)
+		if err != nil {
+			log.Printf("Socket.IO: Błąd zapisu pliku nagrania: %v", err)
+			conn.Emit("recording_file_error", map[string]interface{}{"error": err.Error()})
+			return
+		}
+		conn.Emit("recording_file_saved", file)
+	})
+
+	// Rejestrator kamery potwierdza wykonanie polecenia
+	s.server.OnEvent("/", "command_ack", func(conn socketio.Conn, data models.RecorderCommandAckData) {
+		if !s.hasRole(conn, config.RoleAdmin, config.RoleOperator, config.RoleRecorder) {
+			conn.Emit("command_ack_error", map[string]interface{}{"error": "forbidden"})
+			return
+		}
+		if s.commandService == nil {
+			log.Println("Socket.IO: Brak serwisu poleceń - pominięto command_ack")
+			return
+		}
+		if _, err := s.commandService.Ack(data); err != nil {
+			log.Printf("Socket.IO: Błąd zapisu potwierdzenia polecenia: %v", err)
+			conn.Emit("command_ack_error", map[string]interface{}{"error": err.Error()})
+		}
+	})
+
 	s.server.OnError("/", func(conn socketio.Conn, e error) {
 		log.Printf("Socket.IO: Błąd: %v", e)
 	})
 }
 
+// emitSnapshot - wysyła klientowi event state_snapshot
+func (s *SocketIOService) emitSnapshot(conn socketio.Conn) {
+	if s.snapshotService == nil {
+		return
+	}
+	conn.Emit("state_snapshot", s.snapshotService.Build())
+}
+
+// hasRole - sprawdza rolę połączenia (bez uwierzytelniania każde połączenie ma dostęp)
+func (s *SocketIOService) hasRole(conn socketio.Conn, roles ...config.Role) bool {
+	if !s.authenticator.IsEnabled() {
+		return true
+	}
+	identity, ok := conn.Context().(*auth.Identity)
+	if !ok || identity == nil {
+		return false
+	}
+	for _, role := range roles {
+		if identity.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRecordingService - ustawia serwis nagrań używany przez eventy rejestratorów
+func (s *SocketIOService) SetRecordingService(recordingService *RecordingService) {
+	s.recordingService = recordingService
+}
+
+// SetRecorderCommandService - ustawia serwis poleceń używany przez potwierdzenia rejestratorów
+func (s *SocketIOService) SetRecorderCommandService(commandService *RecorderCommandService) {
+	s.commandService = commandService
+}
+
+// SetSnapshotService - ustawia serwis budujący state_snapshot
+func (s *SocketIOService) SetSnapshotService(snapshotService *SnapshotService) {
+	s.snapshotService = snapshotService
+}
+
 // GetServer - zwraca serwer Socket.IO
 func (s *SocketIOService) GetServer() *socketio.Server {
 	return s.server
@@ -78,4 +181,16 @@ func (s *SocketIOService) BroadcastTimerUpdate(data interface{}) {
 	// Użyj BroadcastToNamespace zamiast BroadcastToRoom
 	s.server.BroadcastToNamespace("/", "timer_update", data)
 	log.Printf("Socket.IO: Broadcast timer_update: %+v", data)
-}
\ No newline at end of file
+}
+
+// BroadcastRecorderCommand - rozgłasza polecenie do rejestratorów kamer
+func (s *SocketIOService) BroadcastRecorderCommand(command string, data interface{}) {
+	s.server.BroadcastToRoom("/", "room1", command, data)
+	log.Printf("Socket.IO: Broadcast %s: %+v", command, data)
+}
+
+// BroadcastToPanel - rozgłasza powiadomienie do wszystkich klientów (panel operatora)
+func (s *SocketIOService) BroadcastToPanel(event string, data interface{}) {
+	s.server.BroadcastToNamespace("/", event, data)
+	log.Printf("Socket.IO: Broadcast %s: %+v", event, data)
+}
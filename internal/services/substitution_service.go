@@ -0,0 +1,386 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OperationSubstitution - rodzaj operacji dziennika dla wykonanego bloku zmian
+const OperationSubstitution = "substitution"
+
+// SubstitutionService - kolejkowanie i wykonywanie bloków zmian zawodników
+//
+// Skład na boisku wynika z pierwszego składu (GamePlayer.IsStarter) i wykonanych zmian.
+// W trybie "rolling" (zmiany hokejowe w futsalu) skład na boisku nie jest kontrolowany.
+type SubstitutionService struct {
+	dbManager     *database.Manager
+	timerService  *TimerService
+	socketService *SocketIOService
+	operationLog  *OperationLogService
+	mu            sync.Mutex // numerowanie, wykonywanie i anulowanie bloków po kolei
+}
+
+// NewSubstitutionService - tworzy nowy serwis zmian i rejestruje cofanie zmian w dzienniku operacji
+func NewSubstitutionService(dbManager *database.Manager, timerService *TimerService, socketService *SocketIOService, operationLog *OperationLogService) *SubstitutionService {
+	s := &SubstitutionService{
+		dbManager:     dbManager,
+		timerService:  timerService,
+		socketService: socketService,
+		operationLog:  operationLog,
+	}
+
+	operationLog.RegisterKind(OperationSubstitution, OperationHandler{
+		Undo: func(op models.GameOperation) error { return s.restoreBlock(op.Before) },
+		Redo: func(op models.GameOperation) error { return s.restoreBlock(op.After) },
+	})
+	return s
+}
+
+// getSubstitutionModeFromVariable - tryb zmian z Variable ("substitutions.mode")
+func getSubstitutionModeFromVariable(variableData map[string]interface{}) string {
+	if substitutions, ok := variableData["substitutions"].(map[string]interface{}); ok {
+		if mode, ok := substitutions["mode"].(string); ok && mode == models.SubstitutionModeRolling {
+			return models.SubstitutionModeRolling
+		}
+	}
+	return models.SubstitutionModeStandard
+}
+
+// mode - tryb zmian aktualnych rozgrywek
+func (s *SubstitutionService) mode(db *gorm.DB) string {
+	_, variableData, err := loadVariable(db)
+	if err != nil {
+		return models.SubstitutionModeStandard
+	}
+	return getSubstitutionModeFromVariable(variableData)
+}
+
+// ListBlocks - bloki zmian meczu (kolejka i wykonane)
+func (s *SubstitutionService) ListBlocks(gameID uint) ([]models.SubstitutionBlock, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var substitutions []models.Substitution
+	if err := db.Preload("PlayerIn").Preload("PlayerOut").Where("game_id = ?", gameID).
+		Order("substitution_block ASC, id ASC").Find(&substitutions).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania zmian: %w", err)
+	}
+
+	blocks := []models.SubstitutionBlock{}
+	for _, substitution := range substitutions {
+		if len(blocks) == 0 || blocks[len(blocks)-1].Block != substitution.SubstitutionBlock {
+			blocks = append(blocks, models.SubstitutionBlock{
+				GameID:        gameID,
+				TeamID:        substitution.TeamID,
+				Block:         substitution.SubstitutionBlock,
+				GamePartID:    substitution.GamePartID,
+				Time:          substitution.Time,
+				IsDone:        substitution.IsDone,
+				Substitutions: []models.Substitution{},
+			})
+		}
+		block := &blocks[len(blocks)-1]
+		block.Substitutions = append(block.Substitutions, substitution)
+	}
+	return blocks, nil
+}
+
+// getBlock - blok zmian meczu
+func (s *SubstitutionService) getBlock(gameID, block uint) (*models.SubstitutionBlock, error) {
+	blocks, err := s.ListBlocks(gameID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range blocks {
+		if blocks[i].Block == block {
+			return &blocks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("nie znaleziono bloku zmian %d", block)
+}
+
+// QueueBlock - dodaje blok zmian do kolejki (wykonanie następuje przez ExecuteBlock)
+func (s *SubstitutionService) QueueBlock(gameID uint, req models.SubstitutionRequest) (*models.SubstitutionBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var gameTeam models.GameTeam
+	if err := db.Where("game_id = ? AND team_id = ?", gameID, req.TeamID).First(&gameTeam).Error; err != nil {
+		return nil, fmt.Errorf("drużyna ID=%d nie gra w meczu ID=%d", req.TeamID, gameID)
+	}
+	if len(req.Pairs) == 0 {
+		return nil, fmt.Errorf("blok zmian musi zawierać co najmniej jedną zmianę")
+	}
+
+	squad, err := s.squad(db, gameID, req.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[uint]bool{}
+	for _, pair := range req.Pairs {
+		if pair.PlayerInID == pair.PlayerOutID {
+			return nil, fmt.Errorf("zawodnik ID=%d nie może zmienić sam siebie", pair.PlayerInID)
+		}
+		for _, playerID := range []uint{pair.PlayerInID, pair.PlayerOutID} {
+			if _, ok := squad[playerID]; !ok {
+				return nil, fmt.Errorf("zawodnik ID=%d nie jest w kadrze meczowej drużyny", playerID)
+			}
+			if seen[playerID] {
+				return nil, fmt.Errorf("zawodnik ID=%d występuje w bloku zmian więcej niż raz", playerID)
+			}
+			seen[playerID] = true
+		}
+	}
+
+	var gamePartID uint
+	if req.GamePartID != nil {
+		var part models.GamePart
+		if err := db.Where("game_id = ?", gameID).First(&part, *req.GamePartID).Error; err != nil {
+			return nil, fmt.Errorf("część meczu ID=%d nie należy do meczu ID=%d", *req.GamePartID, gameID)
+		}
+		gamePartID = part.ID
+	}
+
+	// Numer bloku i zapis w jednej transakcji (pod s.mu) - równoległe żądania nie dostaną tego samego numeru
+	var lastBlock uint
+	substitutions := make([]models.Substitution, 0, len(req.Pairs))
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Substitution{}).Where("game_id = ?", gameID).
+			Select("COALESCE(MAX(substitution_block), 0)").Scan(&lastBlock).Error; err != nil {
+			return err
+		}
+		for _, pair := range req.Pairs {
+			substitutions = append(substitutions, models.Substitution{
+				GameID:            gameID,
+				GamePartID:        gamePartID,
+				SubstitutionBlock: lastBlock + 1,
+				TeamID:            req.TeamID,
+				PlayerInID:        pair.PlayerInID,
+				PlayerOutID:       pair.PlayerOutID,
+			})
+		}
+		return tx.Omit(clause.Associations).Create(&substitutions).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("błąd zapisywania bloku zmian: %w", err)
+	}
+
+	log.Printf("SubstitutionService: Mecz ID=%d - zakolejkowano blok zmian %d (%d zmian)", gameID, lastBlock+1, len(substitutions))
+	return s.getBlock(gameID, lastBlock+1)
+}
+
+// ExecuteBlock - wykonuje blok zmian z bieżącym czasem stopera i rozgłasza substitution
+func (s *SubstitutionService) ExecuteBlock(gameID, block uint, actor Actor) (*models.SubstitutionBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	pending, err := s.getBlock(gameID, block)
+	if err != nil {
+		return nil, err
+	}
+	if pending.IsDone {
+		return nil, fmt.Errorf("blok zmian %d został już wykonany", block)
+	}
+
+	gamePartID := pending.GamePartID
+	if gamePartID == 0 {
+		var session models.ActiveSession
+		if err := db.First(&session).Error; err != nil || session.GameID == nil || *session.GameID != gameID || session.GamePartID == nil {
+			return nil, fmt.Errorf("brak aktywnej części meczu - podaj game_part_id przy kolejkowaniu")
+		}
+		gamePartID = *session.GamePartID
+	}
+
+	if s.mode(db) == models.SubstitutionModeStandard {
+		onPitch, err := s.onPitch(db, gameID, pending.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		for _, substitution := range pending.Substitutions {
+			if !onPitch[substitution.PlayerOutID] {
+				return nil, fmt.Errorf("zawodnik %s %s nie jest na boisku",
+					substitution.PlayerOut.FirstName, substitution.PlayerOut.LastName)
+			}
+			if onPitch[substitution.PlayerInID] {
+				return nil, fmt.Errorf("zawodnik %s %s jest już na boisku",
+					substitution.PlayerIn.FirstName, substitution.PlayerIn.LastName)
+			}
+			delete(onPitch, substitution.PlayerOutID)
+			onPitch[substitution.PlayerInID] = true
+		}
+	}
+
+	executed := *pending
+	executed.IsDone = true
+	executed.GamePartID = gamePartID
+	executed.Time = matchClockSeconds(s.timerService)
+	if err := s.applyBlock(db, executed); err != nil {
+		return nil, err
+	}
+
+	result, err := s.getBlock(gameID, block)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Zmiana (blok %d, %d zawodników) w %s", block, len(result.Substitutions), formatEventTime(result.Time))
+	if _, err := s.operationLog.Record(gameID, OperationSubstitution, summary, blockState(*pending), blockState(*result), actor); err != nil {
+		log.Printf("SubstitutionService: %v", err)
+	}
+
+	log.Printf("SubstitutionService: Mecz ID=%d - wykonano blok zmian %d", gameID, block)
+	s.socketService.BroadcastToPanel("substitution", result)
+	return result, nil
+}
+
+// CancelBlock - usuwa niewykonany blok zmian z kolejki
+func (s *SubstitutionService) CancelBlock(gameID, block uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	pending, err := s.getBlock(gameID, block)
+	if err != nil {
+		return err
+	}
+	if pending.IsDone {
+		return fmt.Errorf("blok zmian %d został już wykonany - użyj cofnięcia operacji", block)
+	}
+
+	if err := db.Where("game_id = ? AND substitution_block = ?", gameID, block).Delete(&models.Substitution{}).Error; err != nil {
+		return fmt.Errorf("błąd usuwania bloku zmian: %w", err)
+	}
+	log.Printf("SubstitutionService: Mecz ID=%d - anulowano blok zmian %d", gameID, block)
+	return nil
+}
+
+// OnPitch - ID zawodników drużyny na boisku (pierwszy skład + wykonane zmiany)
+func (s *SubstitutionService) OnPitch(gameID, teamID uint) ([]uint, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	onPitch, err := s.onPitch(db, gameID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	playerIDs := make([]uint, 0, len(onPitch))
+	for playerID := range onPitch {
+		playerIDs = append(playerIDs, playerID)
+	}
+	sort.Slice(playerIDs, func(i, j int) bool { return playerIDs[i] < playerIDs[j] })
+	return playerIDs, nil
+}
+
+// squad - kadra meczowa drużyny (ID zawodnika -> zawodnik w meczu)
+func (s *SubstitutionService) squad(db *gorm.DB, gameID, teamID uint) (map[uint]models.GamePlayer, error) {
+	var gamePlayers []models.GamePlayer
+	if err := db.Where("game_id = ? AND team_id = ?", gameID, teamID).Find(&gamePlayers).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania kadry meczowej: %w", err)
+	}
+	squad := map[uint]models.GamePlayer{}
+	for _, gamePlayer := range gamePlayers {
+		squad[gamePlayer.PlayerID] = gamePlayer
+	}
+	return squad, nil
+}
+
+// onPitch - zbiór zawodników drużyny na boisku po wykonanych zmianach
+func (s *SubstitutionService) onPitch(db *gorm.DB, gameID, teamID uint) (map[uint]bool, error) {
+	squad, err := s.squad(db, gameID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	onPitch := map[uint]bool{}
+	for playerID, gamePlayer := range squad {
+		if gamePlayer.IsStarter {
+			onPitch[playerID] = true
+		}
+	}
+
+	var done []models.Substitution
+	err = db.Select("substitutions.*").
+		Joins("LEFT JOIN game_parts ON game_parts.id = substitutions.game_part_id").
+		Where("substitutions.game_id = ? AND substitutions.team_id = ? AND substitutions.is_done = ?", gameID, teamID, true).
+		Order("game_parts.match_order ASC, substitutions.time ASC, substitutions.id ASC").
+		Find(&done).Error
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wykonanych zmian: %w", err)
+	}
+	for _, substitution := range done {
+		delete(onPitch, substitution.PlayerOutID)
+		onPitch[substitution.PlayerInID] = true
+	}
+	return onPitch, nil
+}
+
+// applyBlock - zapisuje stan wykonania, czas i część meczu dla wszystkich zmian bloku
+func (s *SubstitutionService) applyBlock(db *gorm.DB, block models.SubstitutionBlock) error {
+	err := db.Model(&models.Substitution{}).
+		Where("game_id = ? AND substitution_block = ?", block.GameID, block.Block).
+		Updates(map[string]interface{}{
+			"is_done":      block.IsDone,
+			"time":         block.Time,
+			"game_part_id": block.GamePartID,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania bloku zmian: %w", err)
+	}
+	return nil
+}
+
+// blockState - blok zmian bez relacji (stan zapisywany w dzienniku operacji)
+func blockState(block models.SubstitutionBlock) models.SubstitutionBlock {
+	state := block
+	state.Substitutions = nil
+	return state
+}
+
+// restoreBlock - przywraca stan bloku zmian z dziennika operacji (cofnij/ponów)
+func (s *SubstitutionService) restoreBlock(state string) error {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var block models.SubstitutionBlock
+	if err := json.Unmarshal([]byte(state), &block); err != nil {
+		return fmt.Errorf("nieprawidłowy stan bloku zmian: %w", err)
+	}
+	if err := s.applyBlock(db, block); err != nil {
+		return err
+	}
+
+	restored, err := s.getBlock(block.GameID, block.Block)
+	if err != nil {
+		return err
+	}
+	s.socketService.BroadcastToPanel("substitution", restored)
+	return nil
+}
@@ -7,19 +7,33 @@ import (
 	"recorder-server/internal/database"
 	"recorder-server/internal/models"
 	"recorder-server/internal/tables"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// projectionDelay - czas zbierania zmian wyniku przed przeliczeniem tabeli na żywo
+const projectionDelay = 300 * time.Millisecond
+
 // TableService - serwis obsługujący operacje na tabelach
 type TableService struct {
-	dbManager *database.Manager
-	registry  *tables.AlgorithmRegistry
+	dbManager     *database.Manager
+	socketService *SocketIOService
+	registry      *tables.AlgorithmRegistry
+
+	projectionMu      sync.Mutex
+	pendingProjection map[uint]bool // grupy czekające na przeliczenie tabeli na żywo
+	broadcastMu       sync.Mutex    // przeliczenia tabeli na żywo po kolei - rozgłoszenia w kolejności zmian
 }
 
 // NewTableService - tworzy nowy serwis tabel
-func NewTableService(dbManager *database.Manager) *TableService {
+func NewTableService(dbManager *database.Manager, socketService *SocketIOService) *TableService {
 	return &TableService{
-		dbManager: dbManager,
-		registry:  tables.GetAlgorithmRegistry(),
+		dbManager:         dbManager,
+		socketService:     socketService,
+		registry:          tables.GetAlgorithmRegistry(),
+		pendingProjection: map[uint]bool{},
 	}
 }
 
@@ -29,109 +43,743 @@ func (s *TableService) getAlgorithmNameFromVariable(variable string) (string, er
 	if err := json.Unmarshal([]byte(variable), &variableData); err != nil {
 		return "", fmt.Errorf("błąd parsowania Variable: %w", err)
 	}
-	
+
 	// Sprawdź czy istnieje pole table_order_algorithm
 	if algorithm, ok := variableData["table_order_algorithm"].(string); ok && algorithm != "" {
 		return algorithm, nil
 	}
-	
+
 	return "", fmt.Errorf("brak informacji o algorytmie sortowania w Variable")
 }
 
-// CalculateTableForGroup - oblicza tabelę dla danej grupy
-func (s *TableService) CalculateTableForGroup(groupID uint) (*tables.Table, error) {
+// getPointsRulesFromVariable - punktacja z Variable ("table_points": {"win", "draw", "loss", "walkover"}), domyślnie 3/1/0/0
+//
+// Bez "walkover" drużyna przegrywająca walkowerem dostaje punkty jak za porażkę.
+func getPointsRulesFromVariable(variable string) tables.PointsRules {
+	points := tables.DefaultPointsRules()
+
+	var variableData map[string]interface{}
+	if err := json.Unmarshal([]byte(variable), &variableData); err != nil {
+		return points
+	}
+	raw, ok := variableData["table_points"].(map[string]interface{})
+	if !ok {
+		return points
+	}
+	if value, ok := raw["win"].(float64); ok {
+		points.Win = int(value)
+	}
+	if value, ok := raw["draw"].(float64); ok {
+		points.Draw = int(value)
+	}
+	if value, ok := raw["loss"].(float64); ok {
+		points.Loss = int(value)
+	}
+	points.Walkover = points.Loss
+	if value, ok := raw["walkover"].(float64); ok {
+		points.Walkover = int(value)
+	}
+	return points
+}
+
+// configureAlgorithm - konfiguruje algorytm kryteriami z Variable (tylko algorytmy konfigurowalne)
+func configureAlgorithm(algorithm tables.TableOrderAlgorithm, variable string) (tables.TableOrderAlgorithm, error) {
+	configurable, ok := algorithm.(tables.ConfigurableTableAlgorithm)
+	if !ok {
+		return algorithm, nil
+	}
+
+	variableData := map[string]interface{}{}
+	if variable != "" {
+		if err := json.Unmarshal([]byte(variable), &variableData); err != nil {
+			return nil, fmt.Errorf("błąd parsowania Variable: %w", err)
+		}
+	}
+	configured, err := configurable.Configure(variableData)
+	if err != nil {
+		return nil, fmt.Errorf("błąd konfiguracji algorytmu '%s': %w", algorithm.GetName(), err)
+	}
+	return configured, nil
+}
+
+// goalsValueType - typ wartości bramek (Variable "table_goals_value_type_id" lub typ o nazwie bramek, np. "Bramki")
+func goalsValueType(db *gorm.DB, variable string) (uint, error) {
+	var variableData map[string]interface{}
+	if err := json.Unmarshal([]byte(variable), &variableData); err == nil {
+		if value, ok := variableData["table_goals_value_type_id"].(float64); ok && value > 0 {
+			return uint(value), nil
+		}
+	}
+
+	var valueTypes []models.ValueType
+	if err := db.Order("id ASC").Find(&valueTypes).Error; err != nil {
+		return 0, fmt.Errorf("błąd pobierania typów wartości: %w", err)
+	}
+	for _, valueType := range valueTypes {
+		if isGoalName(valueType.Name) {
+			log.Printf("TableService: Brak \"table_goals_value_type_id\" - użyto typu wartości '%s' (ID=%d), rozpoznanego po nazwie",
+				valueType.Name, valueType.ID)
+			return valueType.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("brak typu wartości bramek")
+}
+
+// loadGameResults - wyniki meczów grupy (strony z GameTeam, bramki z GameValue)
+//
+// finished = true - mecze zakończone, finished = false - mecze trwające z obecnym wynikiem.
+func loadGameResults(db *gorm.DB, groupID, goalsValueTypeID uint, finished bool) ([]tables.GameResult, error) {
+	var games []models.Game
+	if err := db.Where("group_id = ? AND is_finished = ?", groupID, finished).Find(&games).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania meczów: %w", err)
+	}
+	if len(games) == 0 {
+		return []tables.GameResult{}, nil
+	}
+
+	gameIDs := make([]uint, len(games))
+	for i, game := range games {
+		gameIDs[i] = game.ID
+	}
+
+	var gameTeams []models.GameTeam
+	if err := db.Where("game_id IN ?", gameIDs).Find(&gameTeams).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania drużyn meczów: %w", err)
+	}
+	var gameValues []models.GameValue
+	if err := db.Where("game_id IN ? AND value_type_id = ?", gameIDs, goalsValueTypeID).Find(&gameValues).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania wyników meczów: %w", err)
+	}
+
+	type side struct{ home, away uint }
+	sides := map[uint]*side{}
+	for _, gameTeam := range gameTeams {
+		if sides[gameTeam.GameID] == nil {
+			sides[gameTeam.GameID] = &side{}
+		}
+		switch gameTeam.Side {
+		case 1:
+			sides[gameTeam.GameID].home = gameTeam.TeamID
+		case 2:
+			sides[gameTeam.GameID].away = gameTeam.TeamID
+		}
+	}
+	goals := map[uint]map[uint]int{}
+	dsq := map[uint]map[uint]bool{}
+	for _, value := range gameValues {
+		if goals[value.GameID] == nil {
+			goals[value.GameID] = map[uint]int{}
+			dsq[value.GameID] = map[uint]bool{}
+		}
+		goals[value.GameID][value.TeamID] = value.Value
+		dsq[value.GameID][value.TeamID] = value.IsDSQ
+	}
+
+	cards, err := loadTeamCards(db, gameIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]tables.GameResult, 0, len(games))
+	for _, game := range games {
+		gameSides := sides[game.ID]
+		if gameSides == nil || gameSides.home == 0 || gameSides.away == 0 {
+			log.Printf("TableService: Mecz ID=%d pominięty - brak obu drużyn", game.ID)
+			continue
+		}
+		results = append(results, tables.GameResult{
+			GameID:     game.ID,
+			Round:      game.Round,
+			DateTime:   game.DateTime,
+			HomeTeamID: gameSides.home,
+			AwayTeamID: gameSides.away,
+			HomeGoals:  goals[game.ID][gameSides.home],
+			AwayGoals:  goals[game.ID][gameSides.away],
+
+			HomeDSQ:         dsq[game.ID][gameSides.home],
+			AwayDSQ:         dsq[game.ID][gameSides.away],
+			HomeYellowCards: cards[game.ID][gameSides.home].yellow,
+			HomeRedCards:    cards[game.ID][gameSides.home].red,
+			AwayYellowCards: cards[game.ID][gameSides.away].yellow,
+			AwayRedCards:    cards[game.ID][gameSides.away].red,
+		})
+	}
+	tables.SortResults(results)
+	return results, nil
+}
+
+// loadTeamCards - kartki drużyn w meczach (mecz -> drużyna -> kartki), typy kartek z reguł dyscyplinarnych
+func loadTeamCards(db *gorm.DB, gameIDs []uint) (map[uint]map[uint]gameCards, error) {
+	cards := map[uint]map[uint]gameCards{}
+	rules := loadDisciplineRules(db)
+	typeIDs := append(append([]uint{}, rules.YellowEventTypeIDs...), rules.RedEventTypeIDs...)
+	if len(typeIDs) == 0 {
+		return cards, nil
+	}
+
+	var events []models.Event
+	if err := db.Where("game_id IN ? AND event_type_id IN ? AND team_id IS NOT NULL", gameIDs, typeIDs).
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania kartek: %w", err)
+	}
+
+	red := map[uint]bool{}
+	for _, id := range rules.RedEventTypeIDs {
+		red[id] = true
+	}
+	for _, event := range events {
+		if cards[event.GameID] == nil {
+			cards[event.GameID] = map[uint]gameCards{}
+		}
+		teamCards := cards[event.GameID][*event.TeamID]
+		if red[event.EventTypeID] {
+			teamCards.red++
+		} else {
+			teamCards.yellow++
+		}
+		cards[event.GameID][*event.TeamID] = teamCards
+	}
+	return cards, nil
+}
+
+// groupTableData - grupa, algorytm, punktacja, drużyny i wyniki potrzebne do obliczenia tabeli
+type groupTableData struct {
+	group     models.Group
+	algorithm tables.TableOrderAlgorithm
+	points    tables.PointsRules
+	teams     []models.Team
+	results   []tables.GameResult
+	goalsID   uint // typ wartości bramek
+}
+
+// loadGroupTableData - wczytuje dane tabeli grupy
+func (s *TableService) loadGroupTableData(groupID uint) (*groupTableData, error) {
 	db := s.dbManager.GetDB()
-	
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
 	// Pobierz grupę z relacjami
 	var group models.Group
 	if err := db.Preload("Stage.Competition").Preload("GroupTeams.Team").First(&group, groupID).Error; err != nil {
 		return nil, fmt.Errorf("nie znaleziono group: %w", err)
 	}
-	
+
 	competition := group.Stage.Competition
-	
+
 	// Pobierz nazwę algorytmu z Variable
 	algorithmName, err := s.getAlgorithmNameFromVariable(competition.Variable)
 	if err != nil {
 		return nil, fmt.Errorf("brak przypisanego algorytmu sortowania dla group ID=%d: %w", groupID, err)
 	}
-	
-	log.Printf("TableService: Obliczanie tabeli dla grupy '%s' używając algorytmu '%s'",
-		group.Name, algorithmName)
-	
+
 	// Pobierz algorytm z registry
 	algorithm, err := s.registry.GetAlgorithm(algorithmName)
 	if err != nil {
 		return nil, fmt.Errorf("błąd pobierania algorytmu: %w", err)
 	}
-	
+	if algorithm, err = configureAlgorithm(algorithm, competition.Variable); err != nil {
+		return nil, err
+	}
+
 	// Pobierz drużyny w grupie
 	teams := make([]models.Team, 0, len(group.GroupTeams))
 	for _, gt := range group.GroupTeams {
 		teams = append(teams, gt.Team)
 	}
-	
-	// Pobierz mecze dla tej grupy
-	var games []models.Game
-	if err := db.Where("group_id = ?", groupID).Find(&games).Error; err != nil {
-		return nil, fmt.Errorf("błąd pobierania meczów: %w", err)
+
+	// Pobierz wyniki zakończonych meczów grupy
+	valueTypeID, err := goalsValueType(db, competition.Variable)
+	if err != nil {
+		return nil, err
+	}
+	results, err := loadGameResults(db, groupID, valueTypeID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &groupTableData{
+		group:     group,
+		algorithm: algorithm,
+		points:    getPointsRulesFromVariable(competition.Variable),
+		teams:     teams,
+		results:   results,
+		goalsID:   valueTypeID,
+	}, nil
+}
+
+// CalculateTableForGroup - oblicza tabelę dla danej grupy
+func (s *TableService) CalculateTableForGroup(groupID uint) (*tables.Table, error) {
+	table, _, err := s.calculateGroup(groupID)
+	return table, err
+}
+
+// calculateGroup - oblicza tabelę grupy ze strefami awansu i spadku; zwraca też dane wejściowe tabeli
+func (s *TableService) calculateGroup(groupID uint) (*tables.Table, *groupTableData, error) {
+	data, err := s.loadGroupTableData(groupID)
+	if err != nil {
+		return nil, nil, err
 	}
-	
+
+	log.Printf("TableService: Obliczanie tabeli dla grupy '%s' używając algorytmu '%s' (%d meczów)",
+		data.group.Name, data.algorithm.GetName(), len(data.results))
+
 	// Oblicz tabelę używając algorytmu
-	log.Printf("TableService: Uruchamianie algorytmu '%s'...", algorithm.GetName())
-	table, err := algorithm.CalculateTable(groupID, teams, games)
+	table, err := data.algorithm.CalculateTable(groupID, data.teams, data.results, data.points)
 	if err != nil {
-		return nil, fmt.Errorf("błąd obliczania tabeli: %w", err)
+		return nil, nil, fmt.Errorf("błąd obliczania tabeli: %w", err)
 	}
-	
+
 	// Dodaj nazwę grupy
-	table.GroupName = group.Name
-	
+	table.GroupName = data.group.Name
+
+	// Strefy awansu i spadku (zasady zapisane jako zwykły tekst są pomijane)
+	if rules, err := groupPromotionRules(data.group); err != nil {
+		log.Printf("TableService: Grupa ID=%d - pominięto zasady awansu: %v", groupID, err)
+	} else {
+		tables.ApplyZones(table, rules)
+	}
+
 	log.Printf("TableService: Obliczono tabelę z %d pozycjami", len(table.Standings))
-	return table, nil
+	return table, data, nil
 }
 
-// CalculateTableForStage - oblicza tabele dla wszystkich grup w stage
-func (s *TableService) CalculateTableForStage(stageID uint) ([]*tables.Table, error) {
+// GroupHistory - tabela grupy po każdej kolejce z ruchem pozycji (obliczana z zakończonych meczów)
+func (s *TableService) GroupHistory(groupID uint) (*tables.TableHistory, error) {
+	data, err := s.loadGroupTableData(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := tables.BuildHistory(data.algorithm, groupID, data.teams, data.results, data.points)
+	if err != nil {
+		return nil, fmt.Errorf("błąd obliczania historii tabeli: %w", err)
+	}
+
+	log.Printf("TableService: Historia tabeli grupy '%s' - %d kolejek", data.group.Name, len(history.Rounds))
+	return history, nil
+}
+
+// ProjectedTableForGroup - tabela grupy "na żywo": trwające mecze liczone z obecnym wynikiem
+func (s *TableService) ProjectedTableForGroup(groupID uint) (*tables.ProjectedTable, error) {
+	data, err := s.loadGroupTableData(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.projectGroup(data)
+}
+
+// projectGroup - oblicza tabelę na żywo ze strefami awansu i spadku
+func (s *TableService) projectGroup(data *groupTableData) (*tables.ProjectedTable, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	live, err := loadGameResults(db, data.group.ID, data.goalsID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	projected, err := tables.ProjectTable(data.algorithm, data.group.ID, data.teams, data.results, live, data.points)
+	if err != nil {
+		return nil, fmt.Errorf("błąd obliczania tabeli na żywo: %w", err)
+	}
+	projected.Table.GroupName = data.group.Name
+	if rules, err := groupPromotionRules(data.group); err == nil {
+		tables.ApplyZones(projected.Table, rules)
+	}
+
+	log.Printf("TableService: Tabela na żywo grupy '%s' - %d trwających meczów", data.group.Name, len(live))
+	return projected, nil
+}
+
+// HandleValueChange - po zmianie bramek w trwającym meczu rozgłasza tabelę na żywo jego grupy (table_projection)
+//
+// Wywoływana pod blokadą serwisu wyniku - sprawdza tylko typ wartości i stan meczu, a tabelę
+// przelicza w tle (scheduleProjection).
+func (s *TableService) HandleValueChange(change ValueChange) {
 	db := s.dbManager.GetDB()
-	
+	if db == nil {
+		return
+	}
+	if groupID, ok := projectionGroup(db, change); ok {
+		s.scheduleProjection(groupID)
+	}
+}
+
+// projectionGroup - grupa, której tabelę na żywo zmienia zmiana wartości (bramki w trwającym meczu)
+//
+// Mecz jest trwający (is_finished = false) od ustawienia go jako aktywnego (MarkGameInProgress).
+func projectionGroup(db *gorm.DB, change ValueChange) (uint, bool) {
+	competition, _, err := loadVariable(db)
+	if err != nil {
+		return 0, false
+	}
+	goalsID, err := goalsValueType(db, competition.Variable)
+	if err != nil || change.ValueTypeID != goalsID {
+		return 0, false
+	}
+
+	var game models.Game
+	if err := db.First(&game, change.GameID).Error; err != nil {
+		return 0, false
+	}
+	if game.IsFinished == nil || *game.IsFinished {
+		return 0, false
+	}
+	return game.GroupID, true
+}
+
+// scheduleProjection - przelicza i rozgłasza tabelę na żywo grupy w tle
+//
+// Zmiany z okna projectionDelay łączone są w jedno przeliczenie.
+func (s *TableService) scheduleProjection(groupID uint) {
+	s.projectionMu.Lock()
+	defer s.projectionMu.Unlock()
+	if s.pendingProjection[groupID] {
+		return
+	}
+	s.pendingProjection[groupID] = true
+
+	time.AfterFunc(projectionDelay, func() {
+		s.projectionMu.Lock()
+		delete(s.pendingProjection, groupID)
+		s.projectionMu.Unlock()
+		s.broadcastProjection(groupID)
+	})
+}
+
+// broadcastProjection - oblicza tabelę na żywo grupy i rozgłasza table_projection
+func (s *TableService) broadcastProjection(groupID uint) {
+	s.broadcastMu.Lock()
+	defer s.broadcastMu.Unlock()
+
+	projected, err := s.ProjectedTableForGroup(groupID)
+	if err != nil {
+		log.Printf("TableService: Błąd tabeli na żywo dla grupy ID=%d: %v", groupID, err)
+		return
+	}
+	s.socketService.BroadcastToPanel("table_projection", projected)
+}
+
+// loadRemainingFixtures - mecze grupy bez wyniku końcowego (nierozpoczęte i trwające)
+func loadRemainingFixtures(db *gorm.DB, groupID uint) ([]tables.Fixture, error) {
+	var games []models.Game
+	if err := db.Where("group_id = ? AND (is_finished IS NULL OR is_finished = ?)", groupID, false).
+		Order("round ASC, date_time ASC, id ASC").Find(&games).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania meczów: %w", err)
+	}
+	if len(games) == 0 {
+		return []tables.Fixture{}, nil
+	}
+
+	gameIDs := make([]uint, len(games))
+	for i, game := range games {
+		gameIDs[i] = game.ID
+	}
+	var gameTeams []models.GameTeam
+	if err := db.Where("game_id IN ?", gameIDs).Find(&gameTeams).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania drużyn meczów: %w", err)
+	}
+	home := map[uint]uint{}
+	away := map[uint]uint{}
+	for _, gameTeam := range gameTeams {
+		switch gameTeam.Side {
+		case 1:
+			home[gameTeam.GameID] = gameTeam.TeamID
+		case 2:
+			away[gameTeam.GameID] = gameTeam.TeamID
+		}
+	}
+
+	fixtures := make([]tables.Fixture, 0, len(games))
+	for _, game := range games {
+		if home[game.ID] == 0 || away[game.ID] == 0 {
+			log.Printf("TableService: Mecz ID=%d pominięty - brak obu drużyn", game.ID)
+			continue
+		}
+		fixtures = append(fixtures, tables.Fixture{
+			GameID:     game.ID,
+			Round:      game.Round,
+			DateTime:   game.DateTime,
+			HomeTeamID: home[game.ID],
+			AwayTeamID: away[game.ID],
+		})
+	}
+	return fixtures, nil
+}
+
+// TeamScenarios - możliwe miejsca drużyny po pozostałych meczach grupy i wyniki, które je gwarantują
+//
+// maxMargin > 1 uwzględnia różnice bramek do maxMargin (liczba scenariuszy rośnie bardzo szybko -
+// przydatne w ostatnich kolejkach).
+func (s *TableService) TeamScenarios(groupID, teamID uint, maxMargin int) (*tables.ScenarioReport, error) {
+	data, err := s.loadGroupTableData(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures, err := loadRemainingFixtures(s.dbManager.GetDB(), groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := tables.AnalyzeScenarios(data.algorithm, groupID, data.teams, data.results, fixtures, data.points,
+		teamID, tables.ScenarioOptions{MaxMargin: maxMargin})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("TableService: Scenariusze drużyny '%s' w grupie '%s' - %d meczów, miejsca %d-%d (%d tabel)",
+		report.TeamName, data.group.Name, len(fixtures), report.BestPosition, report.WorstPosition, report.Evaluated)
+	return report, nil
+}
+
+// stagePromotionRules - zasady awansu etapu (Stage.PromotionRules)
+func stagePromotionRules(stage models.Stage) (*tables.PromotionRules, error) {
+	return tables.ParsePromotionRules(stage.PromotionRules, nil)
+}
+
+// groupPromotionRules - zasady awansu etapu nadpisane zasadami grupy (Group.SpecificPromotionRules)
+func groupPromotionRules(group models.Group) (*tables.PromotionRules, error) {
+	rules, err := stagePromotionRules(group.Stage)
+	if err != nil {
+		return nil, err
+	}
+	return tables.ParsePromotionRules(group.SpecificPromotionRules, rules)
+}
+
+// stageTables - tabele wszystkich grup etapu wraz z wynikami, w kolejności grup
+//
+// Grupy, których tabeli nie udało się obliczyć, są pomijane i zwracane w failed.
+func (s *TableService) stageTables(stageID uint) (stage *models.Stage, groups []tables.GroupResults, failed []uint, err error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, nil, nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
 	// Pobierz stage z grupami
-	var stage models.Stage
-	if err := db.Preload("Groups").First(&stage, stageID).Error; err != nil {
-		return nil, fmt.Errorf("nie znaleziono stage: %w", err)
+	stage = &models.Stage{}
+	if err := db.Preload("Groups", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number_in_stage ASC, id ASC")
+	}).First(stage, stageID).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("nie znaleziono stage: %w", err)
 	}
-	
+
 	log.Printf("TableService: Obliczanie tabel dla stage '%s' (%d grup)",
 		stage.Name, len(stage.Groups))
-	
+
 	// Oblicz tabelę dla każdej grupy
-	result := make([]*tables.Table, 0, len(stage.Groups))
+	groups = make([]tables.GroupResults, 0, len(stage.Groups))
 	for _, group := range stage.Groups {
-		table, err := s.CalculateTableForGroup(group.ID)
+		table, data, err := s.calculateGroup(group.ID)
 		if err != nil {
 			log.Printf("TableService: Błąd dla grupy %d: %v", group.ID, err)
+			failed = append(failed, group.ID)
 			continue // Kontynuuj z następną grupą
 		}
-		result = append(result, table)
+		groups = append(groups, tables.GroupResults{Table: table, Games: data.results, Points: data.points})
+	}
+
+	// Najlepsze drużyny z danego miejsca we wszystkich grupach
+	rules, err := stagePromotionRules(*stage)
+	if err != nil {
+		log.Printf("TableService: Stage ID=%d - pominięto zasady awansu: %v", stageID, err)
+	} else {
+		tables.ApplyBestPlaced(groups, rules)
+	}
+	return stage, groups, failed, nil
+}
+
+// CalculateTableForStage - oblicza tabele dla wszystkich grup w stage
+func (s *TableService) CalculateTableForStage(stageID uint) ([]*tables.Table, error) {
+	_, groups, _, err := s.stageTables(stageID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*tables.Table, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group.Table)
 	}
-	
+
 	log.Printf("TableService: Obliczono %d tabel", len(result))
 	return result, nil
 }
 
+// CrossGroupRanking - ranking drużyn z danego miejsca we wszystkich grupach etapu
+func (s *TableService) CrossGroupRanking(stageID uint, position int) ([]tables.CrossGroupEntry, error) {
+	_, groups, _, err := s.stageTables(stageID)
+	if err != nil {
+		return nil, err
+	}
+	ranking := tables.RankAcrossGroups(groups, position)
+
+	// Strefy z reguł najlepszych drużyn (ApplyBestPlaced) zapisane w tabelach grup
+	for i := range ranking {
+		for _, group := range groups {
+			if group.Table.GroupID == ranking[i].GroupID {
+				ranking[i].Zone = group.Table.Standings[position-1].Zone
+			}
+		}
+	}
+	return ranking, nil
+}
+
+// StageAdvancement - drużyna przypisana do grupy następnego etapu
+type StageAdvancement struct {
+	TeamID      uint   `json:"team_id"`
+	TeamName    string `json:"team_name"`
+	FromGroupID uint   `json:"from_group_id"`
+	Position    int    `json:"position"`
+	Zone        string `json:"zone"`
+	ToGroupID   uint   `json:"to_group_id"`
+	Created     bool   `json:"created"` // false - drużyna była już w grupie następnego etapu
+}
+
+// AdvanceStage - po zakończeniu etapu przypisuje awansujące drużyny do grup następnego etapu
+//
+// Kolejność: miejsce w grupie, a w obrębie miejsca ranking między grupami. Przy kilku grupach
+// następnego etapu drużyny rozdzielane są "wężykiem" (1, 2, ..., n, n, ..., 1).
+func (s *TableService) AdvanceStage(stageID uint) ([]StageAdvancement, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	stage, groups, failed, err := s.stageTables(stageID)
+	if err != nil {
+		return nil, err
+	}
+	// Bez tabeli choćby jednej grupy jej drużyny zniknęłyby z następnego etapu
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("nie udało się obliczyć tabel grup %v - awans przerwany", failed)
+	}
+	rules, err := stagePromotionRules(*stage)
+	if err != nil {
+		return nil, err
+	}
+	if rules.NextStageID == 0 {
+		return nil, fmt.Errorf("zasady awansu etapu ID=%d nie wskazują następnego etapu (next_stage_id)", stageID)
+	}
+
+	// Strefy awansujące z zasad grupy (Group.SpecificPromotionRules nadpisuje zasady etapu)
+	groupRules := make(map[uint]*tables.PromotionRules, len(stage.Groups))
+	for _, group := range stage.Groups {
+		group.Stage = *stage
+		specific, err := groupPromotionRules(group)
+		if err != nil {
+			return nil, fmt.Errorf("grupa ID=%d: %w", group.ID, err)
+		}
+		groupRules[group.ID] = specific
+	}
+
+	// Etap musi być zakończony
+	groupIDs := make([]uint, len(stage.Groups))
+	for i, group := range stage.Groups {
+		groupIDs[i] = group.ID
+	}
+	var total, unfinished int64
+	db.Model(&models.Game{}).Where("group_id IN ?", groupIDs).Count(&total)
+	db.Model(&models.Game{}).Where("group_id IN ? AND (is_finished IS NULL OR is_finished = ?)", groupIDs, false).Count(&unfinished)
+	if total == 0 || unfinished > 0 {
+		return nil, fmt.Errorf("etap ID=%d nie jest zakończony (%d z %d meczów nierozegranych)", stageID, unfinished, total)
+	}
+
+	var nextGroups []models.Group
+	if err := db.Where("stage_id = ?", rules.NextStageID).Order("number_in_stage ASC, id ASC").Find(&nextGroups).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania grup następnego etapu: %w", err)
+	}
+	if len(nextGroups) == 0 {
+		return nil, fmt.Errorf("następny etap ID=%d nie ma grup", rules.NextStageID)
+	}
+	nextGroupIDs := make([]uint, len(nextGroups))
+	for i, group := range nextGroups {
+		nextGroupIDs[i] = group.ID
+	}
+	var existing []models.GroupTeam
+	db.Where("group_id IN ?", nextGroupIDs).Find(&existing)
+	assigned := map[uint]uint{}
+	for _, groupTeam := range existing {
+		assigned[groupTeam.TeamID] = groupTeam.GroupID
+	}
+
+	// Awansujące drużyny: miejsce w grupie, potem ranking między grupami
+	maxPosition := 0
+	for _, group := range groups {
+		if len(group.Table.Standings) > maxPosition {
+			maxPosition = len(group.Table.Standings)
+		}
+	}
+	advancing := []StageAdvancement{}
+	for position := 1; position <= maxPosition; position++ {
+		for _, entry := range tables.RankAcrossGroups(groups, position) {
+			var standing *tables.TeamStanding
+			for _, group := range groups {
+				if group.Table.GroupID == entry.GroupID {
+					standing = &group.Table.Standings[position-1]
+				}
+			}
+			if standing == nil || !groupRules[entry.GroupID].Advances(standing.Zone) {
+				continue
+			}
+			advancement := StageAdvancement{
+				TeamID:      standing.TeamID,
+				FromGroupID: entry.GroupID,
+				Position:    position,
+				Zone:        standing.Zone,
+			}
+			if standing.Team != nil {
+				advancement.TeamName = standing.Team.Name
+			}
+			advancing = append(advancing, advancement)
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for i := range advancing {
+			if groupID, ok := assigned[advancing[i].TeamID]; ok {
+				advancing[i].ToGroupID = groupID
+				continue
+			}
+			round, index := i/len(nextGroups), i%len(nextGroups)
+			if round%2 == 1 {
+				index = len(nextGroups) - 1 - index
+			}
+			groupTeam := models.GroupTeam{GroupID: nextGroups[index].ID, TeamID: advancing[i].TeamID}
+			if err := tx.Create(&groupTeam).Error; err != nil {
+				return err
+			}
+			advancing[i].ToGroupID = groupTeam.GroupID
+			advancing[i].Created = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("błąd zapisu drużyn następnego etapu: %w", err)
+	}
+
+	log.Printf("TableService: Etap ID=%d - %d drużyn awansowało do etapu ID=%d", stageID, len(advancing), rules.NextStageID)
+	return advancing, nil
+}
+
 // CalculateTableForCompetition - oblicza tabele dla wszystkich grup w competition
 func (s *TableService) CalculateTableForCompetition(competitionID uint) ([]*tables.Table, error) {
 	db := s.dbManager.GetDB()
-	
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
 	// Pobierz competition z stages
 	var competition models.Competition
 	if err := db.Preload("Stages").First(&competition, competitionID).Error; err != nil {
 		return nil, fmt.Errorf("nie znaleziono competition: %w", err)
 	}
-	
+
 	log.Printf("TableService: Obliczanie tabel dla competition '%s' (%d stages)",
 		competition.Name, len(competition.Stages))
-	
+
 	// Oblicz tabele dla każdego stage
 	allTables := make([]*tables.Table, 0)
 	for _, stage := range competition.Stages {
@@ -142,7 +790,7 @@ func (s *TableService) CalculateTableForCompetition(competitionID uint) ([]*tabl
 		}
 		allTables = append(allTables, stageTables...)
 	}
-	
+
 	log.Printf("TableService: Obliczono łącznie %d tabel", len(allTables))
 	return allTables, nil
 }
@@ -152,35 +800,15 @@ func (s *TableService) GetAvailableAlgorithms() []string {
 	return s.registry.ListAlgorithms()
 }
 
-// CompareTeamsInGroup - porównuje dwie drużyny w kontekście grupy (dla celów debugowania)
-func (s *TableService) CompareTeamsInGroup(groupID uint, team1ID, team2ID uint) (int, error) {
-	db := s.dbManager.GetDB()
-	
-	// Pobierz grupę z competition
-	var group models.Group
-	if err := db.Preload("Stage.Competition").First(&group, groupID).Error; err != nil {
-		return 0, fmt.Errorf("nie znaleziono group: %w", err)
-	}
-	
-	competition := group.Stage.Competition
-	
-	// Pobierz nazwę algorytmu z Variable
-	algorithmName, err := s.getAlgorithmNameFromVariable(competition.Variable)
-	if err != nil {
-		return 0, fmt.Errorf("brak przypisanego algorytmu: %w", err)
-	}
-	
-	// Pobierz algorytm
-	algorithm, err := s.registry.GetAlgorithm(algorithmName)
+// CompareTeamsInGroup - porównuje dwie drużyny w tabeli grupy krok po kroku według kryteriów algorytmu
+func (s *TableService) CompareTeamsInGroup(groupID uint, team1ID, team2ID uint) (*tables.TeamComparison, error) {
+	data, err := s.loadGroupTableData(groupID)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	
-	// TODO: Pobierz statystyki drużyn i mecze bezpośrednie
-	// Dla uproszczenia zwracamy 0
-	
+
 	log.Printf("TableService: Porównanie drużyn %d i %d używając algorytmu '%s'",
-		team1ID, team2ID, algorithm.GetName())
-	
-	return 0, nil
-}
\ No newline at end of file
+		team1ID, team2ID, data.algorithm.GetName())
+
+	return data.algorithm.CompareInTable(groupID, data.teams, data.results, data.points, team1ID, team2ID)
+}
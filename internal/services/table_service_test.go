@@ -0,0 +1,62 @@
+package services
+
+import (
+	"recorder-server/internal/models"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB - baza w pamięci z rozgrywkami, typami wartości "Bramki" (ID=1) i "Faule" (ID=2)
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Competition{}, &models.ValueType{}, &models.Game{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&models.Competition{Name: "Liga"})
+	db.Create(&models.ValueType{Name: "Bramki"})
+	db.Create(&models.ValueType{Name: "Faule"})
+	return db
+}
+
+func TestProjectionTriggeredAfterGameBecomesActive(t *testing.T) {
+	db := testDB(t)
+	game := models.Game{GroupID: 3}
+	db.Create(&game)
+	goal := ValueChange{GameID: game.ID, ValueTypeID: 1}
+
+	if _, ok := projectionGroup(db, goal); ok {
+		t.Fatal("tabela na żywo dla nierozpoczętego meczu")
+	}
+
+	if err := MarkGameInProgress(db, game.ID); err != nil {
+		t.Fatal(err)
+	}
+	if groupID, ok := projectionGroup(db, goal); !ok || groupID != 3 {
+		t.Fatalf("bramka w trwającym meczu: grupa %d (%v), oczekiwano 3", groupID, ok)
+	}
+	if _, ok := projectionGroup(db, ValueChange{GameID: game.ID, ValueTypeID: 2}); ok {
+		t.Fatal("tabela na żywo po zmianie fauli")
+	}
+}
+
+func TestMarkGameInProgressKeepsFinishedGame(t *testing.T) {
+	db := testDB(t)
+	finished := true
+	game := models.Game{GroupID: 3, IsFinished: &finished}
+	db.Create(&game)
+
+	if err := MarkGameInProgress(db, game.ID); err != nil {
+		t.Fatal(err)
+	}
+	db.First(&game, game.ID)
+	if game.IsFinished == nil || !*game.IsFinished {
+		t.Fatalf("zakończony mecz zmienił stan na %v", game.IsFinished)
+	}
+}
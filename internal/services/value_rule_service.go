@@ -0,0 +1,391 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"recorder-server/internal/database"
+	"recorder-server/internal/models"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// ValueRuleService - reguły wartości akumulowanych (np. faule akumulowane w futsalu)
+//
+// Reguły są zapisane w Variable rozgrywek pod kluczem "value_rules":
+//
+//	"value_rules": {"2": {"label": "Faule akumulowane", "reset_per": "time_group", "thresholds": [5]}}
+//
+// Wartość akumulowana to suma wartości części meczu w okresie (grupie czasowej, części lub całym meczu).
+// Przekroczenie progu w górę emituje threshold_reached.
+type ValueRuleService struct {
+	dbManager     *database.Manager
+	socketService *SocketIOService
+}
+
+// NewValueRuleService - tworzy nowy serwis reguł wartości
+func NewValueRuleService(dbManager *database.Manager, socketService *SocketIOService) *ValueRuleService {
+	return &ValueRuleService{
+		dbManager:     dbManager,
+		socketService: socketService,
+	}
+}
+
+// periodKey - identyfikator okresu akumulacji
+type periodKey struct {
+	timeGroup uint
+	partID    uint
+}
+
+// loadVariable - Variable rozgrywek jako mapa
+func loadVariable(db *gorm.DB) (*models.Competition, map[string]interface{}, error) {
+	var competition models.Competition
+	if err := db.First(&competition).Error; err != nil {
+		return nil, nil, fmt.Errorf("nie znaleziono rozgrywek: %w", err)
+	}
+
+	variableData := map[string]interface{}{}
+	if competition.Variable != "" {
+		if err := json.Unmarshal([]byte(competition.Variable), &variableData); err != nil {
+			return nil, nil, fmt.Errorf("błąd parsowania Variable: %w", err)
+		}
+	}
+	return &competition, variableData, nil
+}
+
+// getRulesFromVariable - reguły wartości z Variable (klucz = ID typu wartości)
+func getRulesFromVariable(variableData map[string]interface{}) map[uint]models.ValueRule {
+	rules := map[uint]models.ValueRule{}
+
+	raw, ok := variableData["value_rules"].(map[string]interface{})
+	if !ok {
+		return rules
+	}
+
+	for key, value := range raw {
+		valueTypeID, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		var rule models.ValueRule
+		if err := json.Unmarshal(data, &rule); err != nil {
+			log.Printf("ValueRuleService: Nieprawidłowa reguła dla typu wartości %s: %v", key, err)
+			continue
+		}
+		rule.ValueTypeID = uint(valueTypeID)
+		if rule.ResetPer == "" {
+			rule.ResetPer = models.ValueResetPerTimeGroup
+		}
+		sort.Ints(rule.Thresholds)
+		rules[rule.ValueTypeID] = rule
+	}
+	return rules
+}
+
+// loadRules - reguły wartości aktualnych rozgrywek
+func (s *ValueRuleService) loadRules(db *gorm.DB) (map[uint]models.ValueRule, error) {
+	_, variableData, err := loadVariable(db)
+	if err != nil {
+		return nil, err
+	}
+	return getRulesFromVariable(variableData), nil
+}
+
+// ListRules - reguły wartości posortowane wg typu wartości
+func (s *ValueRuleService) ListRules() ([]models.ValueRule, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	rules, err := s.loadRules(db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.ValueRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, rule)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ValueTypeID < result[j].ValueTypeID })
+	return result, nil
+}
+
+// GetRule - reguła dla typu wartości
+func (s *ValueRuleService) GetRule(valueTypeID uint) (*models.ValueRule, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	rules, err := s.loadRules(db)
+	if err != nil {
+		return nil, err
+	}
+	rule, ok := rules[valueTypeID]
+	if !ok {
+		return nil, fmt.Errorf("brak reguły dla typu wartości ID=%d", valueTypeID)
+	}
+	return &rule, nil
+}
+
+// SetRule - zapisuje regułę typu wartości w Variable rozgrywek
+func (s *ValueRuleService) SetRule(rule models.ValueRule) (*models.ValueRule, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	var valueType models.ValueType
+	if err := db.First(&valueType, rule.ValueTypeID).Error; err != nil {
+		return nil, fmt.Errorf("nie znaleziono typu wartości ID=%d", rule.ValueTypeID)
+	}
+
+	if rule.ResetPer == "" {
+		rule.ResetPer = models.ValueResetPerTimeGroup
+	}
+	switch rule.ResetPer {
+	case models.ValueResetPerTimeGroup, models.ValueResetPerGamePart, models.ValueResetPerGame:
+	default:
+		return nil, fmt.Errorf("nieznany sposób zerowania: %s", rule.ResetPer)
+	}
+	for _, threshold := range rule.Thresholds {
+		if threshold <= 0 {
+			return nil, fmt.Errorf("progi muszą być dodatnie")
+		}
+	}
+	sort.Ints(rule.Thresholds)
+	if rule.Label == "" {
+		rule.Label = valueType.Name
+	}
+
+	err := s.updateRules(db, func(raw map[string]interface{}) {
+		raw[strconv.FormatUint(uint64(rule.ValueTypeID), 10)] = map[string]interface{}{
+			"label":      rule.Label,
+			"reset_per":  rule.ResetPer,
+			"thresholds": rule.Thresholds,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("ValueRuleService: Zapisano regułę dla typu wartości ID=%d (%s, progi %v)",
+		rule.ValueTypeID, rule.ResetPer, rule.Thresholds)
+	return &rule, nil
+}
+
+// DeleteRule - usuwa regułę typu wartości z Variable rozgrywek
+func (s *ValueRuleService) DeleteRule(valueTypeID uint) error {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	return s.updateRules(db, func(raw map[string]interface{}) {
+		delete(raw, strconv.FormatUint(uint64(valueTypeID), 10))
+	})
+}
+
+// updateRules - modyfikuje klucz value_rules w Variable i zapisuje rozgrywki
+func (s *ValueRuleService) updateRules(db *gorm.DB, modify func(raw map[string]interface{})) error {
+	competition, variableData, err := loadVariable(db)
+	if err != nil {
+		return err
+	}
+
+	raw, ok := variableData["value_rules"].(map[string]interface{})
+	if !ok {
+		raw = map[string]interface{}{}
+	}
+	modify(raw)
+	variableData["value_rules"] = raw
+
+	variableJSON, err := json.Marshal(variableData)
+	if err != nil {
+		return fmt.Errorf("błąd serializacji Variable: %w", err)
+	}
+	if err := db.Model(competition).Update("variable", string(variableJSON)).Error; err != nil {
+		return fmt.Errorf("błąd zapisywania Variable: %w", err)
+	}
+	return nil
+}
+
+// periodOf - okres akumulacji części meczu wg reguły
+func periodOf(rule models.ValueRule, part models.GamePart) periodKey {
+	switch rule.ResetPer {
+	case models.ValueResetPerGame:
+		return periodKey{}
+	case models.ValueResetPerGamePart:
+		return periodKey{partID: part.ID}
+	}
+	if part.TimeGroup != nil {
+		return periodKey{timeGroup: *part.TimeGroup}
+	}
+	return periodKey{partID: part.ID}
+}
+
+// GetAccumulated - wartości akumulowane drużyn we wszystkich okresach meczu (dla typów z regułą)
+func (s *ValueRuleService) GetAccumulated(gameID uint) ([]models.AccumulatedValue, error) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("brak aktywnego połączenia z bazą danych")
+	}
+
+	rules, err := s.loadRules(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []models.GamePart
+	if err := db.Where("game_id = ?", gameID).Order("match_order ASC").Find(&parts).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania części meczu: %w", err)
+	}
+
+	var gameTeams []models.GameTeam
+	if err := db.Where("game_id = ?", gameID).Order("side ASC").Find(&gameTeams).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania drużyn meczu: %w", err)
+	}
+
+	var partValues []models.GamePartValue
+	if err := db.Where("game_id = ?", gameID).Find(&partValues).Error; err != nil {
+		return nil, fmt.Errorf("błąd pobierania wartości części meczu: %w", err)
+	}
+
+	var activePartID uint
+	var session models.ActiveSession
+	if err := db.First(&session).Error; err == nil && session.GameID != nil && *session.GameID == gameID && session.GamePartID != nil {
+		activePartID = *session.GamePartID
+	}
+
+	valueTypeIDs := make([]uint, 0, len(rules))
+	for valueTypeID := range rules {
+		valueTypeIDs = append(valueTypeIDs, valueTypeID)
+	}
+	sort.Slice(valueTypeIDs, func(i, j int) bool { return valueTypeIDs[i] < valueTypeIDs[j] })
+
+	result := []models.AccumulatedValue{}
+	for _, valueTypeID := range valueTypeIDs {
+		rule := rules[valueTypeID]
+
+		// Okresy w kolejności pierwszej części meczu
+		var periods []periodKey
+		periodParts := map[periodKey][]models.GamePart{}
+		for _, part := range parts {
+			key := periodOf(rule, part)
+			if _, ok := periodParts[key]; !ok {
+				periods = append(periods, key)
+			}
+			periodParts[key] = append(periodParts[key], part)
+		}
+
+		for _, gameTeam := range gameTeams {
+			for _, key := range periods {
+				accumulated := models.AccumulatedValue{
+					TeamID:      gameTeam.TeamID,
+					ValueTypeID: valueTypeID,
+					Label:       rule.Label,
+					GamePartIDs: []uint{},
+					Reached:     []int{},
+				}
+				if key.timeGroup != 0 {
+					timeGroup := key.timeGroup
+					accumulated.TimeGroup = &timeGroup
+				}
+				for _, part := range periodParts[key] {
+					accumulated.GamePartIDs = append(accumulated.GamePartIDs, part.ID)
+					if part.ID == activePartID {
+						accumulated.IsActive = true
+					}
+					for _, pv := range partValues {
+						if pv.GamePartID == part.ID && pv.TeamID == gameTeam.TeamID && pv.ValueTypeID == valueTypeID {
+							accumulated.Value += pv.Value
+						}
+					}
+				}
+				for _, threshold := range rule.Thresholds {
+					if accumulated.Value >= threshold {
+						accumulated.Reached = append(accumulated.Reached, threshold)
+					} else if accumulated.NextThreshold == nil {
+						next := threshold
+						accumulated.NextThreshold = &next
+					}
+				}
+				result = append(result, accumulated)
+			}
+		}
+	}
+	return result, nil
+}
+
+// HandleValueChange - sprawdza progi po zmianie wartości i emituje threshold_reached
+func (s *ValueRuleService) HandleValueChange(change ValueChange) {
+	db := s.dbManager.GetDB()
+	if db == nil {
+		return
+	}
+
+	rules, err := s.loadRules(db)
+	if err != nil {
+		return
+	}
+	rule, ok := rules[change.ValueTypeID]
+	if !ok || len(rule.Thresholds) == 0 || change.Current <= change.Previous {
+		return
+	}
+
+	var parts []models.GamePart
+	if err := db.Where("game_id = ?", change.GameID).Find(&parts).Error; err != nil {
+		return
+	}
+
+	var changedPart *models.GamePart
+	for i := range parts {
+		if parts[i].ID == change.GamePartID {
+			changedPart = &parts[i]
+			break
+		}
+	}
+	if changedPart == nil {
+		return
+	}
+
+	period := periodOf(rule, *changedPart)
+	var partIDs []uint
+	for _, part := range parts {
+		if periodOf(rule, part) == period {
+			partIDs = append(partIDs, part.ID)
+		}
+	}
+
+	var current int64
+	db.Model(&models.GamePartValue{}).
+		Where("game_part_id IN ? AND team_id = ? AND value_type_id = ?", partIDs, change.TeamID, change.ValueTypeID).
+		Select("COALESCE(SUM(value), 0)").Scan(&current)
+	previous := int(current) - (change.Current - change.Previous)
+
+	for _, threshold := range rule.Thresholds {
+		if previous >= threshold || int(current) < threshold {
+			continue
+		}
+		payload := models.ThresholdReached{
+			GameID:      change.GameID,
+			GamePartID:  change.GamePartID,
+			TeamID:      change.TeamID,
+			ValueTypeID: change.ValueTypeID,
+			Label:       rule.Label,
+			TimeGroup:   changedPart.TimeGroup,
+			Threshold:   threshold,
+			Value:       int(current),
+		}
+		log.Printf("ValueRuleService: Próg %d osiągnięty (%s, mecz ID=%d, drużyna ID=%d)",
+			threshold, rule.Label, change.GameID, change.TeamID)
+		s.socketService.BroadcastToPanel("threshold_reached", payload)
+	}
+}
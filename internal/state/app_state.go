@@ -12,15 +12,16 @@ type AppState struct {
 	activeCameras   []string
 	inactiveCameras []string
 	allCameras      []string
+	programCamera   string // kamera aktualnie na wizji (program)
 }
 
-// NewAppState - tworzy nowy stan aplikacji
-func NewAppState(allCameras []string) *AppState {
+// NewAppState - tworzy nowy stan aplikacji (lista kamer ładowana z bazy przez SetAllCameras)
+func NewAppState() *AppState {
 	return &AppState{
-		allCameras:      allCameras,
+		allCameras:      []string{},
 		isRecording:     false,
 		activeCameras:   []string{},
-		inactiveCameras: allCameras,
+		inactiveCameras: []string{},
 	}
 }
 
@@ -33,6 +34,8 @@ func (s *AppState) GetStatus() models.RecordStatus {
 		RecordStatus:    s.isRecording,
 		ActiveCameras:   append([]string{}, s.activeCameras...),
 		InactiveCameras: append([]string{}, s.inactiveCameras...),
+		AllCameras:      append([]string{}, s.allCameras...),
+		ProgramCamera:   s.programCamera,
 	}
 }
 
@@ -53,7 +56,18 @@ func (s *AppState) StopRecording() {
 
 	s.isRecording = false
 	s.activeCameras = []string{}
-	s.inactiveCameras = s.allCameras
+	s.inactiveCameras = append([]string{}, s.allCameras...)
+}
+
+// SetAllCameras - ustawia listę wszystkich kamer (podczas nagrywania nie zmienia aktywnych)
+func (s *AppState) SetAllCameras(cameras []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.allCameras = append([]string{}, cameras...)
+	if !s.isRecording {
+		s.inactiveCameras = append([]string{}, cameras...)
+	}
 }
 
 // IsRecording - sprawdza czy trwa nagrywanie
@@ -75,4 +89,18 @@ func (s *AppState) GetAllCameras() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return append([]string{}, s.allCameras...)
-}
\ No newline at end of file
+}
+
+// SetProgramCamera - ustawia kamerę aktualnie na wizji
+func (s *AppState) SetProgramCamera(camera string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.programCamera = camera
+}
+
+// GetProgramCamera - pobiera kamerę aktualnie na wizji
+func (s *AppState) GetProgramCamera() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.programCamera
+}
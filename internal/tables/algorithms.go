@@ -1,7 +1,6 @@
 package tables
 
 import (
-	"fmt"
 	"recorder-server/internal/models"
 	"sort"
 	"time"
@@ -14,76 +13,43 @@ func (a *StandardAlgorithm) GetName() string {
 	return "standard"
 }
 
-func (a *StandardAlgorithm) CalculateTable(groupID uint, teams []models.Team, games []models.Game) (*Table, error) {
-	// TODO: Implementacja obliczania tabeli
-	// 1. Dla każdej drużyny zlicz: mecze, wygrane, remisy, przegrane, bramki
-	// 2. Oblicz punkty (zwykle 3 za wygraną, 1 za remis)
-	// 3. Sortuj według punktów, różnicy bramek, bramek zdobytych
-	
-	standings := make([]TeamStanding, 0, len(teams))
-	
-	// Przykładowa struktura - w rzeczywistości trzeba obliczyć na podstawie games
-	for i, team := range teams {
-		standings = append(standings, TeamStanding{
-			Position:       i + 1,
-			Team:           &team,
-			TeamID:         team.ID,
-			Played:         0,
-			Won:            0,
-			Drawn:          0,
-			Lost:           0,
-			GoalsFor:       0,
-			GoalsAgainst:   0,
-			GoalDifference: 0,
-			Points:         0,
-		})
-	}
-	
-	// Sortuj
-	sort.Slice(standings, func(i, j int) bool {
+func (a *StandardAlgorithm) Criteria() []string {
+	return []string{CriterionPoints, CriterionGoalDiff, CriterionGoalsFor}
+}
+
+func (a *StandardAlgorithm) CalculateTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules) (*Table, error) {
+	return a.calculate(groupID, teams, games, points, nil), nil
+}
+
+func (a *StandardAlgorithm) CompareInTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules, team1ID, team2ID uint) (*TeamComparison, error) {
+	return compareInTable(a.calculate, CriterionNone, groupID, teams, games, points, team1ID, team2ID)
+}
+
+// calculate - kolejność kryteriami Criteria() porównywanymi parami
+func (a *StandardAlgorithm) calculate(groupID uint, teams []models.Team, games []GameResult, points PointsRules, trace *comparisonTrace) *Table {
+	standings := ComputeStandings(teams, games, points)
+	trace.addCriteria(standings, a.Criteria(), nil, nil)
+
+	// Sortuj (przy pełnej równości zostaje kolejność drużyn w grupie)
+	sort.SliceStable(standings, func(i, j int) bool {
 		return a.CompareTeams(&standings[i], &standings[j], nil) < 0
 	})
-	
-	// Aktualizuj pozycje
-	for i := range standings {
-		standings[i].Position = i + 1
-	}
-	
+	assignPositions(standings)
+	explainPairwise(standings, a.Criteria())
+
 	return &Table{
 		GroupID:   groupID,
 		Standings: standings,
 		UpdatedAt: time.Now().Format(time.RFC3339),
 		Algorithm: a.GetName(),
-	}, nil
+		Criteria:  a.Criteria(),
+	}
 }
 
-func (a *StandardAlgorithm) CompareTeams(s1, s2 *TeamStanding, headToHeadGames []models.Game) int {
-	// Najpierw punkty
-	if s1.Points != s2.Points {
-		if s1.Points > s2.Points {
-			return -1 // s1 wyżej
-		}
-		return 1 // s2 wyżej
-	}
-	
-	// Potem różnica bramek
-	if s1.GoalDifference != s2.GoalDifference {
-		if s1.GoalDifference > s2.GoalDifference {
-			return -1
-		}
-		return 1
-	}
-	
-	// Potem bramki zdobyte
-	if s1.GoalsFor != s2.GoalsFor {
-		if s1.GoalsFor > s2.GoalsFor {
-			return -1
-		}
-		return 1
-	}
-	
-	// Równe
-	return 0
+func (a *StandardAlgorithm) CompareTeams(s1, s2 *TeamStanding, headToHeadGames []GameResult) int {
+	// Punkty, różnica bramek, bramki zdobyte
+	result, _, _ := compareCriteria(a.Criteria(), s1, s2, nil, nil)
+	return result
 }
 
 // MZPNAlgorithm - algorytm z uwzględnieniem meczów bezpośrednich
@@ -93,13 +59,102 @@ func (a *MZPNAlgorithm) GetName() string {
 	return "mzpn"
 }
 
-func (a *MZPNAlgorithm) CalculateTable(groupID uint, teams []models.Team, games []models.Game) (*Table, error) {
-	// TODO: Podobnie jak StandardAlgorithm, ale z uwzględnieniem meczów bezpośrednich
-	
-	return nil, fmt.Errorf("mzpn algorithm not implemented yet")
+func (a *MZPNAlgorithm) Criteria() []string {
+	criteria := append([]string{CriterionPoints}, headToHeadCriteria...)
+	return append(criteria, overallCriteria...)
+}
+
+func (a *MZPNAlgorithm) CalculateTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules) (*Table, error) {
+	return a.calculate(groupID, teams, games, points, nil), nil
 }
 
-func (a *MZPNAlgorithm) CompareTeams(s1, s2 *TeamStanding, headToHeadGames []models.Game) int {
+func (a *MZPNAlgorithm) CompareInTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules, team1ID, team2ID uint) (*TeamComparison, error) {
+	return compareInTable(a.calculate, CriterionNone, groupID, teams, games, points, team1ID, team2ID)
+}
+
+// calculate - kolejność po punktach, grupy z równą liczbą punktów porządkuje resolveTie
+func (a *MZPNAlgorithm) calculate(groupID uint, teams []models.Team, games []GameResult, points PointsRules, trace *comparisonTrace) *Table {
+	standings := ComputeStandings(teams, games, points)
+	trace.addCriteria(standings, []string{CriterionPoints}, nil, nil)
+
+	// Najpierw punkty, potem rozstrzyganie grup z równą liczbą punktów małą tabelą
+	sort.SliceStable(standings, func(i, j int) bool {
+		return compareDesc(standings[i].Points, standings[j].Points) < 0
+	})
+	for start := 0; start < len(standings); {
+		end := start + 1
+		for end < len(standings) && standings[end].Points == standings[start].Points {
+			end++
+		}
+		a.resolveTie(standings[start:end], games, points, trace)
+		start = end
+	}
+	assignPositions(standings)
+	explainPoints(standings)
+
+	return &Table{
+		GroupID:   groupID,
+		Standings: standings,
+		UpdatedAt: time.Now().Format(time.RFC3339),
+		Algorithm: a.GetName(),
+		Criteria:  a.Criteria(),
+	}
+}
+
+// resolveTie - porządkuje drużyny z równą liczbą punktów
+//
+// Mała tabela meczów bezpośrednich (punkty, różnica bramek, bramki zdobyte, bramki na wyjeździe)
+// liczona jest tylko między remisującymi drużynami. Jeśli rozdzieli część z nich, dla każdej
+// wciąż remisującej podgrupy mała tabela liczona jest ponownie - tylko z jej meczów. Gdy nie
+// rozdziela nikogo, decyduje różnica bramek i bramki zdobyte we wszystkich meczach.
+func (a *MZPNAlgorithm) resolveTie(group []TeamStanding, games []GameResult, points PointsRules, trace *comparisonTrace) {
+	if len(group) < 2 {
+		return
+	}
+
+	teamIDs := make([]uint, len(group))
+	for i := range group {
+		teamIDs[i] = group[i].TeamID
+	}
+	mini := MiniTable(teamIDs, games, points)
+	trace.addCriteria(group, headToHeadCriteria, mini, teamIDs)
+	compare := func(s1, s2 *TeamStanding) (int, string, [2]int) {
+		return compareCriteria(headToHeadCriteria, s1, s2, mini[s1.TeamID], mini[s2.TeamID])
+	}
+
+	sort.SliceStable(group, func(i, j int) bool {
+		result, _, _ := compare(&group[i], &group[j])
+		return result < 0
+	})
+
+	for start := 0; start < len(group); {
+		end := start + 1
+		for end < len(group) {
+			if result, _, _ := compare(&group[start], &group[end]); result != 0 {
+				break
+			}
+			end++
+		}
+		if start == 0 && end == len(group) {
+			// Mała tabela nie rozdziela drużyn - kryteria ogólne
+			trace.addCriteria(group, overallCriteria, nil, nil)
+			sort.SliceStable(group, func(i, j int) bool {
+				result, _, _ := compareCriteria(overallCriteria, &group[i], &group[j], nil, nil)
+				return result < 0
+			})
+			explainPairwise(group, overallCriteria)
+			return
+		}
+		a.resolveTie(group[start:end], games, points, trace)
+		if end < len(group) {
+			_, criterion, values := compare(&group[end-1], &group[end])
+			explain(&group[end-1], &group[end], criterion, values)
+		}
+		start = end
+	}
+}
+
+func (a *MZPNAlgorithm) CompareTeams(s1, s2 *TeamStanding, headToHeadGames []GameResult) int {
 	// Najpierw punkty
 	if s1.Points != s2.Points {
 		if s1.Points > s2.Points {
@@ -107,64 +162,156 @@ func (a *MZPNAlgorithm) CompareTeams(s1, s2 *TeamStanding, headToHeadGames []mod
 		}
 		return 1
 	}
-	
-	// Potem mecze bezpośrednie
+
+	// Potem mecze bezpośrednie (przy dwóch drużynach kolejność zależy tylko od bilansu zwycięstw)
 	if len(headToHeadGames) > 0 {
-		// TODO: Analiza meczów bezpośrednich
-		// - punkty w meczach bezpośrednich
-		// - różnica bramek w meczach bezpośrednich
-		// - bramki zdobyte na wyjeździe w meczach bezpośrednich
-	}
-	
-	// Potem różnica bramek ogólna
-	if s1.GoalDifference != s2.GoalDifference {
-		if s1.GoalDifference > s2.GoalDifference {
-			return -1
+		mini := MiniTable([]uint{s1.TeamID, s2.TeamID}, headToHeadGames, DefaultPointsRules())
+		if result, _, _ := compareCriteria(headToHeadCriteria, s1, s2, mini[s1.TeamID], mini[s2.TeamID]); result != 0 {
+			return result
 		}
-		return 1
 	}
-	
-	return 0
+
+	// Potem różnica bramek i bramki zdobyte ogółem
+	result, _, _ := compareCriteria(overallCriteria, s1, s2, nil, nil)
+	return result
 }
 
-// GoalDifferenceFirstAlgorithm - algorytm priorytetyzujący różnicę bramek
+// NalffutsalAlgorithm - algorytm wg regulaminu NALF Futsal
+//
+// Walkower (IsDSQ) liczony jest 5:0 bez względu na zapisany wynik (punkty przegranego - PointsRules.Walkover).
+// Przy równej liczbie punktów decydują kolejno: punkty i różnica bramek w meczach bezpośrednich drużyn
+// z tą samą liczbą punktów, różnica bramek, bramki zdobyte, punkty fair play (żółta 1, czerwona 3 - mniej = wyżej), losowanie.
 type NalffutsalAlgorithm struct{}
 
 func (a *NalffutsalAlgorithm) GetName() string {
 	return "nalffutsal"
 }
 
-func (a *NalffutsalAlgorithm) CalculateTable(groupID uint, teams []models.Team, games []models.Game) (*Table, error) {
-	// TODO: Implementacja
-	return nil, fmt.Errorf("nalffutsal algorithm not implemented yet")
+func (a *NalffutsalAlgorithm) Criteria() []string {
+	criteria := append([]string{CriterionPoints}, nalffutsalTiebreakers...)
+	return append(criteria, CriterionLot)
 }
 
-func (a *NalffutsalAlgorithm) CompareTeams(s1, s2 *TeamStanding, headToHeadGames []models.Game) int {
-	// Najpierw różnica bramek (!)
-	if s1.GoalDifference != s2.GoalDifference {
-		if s1.GoalDifference > s2.GoalDifference {
-			return -1
+func (a *NalffutsalAlgorithm) CalculateTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules) (*Table, error) {
+	return a.calculate(groupID, teams, games, points, nil), nil
+}
+
+func (a *NalffutsalAlgorithm) CompareInTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules, team1ID, team2ID uint) (*TeamComparison, error) {
+	return compareInTable(a.calculate, CriterionLot, groupID, teams, games, points, team1ID, team2ID)
+}
+
+// calculate - walkowery 5:0, punkty fair play, kolejność po punktach i resolveTie
+func (a *NalffutsalAlgorithm) calculate(groupID uint, teams []models.Team, games []GameResult, points PointsRules, trace *comparisonTrace) *Table {
+	results, doubleWalkovers := walkoverResults(games)
+	standings := ComputeStandings(teams, results, points)
+
+	index := make(map[uint]int, len(standings))
+	for i := range standings {
+		index[standings[i].TeamID] = i
+	}
+
+	// Obustronny walkower - obie drużyny przegrywają 0:5
+	walkovers := map[uint]int{}
+	for _, game := range games {
+		if game.HomeDSQ {
+			walkovers[game.HomeTeamID]++
 		}
-		return 1
+		if game.AwayDSQ {
+			walkovers[game.AwayTeamID]++
+		}
+	}
+	for _, game := range doubleWalkovers {
+		for _, teamID := range []uint{game.HomeTeamID, game.AwayTeamID} {
+			if i, ok := index[teamID]; ok {
+				addResult(&standings[i], 0, NalffutsalWalkoverGoals, true, true, points)
+			}
+		}
+	}
+
+	fairPlay := FairPlayPoints(games)
+	for i := range standings {
+		standings[i].CustomData = map[string]interface{}{
+			"fair_play_points": fairPlay[standings[i].TeamID],
+			"walkovers":        walkovers[standings[i].TeamID],
+		}
+	}
+
+	trace.addCriteria(standings, []string{CriterionPoints}, nil, nil)
+	sort.SliceStable(standings, func(i, j int) bool {
+		return compareDesc(standings[i].Points, standings[j].Points) < 0
+	})
+	for start := 0; start < len(standings); {
+		end := start + 1
+		for end < len(standings) && standings[end].Points == standings[start].Points {
+			end++
+		}
+		a.resolveTie(standings[start:end], results, points, trace)
+		start = end
+	}
+	assignPositions(standings)
+	explainPoints(standings)
+
+	return &Table{
+		GroupID:   groupID,
+		Standings: standings,
+		UpdatedAt: time.Now().Format(time.RFC3339),
+		Algorithm: a.GetName(),
+		Criteria:  a.Criteria(),
+	}
+}
+
+// resolveTie - porządkuje drużyny z równą liczbą punktów i zapisuje wyjaśnienie kolejności
+func (a *NalffutsalAlgorithm) resolveTie(group []TeamStanding, games []GameResult, points PointsRules, trace *comparisonTrace) {
+	if len(group) < 2 {
+		return
 	}
-	
-	// Potem punkty
+
+	teamIDs := make([]uint, len(group))
+	for i := range group {
+		teamIDs[i] = group[i].TeamID
+	}
+	mini := MiniTable(teamIDs, games, points)
+	trace.addCriteria(group, nalffutsalTiebreakers, mini, teamIDs)
+
+	sort.SliceStable(group, func(i, j int) bool {
+		result, _, _ := compareCriteria(nalffutsalTiebreakers, &group[i], &group[j], mini[group[i].TeamID], mini[group[j].TeamID])
+		return result < 0
+	})
+
+	for i := 0; i < len(group)-1; i++ {
+		upper, lower := &group[i], &group[i+1]
+		_, criterion, values := compareCriteria(nalffutsalTiebreakers, upper, lower, mini[upper.TeamID], mini[lower.TeamID])
+		if criterion == "" {
+			// Wszystkie kryteria równe - kolejność drużyn w grupie traktowana jako wynik losowania
+			criterion = CriterionLot
+		}
+		explain(upper, lower, criterion, values)
+	}
+}
+
+func (a *NalffutsalAlgorithm) CompareTeams(s1, s2 *TeamStanding, headToHeadGames []GameResult) int {
+	// Najpierw punkty
 	if s1.Points != s2.Points {
 		if s1.Points > s2.Points {
 			return -1
 		}
 		return 1
 	}
-	
-	return 0
+
+	// Potem mecze bezpośrednie, bilans ogólny i fair play
+	results, _ := walkoverResults(headToHeadGames)
+	mini := MiniTable([]uint{s1.TeamID, s2.TeamID}, results, DefaultPointsRules())
+	result, _, _ := compareCriteria(nalffutsalTiebreakers, s1, s2, mini[s1.TeamID], mini[s2.TeamID])
+	return result
 }
 
 // RegisterDefaultAlgorithms - rejestruje domyślne algorytmy
 // Ta funkcja powinna być wywołana podczas inicjalizacji aplikacji
 func RegisterDefaultAlgorithms() {
 	registry := GetAlgorithmRegistry()
-	
+
 	registry.RegisterAlgorithm("standard", &StandardAlgorithm{})
 	registry.RegisterAlgorithm("mzpn", &MZPNAlgorithm{})
 	registry.RegisterAlgorithm("nalffutsal", &NalffutsalAlgorithm{})
+	registry.RegisterAlgorithm("configurable", &ConfigurableAlgorithm{})
 }
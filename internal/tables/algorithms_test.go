@@ -0,0 +1,294 @@
+package tables
+
+import (
+	"recorder-server/internal/models"
+	"testing"
+)
+
+// testTeams - drużyny o ID 1..n (nazwy A, B, C...)
+func testTeams(n int) []models.Team {
+	teams := make([]models.Team, n)
+	for i := range teams {
+		teams[i] = models.Team{ID: uint(i + 1), Name: string(rune('A' + i))}
+	}
+	return teams
+}
+
+// result - wynik meczu gospodarz-gość w kolejności dodania
+func result(games *[]GameResult, home, away uint, homeGoals, awayGoals int) {
+	*games = append(*games, GameResult{
+		GameID:     uint(len(*games) + 1),
+		Round:      len(*games) + 1,
+		HomeTeamID: home,
+		AwayTeamID: away,
+		HomeGoals:  homeGoals,
+		AwayGoals:  awayGoals,
+	})
+}
+
+// order - nazwy drużyn w kolejności tabeli
+func order(table *Table) string {
+	names := ""
+	for _, standing := range table.Standings {
+		names += standing.Team.Name
+	}
+	return names
+}
+
+func TestStandardAlgorithmCountsResults(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 2, 1)
+	result(&games, 3, 1, 1, 1)
+	result(&games, 2, 3, 0, 3)
+
+	table, err := (&StandardAlgorithm{}).CalculateTable(1, testTeams(3), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order(table); got != "CAB" {
+		t.Fatalf("kolejność = %s, oczekiwano CAB", got)
+	}
+
+	a := table.Standings[1]
+	if a.Played != 2 || a.Won != 1 || a.Drawn != 1 || a.Lost != 0 || a.GoalsFor != 3 || a.GoalsAgainst != 2 || a.Points != 4 || a.Form != "WD" {
+		t.Fatalf("nieprawidłowe statystyki A: %+v", a)
+	}
+}
+
+func TestMZPNHeadToHeadBeatsGoalDifference(t *testing.T) {
+	// A i B po 4 pkt; B ma lepszą różnicę bramek, ale A wygrało mecz bezpośredni
+	var games []GameResult
+	result(&games, 1, 2, 1, 0)
+	result(&games, 2, 3, 5, 0)
+	result(&games, 1, 4, 0, 0)
+	result(&games, 2, 4, 0, 0)
+
+	standard, _ := (&StandardAlgorithm{}).CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if got := order(standard); got != "BADC" {
+		t.Fatalf("standard: kolejność = %s, oczekiwano BADC", got)
+	}
+
+	table, err := (&MZPNAlgorithm{}).CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order(table); got != "ABDC" {
+		t.Fatalf("mzpn: kolejność = %s, oczekiwano ABDC", got)
+	}
+}
+
+func TestMZPNAwayGoalsInHeadToHead(t *testing.T) {
+	// Dwumecz A-B 2:1 i B-A 1:0: równe punkty, bilans i bramki - B strzeliło gola na wyjeździe
+	var games []GameResult
+	result(&games, 1, 2, 2, 1)
+	result(&games, 2, 1, 1, 0)
+
+	table, err := (&MZPNAlgorithm{}).CalculateTable(1, testTeams(2), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order(table); got != "BA" {
+		t.Fatalf("kolejność = %s, oczekiwano BA", got)
+	}
+}
+
+func TestMZPNHeadToHeadOverridesOverallBalance(t *testing.T) {
+	// B i C po 6 pkt; C ma lepszy bilans ogólny (+5 wobec +1), ale B wygrało mecz bezpośredni 2:0
+	var games []GameResult
+	result(&games, 1, 2, 1, 0)
+	result(&games, 2, 3, 2, 0)
+	result(&games, 3, 1, 1, 0)
+	result(&games, 1, 4, 1, 0)
+	result(&games, 2, 4, 1, 0)
+	result(&games, 3, 4, 9, 0)
+	result(&games, 2, 1, 1, 2)
+	result(&games, 4, 3, 3, 0)
+
+	table, err := (&MZPNAlgorithm{}).CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order(table); got != "ABCD" {
+		t.Fatalf("kolejność = %s, oczekiwano ABCD", got)
+	}
+}
+
+func TestMZPNRecursiveMiniTable(t *testing.T) {
+	// Wszystkie cztery drużyny po 4 pkt. Mała tabela czterech drużyn wyłania D (bramka na wyjeździe),
+	// a A, B i C pozostają równe - mała tabela liczona tylko z ich meczów daje C > A > B.
+	var games []GameResult
+	result(&games, 1, 2, 1, 0) // A-B
+	result(&games, 3, 1, 1, 0) // C-A
+	result(&games, 2, 3, 0, 0) // B-C
+	result(&games, 1, 4, 0, 0) // A-D
+	result(&games, 2, 4, 1, 0) // B-D
+	result(&games, 3, 4, 0, 1) // C-D
+
+	table, err := (&MZPNAlgorithm{}).CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, standing := range table.Standings {
+		if standing.Points != 4 {
+			t.Fatalf("drużyna %s ma %d pkt, oczekiwano 4", standing.Team.Name, standing.Points)
+		}
+	}
+	if got := order(table); got != "DCAB" {
+		t.Fatalf("kolejność = %s, oczekiwano DCAB", got)
+	}
+}
+
+func TestMZPNFallsBackToOverallCriteria(t *testing.T) {
+	// Cykl A>B>C>A 1:0 u siebie - mała tabela nie rozdziela, decyduje bilans ogólny
+	var games []GameResult
+	result(&games, 1, 2, 1, 0)
+	result(&games, 2, 3, 1, 0)
+	result(&games, 3, 1, 1, 0)
+	result(&games, 3, 4, 3, 0)
+	result(&games, 2, 4, 2, 0)
+	result(&games, 1, 4, 1, 0)
+
+	table, err := (&MZPNAlgorithm{}).CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order(table); got != "CBAD" {
+		t.Fatalf("kolejność = %s, oczekiwano CBAD", got)
+	}
+}
+
+func TestMZPNCompareTeams(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 1, 0)
+	result(&games, 2, 3, 5, 0)
+	result(&games, 1, 4, 0, 0)
+	result(&games, 2, 4, 0, 0)
+
+	standings := ComputeStandings(testTeams(4), games, DefaultPointsRules())
+	algorithm := &MZPNAlgorithm{}
+	if got := algorithm.CompareTeams(&standings[0], &standings[1], GamesBetween(games, 1, 2)); got != -1 {
+		t.Fatalf("CompareTeams(A, B) = %d, oczekiwano -1", got)
+	}
+	if got := algorithm.CompareTeams(&standings[0], &standings[1], nil); got != 1 {
+		t.Fatalf("CompareTeams(A, B) bez meczów bezpośrednich = %d, oczekiwano 1", got)
+	}
+}
+
+// publishedResult - wynik meczu grupy (gospodarz wg oficjalnego terminarza)
+type publishedResult struct {
+	home, away           string
+	homeGoals, awayGoals int
+}
+
+// publishedGroup - rzeczywista grupa turniejowa: drużyny, wyniki i opublikowana kolejność
+type publishedGroup struct {
+	name    string
+	teams   []string
+	results []publishedResult
+	want    []string
+}
+
+func TestMZPNPublishedTables(t *testing.T) {
+	// Grupy turniejów UEFA EURO z remisami punktowymi rozstrzygniętymi kryteriami meczów bezpośrednich.
+	// Regulamin EURO stosuje tę samą kolejność co MZPN: mała tabela drużyn z równą liczbą punktów
+	// przed bilansem ogólnym. Oczekiwana kolejność to opublikowana tabela końcowa grupy (uefa.com).
+	groups := []publishedGroup{
+		{
+			// Wszystkie cztery drużyny po 4 pkt: Rumunia i Belgia - różnica bramek w małej tabeli +1,
+			// decydują bramki zdobyte (4 wobec 2), mimo że Belgia wygrała mecz bezpośredni 2:0
+			name:  "EURO 2024, grupa E",
+			teams: []string{"Belgia", "Słowacja", "Rumunia", "Ukraina"},
+			results: []publishedResult{
+				{"Rumunia", "Ukraina", 3, 0}, {"Belgia", "Słowacja", 0, 1},
+				{"Słowacja", "Ukraina", 1, 2}, {"Belgia", "Rumunia", 2, 0},
+				{"Słowacja", "Rumunia", 1, 1}, {"Ukraina", "Belgia", 0, 0},
+			},
+			want: []string{"Rumunia", "Belgia", "Słowacja", "Ukraina"},
+		},
+		{
+			// Dania, Finlandia i Rosja po 3 pkt, w małej tabeli każda po 3 pkt - decyduje różnica bramek
+			name:  "EURO 2020, grupa B",
+			teams: []string{"Dania", "Finlandia", "Belgia", "Rosja"},
+			results: []publishedResult{
+				{"Dania", "Finlandia", 0, 1}, {"Belgia", "Rosja", 3, 0},
+				{"Finlandia", "Rosja", 0, 1}, {"Dania", "Belgia", 1, 2},
+				{"Rosja", "Dania", 1, 4}, {"Finlandia", "Belgia", 0, 2},
+			},
+			want: []string{"Belgia", "Dania", "Finlandia", "Rosja"},
+		},
+		{
+			// Szwecja, Dania i Włochy po 5 pkt, wszystkie mecze między nimi remisowe - decydują bramki zdobyte
+			name:  "EURO 2004, grupa C",
+			teams: []string{"Dania", "Włochy", "Szwecja", "Bułgaria"},
+			results: []publishedResult{
+				{"Dania", "Włochy", 0, 0}, {"Szwecja", "Bułgaria", 5, 0},
+				{"Bułgaria", "Dania", 0, 2}, {"Włochy", "Szwecja", 1, 1},
+				{"Włochy", "Bułgaria", 2, 1}, {"Dania", "Szwecja", 2, 2},
+			},
+			want: []string{"Szwecja", "Dania", "Włochy", "Bułgaria"},
+		},
+		{
+			// Grecja i Rosja po 4 pkt - Rosja ma lepszy bilans ogólny (+2 wobec 0), ale Grecja wygrała 1:0
+			name:  "EURO 2012, grupa A",
+			teams: []string{"Polska", "Grecja", "Rosja", "Czechy"},
+			results: []publishedResult{
+				{"Polska", "Grecja", 1, 1}, {"Rosja", "Czechy", 4, 1},
+				{"Grecja", "Czechy", 1, 2}, {"Polska", "Rosja", 1, 1},
+				{"Czechy", "Polska", 1, 0}, {"Grecja", "Rosja", 1, 0},
+			},
+			want: []string{"Czechy", "Grecja", "Rosja", "Polska"},
+		},
+		{
+			// Niemcy i Polska po 7 pkt, remis 0:0 - decyduje różnica bramek we wszystkich meczach (+3 wobec +2)
+			name:  "EURO 2016, grupa C",
+			teams: []string{"Polska", "Irlandia Północna", "Niemcy", "Ukraina"},
+			results: []publishedResult{
+				{"Polska", "Irlandia Północna", 1, 0}, {"Niemcy", "Ukraina", 2, 0},
+				{"Ukraina", "Irlandia Północna", 0, 2}, {"Niemcy", "Polska", 0, 0},
+				{"Ukraina", "Polska", 0, 1}, {"Irlandia Północna", "Niemcy", 0, 1},
+			},
+			want: []string{"Niemcy", "Polska", "Irlandia Północna", "Ukraina"},
+		},
+	}
+
+	for _, group := range groups {
+		// Kolejność drużyn w grupie nie może wpływać na wynik - sprawdzamy też odwróconą
+		for _, reversed := range []bool{false, true} {
+			table, err := (&MZPNAlgorithm{}).CalculateTable(1, group.teamList(reversed), group.games(), DefaultPointsRules())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, standing := range table.Standings {
+				if standing.Team.Name != group.want[i] {
+					t.Errorf("%s: miejsce %d = %s, oczekiwano %s", group.name, i+1, standing.Team.Name, group.want[i])
+				}
+			}
+		}
+	}
+}
+
+// teamList - drużyny grupy o ID 1..n (opcjonalnie w odwróconej kolejności)
+func (g publishedGroup) teamList(reversed bool) []models.Team {
+	teams := make([]models.Team, len(g.teams))
+	for i, name := range g.teams {
+		position := i
+		if reversed {
+			position = len(g.teams) - 1 - i
+		}
+		teams[position] = models.Team{ID: uint(i + 1), Name: name}
+	}
+	return teams
+}
+
+// games - wyniki grupy z ID drużyn wg teamList
+func (g publishedGroup) games() []GameResult {
+	ids := map[string]uint{}
+	for i, name := range g.teams {
+		ids[name] = uint(i + 1)
+	}
+	var games []GameResult
+	for _, r := range g.results {
+		result(&games, ids[r.home], ids[r.away], r.homeGoals, r.awayGoals)
+	}
+	return games
+}
@@ -0,0 +1,302 @@
+package tables
+
+import (
+	"fmt"
+	"recorder-server/internal/models"
+	"sort"
+	"time"
+)
+
+// Kryteria kolejności tabeli (Variable "table_tiebreakers")
+const (
+	CriterionPoints      = "points"         // punkty
+	CriterionH2HPoints   = "h2h_points"     // punkty w meczach bezpośrednich
+	CriterionH2HGoalDiff = "h2h_goal_diff"  // różnica bramek w meczach bezpośrednich
+	CriterionH2HGoalsFor = "h2h_goals_for"  // bramki zdobyte w meczach bezpośrednich
+	CriterionH2HAway     = "h2h_away_goals" // bramki zdobyte na wyjeździe w meczach bezpośrednich
+	CriterionGoalDiff    = "goal_diff"      // różnica bramek
+	CriterionGoalsFor    = "goals_for"      // bramki zdobyte
+	CriterionAwayGoals   = "away_goals"     // bramki zdobyte na wyjeździe
+	CriterionWins        = "wins"           // liczba zwycięstw
+	CriterionFairPlay    = "fair_play"      // punkty fair play (mniej = wyżej)
+	CriterionLot         = "lot"            // losowanie (Variable "table_lot_order")
+)
+
+// DefaultCriteria - kryteria algorytmu konfigurowalnego bez "table_tiebreakers"
+var DefaultCriteria = []string{CriterionPoints, CriterionGoalDiff, CriterionGoalsFor}
+
+// criterionLabels - opisy kryteriów
+var criterionLabels = map[string]string{
+	CriterionPoints:      "punkty",
+	CriterionH2HPoints:   "punkty w meczach bezpośrednich",
+	CriterionH2HGoalDiff: "różnica bramek w meczach bezpośrednich",
+	CriterionH2HGoalsFor: "bramki zdobyte w meczach bezpośrednich",
+	CriterionH2HAway:     "bramki na wyjeździe w meczach bezpośrednich",
+	CriterionGoalDiff:    "różnica bramek",
+	CriterionGoalsFor:    "bramki zdobyte",
+	CriterionAwayGoals:   "bramki zdobyte na wyjeździe",
+	CriterionWins:        "zwycięstwa",
+	CriterionFairPlay:    "punkty fair play (mniej = wyżej)",
+	CriterionLot:         "losowanie",
+}
+
+// ConfigurableAlgorithm - algorytm z łańcuchem kryteriów z Variable rozgrywek
+//
+// Kryteria stosowane są kolejno; kryteria meczów bezpośrednich (h2h_*) liczone są tylko z meczów
+// między drużynami, które pozostają równe po wszystkich wcześniejszych kryteriach.
+type ConfigurableAlgorithm struct {
+	Chain    []string // kolejne kryteria (domyślnie DefaultCriteria)
+	LotOrder []uint   // kolejność drużyn wylosowana na wypadek pełnej równości
+	lotRank  map[uint]int
+}
+
+// NewConfigurableAlgorithm - tworzy algorytm z podanym łańcuchem kryteriów
+func NewConfigurableAlgorithm(criteria []string, lotOrder []uint) (*ConfigurableAlgorithm, error) {
+	if len(criteria) == 0 {
+		criteria = DefaultCriteria
+	}
+	for _, criterion := range criteria {
+		if _, ok := criterionLabels[criterion]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCriterion, criterion)
+		}
+	}
+
+	lotRank := make(map[uint]int, len(lotOrder))
+	for i, teamID := range lotOrder {
+		lotRank[teamID] = i
+	}
+	return &ConfigurableAlgorithm{
+		Chain:    append([]string{}, criteria...),
+		LotOrder: append([]uint{}, lotOrder...),
+		lotRank:  lotRank,
+	}, nil
+}
+
+// CriterionLabel - opis kryterium (pusty dla nieznanego)
+func CriterionLabel(criterion string) string {
+	return criterionLabels[criterion]
+}
+
+func (a *ConfigurableAlgorithm) GetName() string {
+	return "configurable"
+}
+
+// Configure - łańcuch kryteriów z "table_tiebreakers" i kolejność losowania z "table_lot_order"
+func (a *ConfigurableAlgorithm) Configure(variable map[string]interface{}) (TableOrderAlgorithm, error) {
+	var criteria []string
+	if raw, ok := variable["table_tiebreakers"].([]interface{}); ok {
+		for _, item := range raw {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidCriterion, item)
+			}
+			criteria = append(criteria, name)
+		}
+	}
+
+	var lotOrder []uint
+	if raw, ok := variable["table_lot_order"].([]interface{}); ok {
+		for _, item := range raw {
+			if id, ok := item.(float64); ok && id > 0 {
+				lotOrder = append(lotOrder, uint(id))
+			}
+		}
+	}
+
+	if len(criteria) == 0 {
+		criteria = a.Criteria()
+	}
+	return NewConfigurableAlgorithm(criteria, lotOrder)
+}
+
+// Criteria - skonfigurowane kryteria lub domyślne
+func (a *ConfigurableAlgorithm) Criteria() []string {
+	if len(a.Chain) == 0 {
+		return DefaultCriteria
+	}
+	return a.Chain
+}
+
+func (a *ConfigurableAlgorithm) CalculateTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules) (*Table, error) {
+	return a.calculate(groupID, teams, games, points, nil), nil
+}
+
+func (a *ConfigurableAlgorithm) CompareInTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules, team1ID, team2ID uint) (*TeamComparison, error) {
+	return compareInTable(a.calculate, CriterionNone, groupID, teams, games, points, team1ID, team2ID)
+}
+
+// calculate - punkty fair play i bramki na wyjeździe, kolejność wybranymi kryteriami (rank)
+func (a *ConfigurableAlgorithm) calculate(groupID uint, teams []models.Team, games []GameResult, points PointsRules, trace *comparisonTrace) *Table {
+	standings := ComputeStandings(teams, games, points)
+
+	fairPlay := FairPlayPoints(games)
+	awayGoals := AwayGoals(games)
+	for i := range standings {
+		standings[i].CustomData = map[string]interface{}{
+			"fair_play_points": fairPlay[standings[i].TeamID],
+			"away_goals":       awayGoals[standings[i].TeamID],
+		}
+	}
+
+	a.rank(standings, 0, games, points, trace)
+	assignPositions(standings)
+
+	return &Table{
+		GroupID:   groupID,
+		Standings: standings,
+		UpdatedAt: time.Now().Format(time.RFC3339),
+		Algorithm: a.GetName(),
+		Criteria:  a.Criteria(),
+	}
+}
+
+// rank - sortuje grupę drużyn kryterium o indeksie idx, a każdą wciąż równą podgrupę kolejnymi kryteriami
+//
+// Na granicach podgrup zapisywane jest wyjaśnienie - rozdzieliło je kryterium idx.
+func (a *ConfigurableAlgorithm) rank(group []TeamStanding, idx int, games []GameResult, points PointsRules, trace *comparisonTrace) {
+	criteria := a.Criteria()
+	if len(group) < 2 {
+		return
+	}
+	if idx >= len(criteria) {
+		for i := 0; i < len(group)-1; i++ {
+			explain(&group[i], &group[i+1], CriterionNone, [2]int{})
+		}
+		return
+	}
+
+	var mini map[uint]*HeadToHead
+	var teamIDs []uint
+	if isHeadToHeadCriterion(criteria[idx]) {
+		teamIDs = make([]uint, len(group))
+		for i := range group {
+			teamIDs[i] = group[i].TeamID
+		}
+		mini = MiniTable(teamIDs, games, points)
+	}
+
+	values := make(map[uint]int, len(group))
+	for i := range group {
+		values[group[i].TeamID] = a.criterionValue(criteria[idx], &group[i], mini, i)
+	}
+	if s1, s2 := trace.pair(group); s1 != nil {
+		compared := [2]int{}
+		if criteria[idx] != CriterionLot {
+			compared = criterionValues(criteria[idx], s1, s2, mini[s1.TeamID], mini[s2.TeamID])
+		}
+		trace.add(criteria[idx], compared, compareDesc(values[s2.TeamID], values[s1.TeamID]), teamIDs)
+	}
+	sort.SliceStable(group, func(i, j int) bool {
+		return values[group[i].TeamID] < values[group[j].TeamID]
+	})
+
+	for start := 0; start < len(group); {
+		end := start + 1
+		for end < len(group) && values[group[end].TeamID] == values[group[start].TeamID] {
+			end++
+		}
+		a.rank(group[start:end], idx+1, games, points, trace)
+		if end < len(group) {
+			upper, lower := &group[end-1], &group[end]
+			criterion := criteria[idx]
+			compared := criterionValues(criterion, upper, lower, mini[upper.TeamID], mini[lower.TeamID])
+			if criterion == CriterionLot {
+				compared = [2]int{}
+			}
+			explain(upper, lower, criterion, compared)
+		}
+		start = end
+	}
+}
+
+// isHeadToHeadCriterion - czy kryterium liczone jest z meczów bezpośrednich
+func isHeadToHeadCriterion(criterion string) bool {
+	switch criterion {
+	case CriterionH2HPoints, CriterionH2HGoalDiff, CriterionH2HGoalsFor, CriterionH2HAway:
+		return true
+	}
+	return false
+}
+
+// criterionValue - wartość kryterium do sortowania rosnąco (mniej = wyżej)
+//
+// position to miejsce drużyny w grupie przed sortowaniem - kolejność losowania dla drużyn spoza LotOrder.
+func (a *ConfigurableAlgorithm) criterionValue(criterion string, standing *TeamStanding, mini map[uint]*HeadToHead, position int) int {
+	if criterion == CriterionLot {
+		if rank, ok := a.lotRank[standing.TeamID]; ok {
+			return rank
+		}
+		return len(a.lotRank) + position
+	}
+	return -CriterionValue(criterion, standing, mini[standing.TeamID])
+}
+
+// CriterionValue - wartość kryterium drużyny w postaci "więcej = wyżej" (fair play ujemnie, losowanie 0)
+func CriterionValue(criterion string, standing *TeamStanding, mini *HeadToHead) int {
+	switch criterion {
+	case CriterionPoints:
+		return standing.Points
+	case CriterionH2HPoints:
+		if mini != nil {
+			return mini.Points
+		}
+	case CriterionH2HGoalDiff:
+		if mini != nil {
+			return mini.GoalDifference
+		}
+	case CriterionH2HGoalsFor:
+		if mini != nil {
+			return mini.GoalsFor
+		}
+	case CriterionH2HAway:
+		if mini != nil {
+			return mini.AwayGoals
+		}
+	case CriterionGoalDiff:
+		return standing.GoalDifference
+	case CriterionGoalsFor:
+		return standing.GoalsFor
+	case CriterionAwayGoals:
+		return customInt(standing, "away_goals")
+	case CriterionWins:
+		return standing.Won
+	case CriterionFairPlay:
+		return -customInt(standing, "fair_play_points")
+	}
+	return 0
+}
+
+// customInt - liczba zapisana w CustomData (0 gdy brak)
+func customInt(standing *TeamStanding, key string) int {
+	if value, ok := standing.CustomData[key].(int); ok {
+		return value
+	}
+	return 0
+}
+
+func (a *ConfigurableAlgorithm) CompareTeams(s1, s2 *TeamStanding, headToHeadGames []GameResult) int {
+	mini := MiniTable([]uint{s1.TeamID, s2.TeamID}, headToHeadGames, DefaultPointsRules())
+	for _, criterion := range a.Criteria() {
+		if criterion == CriterionLot {
+			break
+		}
+		v1 := a.criterionValue(criterion, s1, mini, 0)
+		v2 := a.criterionValue(criterion, s2, mini, 0)
+		if v1 != v2 {
+			if v1 < v2 {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// AwayGoals - bramki zdobyte przez drużyny na wyjeździe
+func AwayGoals(games []GameResult) map[uint]int {
+	goals := map[uint]int{}
+	for _, game := range games {
+		goals[game.AwayTeamID] += game.AwayGoals
+	}
+	return goals
+}
@@ -0,0 +1,106 @@
+package tables
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// configured - algorytm skonfigurowany fragmentem Variable w JSON
+func configured(t *testing.T, variable string) TableOrderAlgorithm {
+	t.Helper()
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(variable), &data); err != nil {
+		t.Fatal(err)
+	}
+	algorithm, err := (&ConfigurableAlgorithm{}).Configure(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return algorithm
+}
+
+func TestConfigurableDefaultMatchesStandard(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 1, 0)
+	result(&games, 2, 3, 5, 0)
+	result(&games, 1, 4, 0, 0)
+	result(&games, 2, 4, 0, 0)
+
+	table, err := configured(t, `{}`).CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order(table); got != "BADC" {
+		t.Fatalf("kolejność = %s, oczekiwano BADC", got)
+	}
+}
+
+func TestConfigurableHeadToHeadChain(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 1, 0)
+	result(&games, 2, 3, 5, 0)
+	result(&games, 1, 4, 0, 0)
+	result(&games, 2, 4, 0, 0)
+
+	algorithm := configured(t, `{"table_tiebreakers": ["points", "h2h_points", "h2h_goal_diff", "goal_diff", "goals_for"]}`)
+	table, err := algorithm.CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order(table); got != "ABDC" {
+		t.Fatalf("kolejność = %s, oczekiwano ABDC", got)
+	}
+}
+
+func TestConfigurableWinsAndLot(t *testing.T) {
+	// Punktacja 2/1/0: A (zwycięstwo i porażka) i B (dwa remisy) mają po 2 pkt, bilans 0 i 1 bramkę
+	var games []GameResult
+	result(&games, 1, 3, 1, 0)
+	result(&games, 4, 1, 1, 0)
+	result(&games, 2, 3, 1, 1)
+	result(&games, 2, 4, 0, 0)
+	points := PointsRules{Win: 2, Draw: 1, Loss: 0}
+
+	table, err := configured(t, `{"table_tiebreakers": ["points", "wins", "goal_diff"]}`).CalculateTable(1, testTeams(4), games, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order(table); got != "DABC" {
+		t.Fatalf("zwycięstwa: kolejność = %s, oczekiwano DABC", got)
+	}
+
+	algorithm := configured(t, `{"table_tiebreakers": ["points", "goal_diff", "goals_for", "lot"], "table_lot_order": [2, 1]}`)
+	table, err = algorithm.CalculateTable(1, testTeams(4), games, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := order(table); got != "DBAC" {
+		t.Fatalf("losowanie: kolejność = %s, oczekiwano DBAC", got)
+	}
+}
+
+func TestConfigurableWalkoverPoints(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 0, 0)
+	games[0].AwayDSQ = true
+	points := PointsRules{Win: 3, Draw: 1, Loss: 0, Walkover: -1}
+
+	table, err := configured(t, `{}`).CalculateTable(1, testTeams(2), games, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, away := table.Standings[0], table.Standings[1]
+	if home.Points != 3 || home.Won != 1 || away.Points != -1 || away.Lost != 1 {
+		t.Fatalf("walkower: %+v / %+v", home, away)
+	}
+}
+
+func TestConfigurableRejectsUnknownCriterion(t *testing.T) {
+	_, err := (&ConfigurableAlgorithm{}).Configure(map[string]interface{}{
+		"table_tiebreakers": []interface{}{"points", "coin_toss"},
+	})
+	if !errors.Is(err, ErrInvalidCriterion) {
+		t.Fatalf("błąd = %v, oczekiwano ErrInvalidCriterion", err)
+	}
+}
@@ -0,0 +1,267 @@
+package tables
+
+import (
+	"fmt"
+	"recorder-server/internal/models"
+)
+
+// CriterionNone - drużyny równe we wszystkich kryteriach algorytmu
+const CriterionNone = "none"
+
+// TiebreakReason - wyjaśnienie, dlaczego drużyna jest wyżej od drużyny z następnej pozycji
+type TiebreakReason struct {
+	Versus      uint   `json:"versus"`      // ID drużyny na następnej pozycji
+	Criterion   string `json:"criterion"`   // kryterium, które rozstrzygnęło
+	Label       string `json:"label"`       // opis kryterium
+	Values      [2]int `json:"values"`      // porównane wartości (ta drużyna, następna)
+	Description string `json:"description"` // opis dla komentatora
+}
+
+// ComparisonStep - jeden krok porównania dwóch drużyn
+type ComparisonStep struct {
+	Criterion string `json:"criterion"`
+	Label     string `json:"label"`
+	Values    [2]int `json:"values"`          // wartości drużyny 1 i drużyny 2
+	Result    int    `json:"result"`          // -1 drużyna 1 wyżej, 0 równe, 1 drużyna 2 wyżej
+	Teams     []uint `json:"teams,omitempty"` // drużyny małej tabeli (kryteria meczów bezpośrednich)
+}
+
+// TeamComparison - porównanie dwóch drużyn krok po kroku według kryteriów algorytmu
+type TeamComparison struct {
+	Team1ID     uint             `json:"team1_id"`
+	Team2ID     uint             `json:"team2_id"`
+	Positions   [2]int           `json:"positions"` // pozycje drużyn w tabeli
+	Algorithm   string           `json:"algorithm"`
+	Steps       []ComparisonStep `json:"steps"`
+	Criterion   string           `json:"criterion"` // kryterium rozstrzygające (CriterionNone przy pełnej równości)
+	Result      int              `json:"result"`    // -1 drużyna 1 wyżej, 1 drużyna 2 wyżej
+	Description string           `json:"description"`
+}
+
+// criterionValues - wartości kryterium do prezentacji (fair play jako punkty karne)
+func criterionValues(criterion string, a, b *TeamStanding, miniA, miniB *HeadToHead) [2]int {
+	values := [2]int{CriterionValue(criterion, a, miniA), CriterionValue(criterion, b, miniB)}
+	if criterion == CriterionFairPlay {
+		values[0], values[1] = -values[0], -values[1]
+	}
+	return values
+}
+
+// compareCriteria - porównuje drużyny kolejnymi kryteriami (bez losowania)
+//
+// Zwraca wynik (-1 a wyżej, 1 b wyżej), rozstrzygające kryterium i porównane wartości;
+// przy pełnej równości kryterium jest puste.
+func compareCriteria(criteria []string, a, b *TeamStanding, miniA, miniB *HeadToHead) (int, string, [2]int) {
+	for _, criterion := range criteria {
+		if criterion == CriterionLot {
+			continue
+		}
+		result := compareDesc(CriterionValue(criterion, a, miniA), CriterionValue(criterion, b, miniB))
+		if result != 0 {
+			return result, criterion, criterionValues(criterion, a, b, miniA, miniB)
+		}
+	}
+	return 0, "", [2]int{}
+}
+
+// teamName - nazwa drużyny do opisów
+func teamName(standing *TeamStanding) string {
+	if standing.Team != nil && standing.Team.Name != "" {
+		return standing.Team.Name
+	}
+	return fmt.Sprintf("Drużyna %d", standing.TeamID)
+}
+
+// explain - zapisuje w drużynie wyżej wyjaśnienie kolejności względem drużyny z następnej pozycji
+func explain(upper, lower *TeamStanding, criterion string, values [2]int) {
+	reason := &TiebreakReason{
+		Versus:    lower.TeamID,
+		Criterion: criterion,
+		Label:     CriterionLabel(criterion),
+		Values:    values,
+	}
+	switch criterion {
+	case "", CriterionNone:
+		reason.Criterion = CriterionNone
+		reason.Label = "brak rozstrzygnięcia"
+		reason.Description = fmt.Sprintf("%s i %s są równe we wszystkich kryteriach", teamName(upper), teamName(lower))
+	case CriterionLot:
+		reason.Description = fmt.Sprintf("%s wyżej niż %s - o kolejności zdecydowało losowanie", teamName(upper), teamName(lower))
+	default:
+		reason.Description = fmt.Sprintf("%s wyżej niż %s - %s: %d - %d",
+			teamName(upper), teamName(lower), reason.Label, values[0], values[1])
+	}
+	upper.Tiebreak = reason
+}
+
+// explainPoints - wyjaśnienia dla sąsiednich drużyn z różną liczbą punktów
+func explainPoints(standings []TeamStanding) {
+	for i := 0; i < len(standings)-1; i++ {
+		upper, lower := &standings[i], &standings[i+1]
+		if upper.Points != lower.Points {
+			explain(upper, lower, CriterionPoints, [2]int{upper.Points, lower.Points})
+		}
+	}
+}
+
+// explainPairwise - wyjaśnienia dla wszystkich sąsiednich drużyn porównywanych parami (bez meczów bezpośrednich)
+func explainPairwise(standings []TeamStanding, criteria []string) {
+	for i := 0; i < len(standings)-1; i++ {
+		upper, lower := &standings[i], &standings[i+1]
+		_, criterion, values := compareCriteria(criteria, upper, lower, nil, nil)
+		explain(upper, lower, criterion, values)
+	}
+}
+
+// tracedCalculate - układanie tabeli przez algorytm z zapisem porównania dwóch drużyn (trace nil - bez zapisu)
+type tracedCalculate func(groupID uint, teams []models.Team, games []GameResult, points PointsRules, trace *comparisonTrace) *Table
+
+// compareInTable - porównanie dwóch drużyn zapisane przez algorytm podczas układania tabeli
+//
+// fallback to kryterium pełnej równości (CriterionLot, gdy algorytm rozstrzyga ją losowaniem).
+func compareInTable(calculate tracedCalculate, fallback string, groupID uint, teams []models.Team, games []GameResult,
+	points PointsRules, team1ID, team2ID uint) (*TeamComparison, error) {
+	trace, err := newComparisonTrace(teams, team1ID, team2ID)
+	if err != nil {
+		return nil, err
+	}
+	return trace.comparison(calculate(groupID, teams, games, points, trace), fallback), nil
+}
+
+// comparisonTrace - kroki porównania dwóch drużyn zapisywane przez algorytm w trakcie układania tabeli
+//
+// Algorytm dopisuje krok tam, gdzie sam rozstrzyga kolejność grupy zawierającej obie drużyny,
+// więc porównanie zawsze zgadza się z tabelą i jej wyjaśnieniami. Metody działają na nil (brak zapisu).
+type comparisonTrace struct {
+	team1ID, team2ID uint
+	steps            []ComparisonStep
+	criterion        string // kryterium rozstrzygające ("" dopóki drużyny są równe)
+}
+
+// newComparisonTrace - zapis porównania dwóch różnych drużyn grupy
+func newComparisonTrace(teams []models.Team, team1ID, team2ID uint) (*comparisonTrace, error) {
+	found := 0
+	for _, team := range teams {
+		if team.ID == team1ID || team.ID == team2ID {
+			found++
+		}
+	}
+	if found != 2 || team1ID == team2ID {
+		return nil, fmt.Errorf("%w: drużyny ID=%d i ID=%d muszą być różnymi drużynami tabeli", ErrInvalidGroupData, team1ID, team2ID)
+	}
+	return &comparisonTrace{team1ID: team1ID, team2ID: team2ID, steps: []ComparisonStep{}}, nil
+}
+
+// pair - porównywane drużyny, jeśli grupa zawiera obie, a porównanie nie jest jeszcze rozstrzygnięte
+func (t *comparisonTrace) pair(group []TeamStanding) (*TeamStanding, *TeamStanding) {
+	if t == nil || t.criterion != "" {
+		return nil, nil
+	}
+	var s1, s2 *TeamStanding
+	for i := range group {
+		switch group[i].TeamID {
+		case t.team1ID:
+			s1 = &group[i]
+		case t.team2ID:
+			s2 = &group[i]
+		}
+	}
+	if s1 == nil || s2 == nil {
+		return nil, nil
+	}
+	return s1, s2
+}
+
+// add - dopisuje krok porównania; wynik różny od 0 rozstrzyga porównanie
+func (t *comparisonTrace) add(criterion string, values [2]int, result int, scope []uint) {
+	step := ComparisonStep{
+		Criterion: criterion,
+		Label:     CriterionLabel(criterion),
+		Values:    values,
+		Result:    result,
+	}
+	if isHeadToHeadCriterion(criterion) {
+		step.Teams = append([]uint{}, scope...)
+	}
+	t.steps = append(t.steps, step)
+	if result != 0 {
+		t.criterion = criterion
+	}
+}
+
+// addCriteria - dopisuje kolejne kryteria (bez losowania) aż do rozstrzygającego
+//
+// mini to mała tabela drużyn scope - dla kryteriów meczów bezpośrednich.
+func (t *comparisonTrace) addCriteria(group []TeamStanding, criteria []string, mini map[uint]*HeadToHead, scope []uint) {
+	s1, s2 := t.pair(group)
+	if s1 == nil {
+		return
+	}
+	for _, criterion := range criteria {
+		if criterion == CriterionLot {
+			continue
+		}
+		result := compareDesc(CriterionValue(criterion, s1, mini[s1.TeamID]), CriterionValue(criterion, s2, mini[s2.TeamID]))
+		t.add(criterion, criterionValues(criterion, s1, s2, mini[s1.TeamID], mini[s2.TeamID]), result, scope)
+		if result != 0 {
+			return
+		}
+	}
+}
+
+// comparison - porównanie z zapisanych kroków i pozycji w tabeli (fallback jak w compareInTable)
+func (t *comparisonTrace) comparison(table *Table, fallback string) *TeamComparison {
+	s1, s2 := findStanding(table, t.team1ID), findStanding(table, t.team2ID)
+
+	comparison := &TeamComparison{
+		Team1ID:   t.team1ID,
+		Team2ID:   t.team2ID,
+		Positions: [2]int{s1.Position, s2.Position},
+		Algorithm: table.Algorithm,
+		Steps:     t.steps,
+		Criterion: t.criterion,
+		Result:    compareDesc(s2.Position, s1.Position),
+	}
+	if comparison.Criterion == "" {
+		comparison.Criterion = CriterionNone
+		if fallback == CriterionLot {
+			comparison.Criterion = CriterionLot
+			comparison.Steps = append(comparison.Steps, ComparisonStep{
+				Criterion: CriterionLot,
+				Label:     CriterionLabel(CriterionLot),
+				Result:    comparison.Result,
+			})
+		}
+	}
+
+	higher, lower := s1, s2
+	if comparison.Result > 0 {
+		higher, lower = s2, s1
+	}
+	switch comparison.Criterion {
+	case CriterionNone:
+		comparison.Description = fmt.Sprintf("%s (%d.) i %s (%d.) są równe we wszystkich kryteriach",
+			teamName(s1), s1.Position, teamName(s2), s2.Position)
+	case CriterionLot:
+		comparison.Description = fmt.Sprintf("%s wyżej niż %s - o kolejności zdecydowało losowanie", teamName(higher), teamName(lower))
+	default:
+		step := comparison.Steps[len(comparison.Steps)-1]
+		values := step.Values
+		if comparison.Result > 0 {
+			values[0], values[1] = values[1], values[0]
+		}
+		comparison.Description = fmt.Sprintf("%s (%d.) wyżej niż %s (%d.) - rozstrzyga %s: %d - %d",
+			teamName(higher), higher.Position, teamName(lower), lower.Position, step.Label, values[0], values[1])
+	}
+	return comparison
+}
+
+// findStanding - pozycja drużyny w tabeli
+func findStanding(table *Table, teamID uint) *TeamStanding {
+	for i := range table.Standings {
+		if table.Standings[i].TeamID == teamID {
+			return &table.Standings[i]
+		}
+	}
+	return nil
+}
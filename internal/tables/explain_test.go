@@ -0,0 +1,174 @@
+package tables
+
+import "testing"
+
+// criteriaOf - kryteria rozstrzygające kolejne pary sąsiednich drużyn
+func criteriaOf(table *Table) []string {
+	criteria := []string{}
+	for _, standing := range table.Standings {
+		if standing.Tiebreak != nil {
+			criteria = append(criteria, standing.Tiebreak.Criterion)
+		}
+	}
+	return criteria
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStandardExplainsAdjacentTeams(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 1, 0)
+	result(&games, 2, 3, 5, 0)
+	result(&games, 1, 4, 0, 0)
+	result(&games, 2, 4, 0, 0)
+
+	table, err := (&StandardAlgorithm{}).CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// B-A: równe punkty, różnica bramek; A-D i D-C: punkty
+	want := []string{CriterionGoalDiff, CriterionPoints, CriterionPoints}
+	if got := criteriaOf(table); !equalStrings(got, want) {
+		t.Fatalf("kryteria = %v, oczekiwano %v", got, want)
+	}
+	if reason := table.Standings[0].Tiebreak; reason.Versus != 1 || reason.Values != [2]int{4, 1} {
+		t.Fatalf("wyjaśnienie B-A = %+v", reason)
+	}
+	if table.Standings[3].Tiebreak != nil {
+		t.Fatal("ostatnia drużyna nie powinna mieć wyjaśnienia")
+	}
+}
+
+func TestMZPNExplainsRecursiveMiniTable(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 1, 0) // A-B
+	result(&games, 3, 1, 1, 0) // C-A
+	result(&games, 2, 3, 0, 0) // B-C
+	result(&games, 1, 4, 0, 0) // A-D
+	result(&games, 2, 4, 1, 0) // B-D
+	result(&games, 3, 4, 0, 1) // C-D
+
+	table, err := (&MZPNAlgorithm{}).CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// D-C: bramki na wyjeździe w małej tabeli czterech drużyn; C-A i A-B: punkty w małej tabeli A, B, C
+	want := []string{CriterionH2HAway, CriterionH2HPoints, CriterionH2HPoints}
+	if got := criteriaOf(table); !equalStrings(got, want) {
+		t.Fatalf("kryteria = %v, oczekiwano %v", got, want)
+	}
+	if values := table.Standings[1].Tiebreak.Values; values != [2]int{4, 3} {
+		t.Fatalf("C-A: wartości = %v, oczekiwano [4 3]", values)
+	}
+}
+
+func TestMZPNExplainsOverallFallback(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 1, 0)
+	result(&games, 2, 3, 1, 0)
+	result(&games, 3, 1, 1, 0)
+	result(&games, 3, 4, 3, 0)
+	result(&games, 2, 4, 2, 0)
+	result(&games, 1, 4, 1, 0)
+
+	table, err := (&MZPNAlgorithm{}).CalculateTable(1, testTeams(4), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{CriterionGoalDiff, CriterionGoalDiff, CriterionPoints}
+	if got := criteriaOf(table); !equalStrings(got, want) {
+		t.Fatalf("kryteria = %v, oczekiwano %v", got, want)
+	}
+}
+
+func TestCompareInTableStepByStep(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 1, 0)
+	result(&games, 2, 3, 5, 0)
+	result(&games, 1, 4, 0, 0)
+	result(&games, 2, 4, 0, 0)
+
+	algorithm := &MZPNAlgorithm{}
+	comparison, err := algorithm.CompareInTable(1, testTeams(4), games, DefaultPointsRules(), 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.Result != 1 || comparison.Criterion != CriterionH2HPoints || comparison.Positions != [2]int{2, 1} {
+		t.Fatalf("porównanie = %+v", comparison)
+	}
+	if len(comparison.Steps) != 2 || comparison.Steps[0].Criterion != CriterionPoints || comparison.Steps[0].Result != 0 {
+		t.Fatalf("kroki = %+v", comparison.Steps)
+	}
+	if step := comparison.Steps[1]; step.Values != [2]int{0, 3} || step.Result != 1 {
+		t.Fatalf("krok meczów bezpośrednich = %+v", step)
+	}
+
+	if _, err := algorithm.CompareInTable(1, testTeams(4), games, DefaultPointsRules(), 1, 9); err == nil {
+		t.Fatal("oczekiwano błędu dla drużyny spoza tabeli")
+	}
+}
+
+func TestCompareInTableAgreesWithTiebreaks(t *testing.T) {
+	// A, B i C po 7 pkt. W małej tabeli trzech drużyn A ma 6 pkt, B i C po 1 pkt - B wyżej dzięki
+	// różnicy bramek w meczach bezpośrednich (-1 wobec -3), choć C ma lepszy bilans ogólny
+	var games []GameResult
+	result(&games, 1, 2, 1, 0) // A-B
+	result(&games, 1, 3, 3, 0) // A-C
+	result(&games, 2, 3, 1, 1) // B-C
+	result(&games, 1, 4, 0, 0) // A-D
+	result(&games, 5, 1, 1, 0) // E-A
+	result(&games, 2, 4, 1, 0) // B-D
+	result(&games, 2, 5, 1, 0) // B-E
+	result(&games, 3, 4, 5, 0) // C-D
+	result(&games, 3, 5, 5, 0) // C-E
+
+	configurable, err := NewConfigurableAlgorithm([]string{CriterionPoints, CriterionH2HPoints, CriterionH2HGoalDiff, CriterionGoalDiff}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, algorithm := range []TableOrderAlgorithm{&MZPNAlgorithm{}, &NalffutsalAlgorithm{}, &StandardAlgorithm{}, configurable} {
+		table, err := algorithm.CalculateTable(1, testTeams(5), games, DefaultPointsRules())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < len(table.Standings)-1; i++ {
+			upper, lower := table.Standings[i], table.Standings[i+1]
+			comparison, err := algorithm.CompareInTable(1, testTeams(5), games, DefaultPointsRules(), upper.TeamID, lower.TeamID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if comparison.Result != -1 || comparison.Criterion != upper.Tiebreak.Criterion {
+				t.Fatalf("%s: %s-%s porównanie %+v, w tabeli %+v", algorithm.GetName(), upper.Team.Name, lower.Team.Name, comparison, upper.Tiebreak)
+			}
+			if step := comparison.Steps[len(comparison.Steps)-1]; comparison.Criterion != CriterionLot && step.Values != upper.Tiebreak.Values {
+				t.Fatalf("%s: %s-%s wartości %v, w tabeli %v", algorithm.GetName(), upper.Team.Name, lower.Team.Name, step.Values, upper.Tiebreak.Values)
+			}
+		}
+
+		// Łańcuch konfigurowalny zawęża małą tabelę po każdym kryterium - C wyżej dzięki bilansowi ogólnemu
+		if algorithm.GetName() != "mzpn" && algorithm.GetName() != "nalffutsal" {
+			continue
+		}
+		if got := order(table); got[:3] != "ABC" {
+			t.Fatalf("%s: kolejność = %s, oczekiwano ABC na początku", algorithm.GetName(), got)
+		}
+		comparison, err := algorithm.CompareInTable(1, testTeams(5), games, DefaultPointsRules(), 3, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step := comparison.Steps[len(comparison.Steps)-1]
+		if comparison.Result != 1 || comparison.Criterion != CriterionH2HGoalDiff || step.Values != [2]int{-3, -1} || len(step.Teams) != 3 {
+			t.Fatalf("%s: porównanie C-B = %+v", algorithm.GetName(), comparison)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package tables
+
+// HeadToHead - statystyki drużyny w meczach bezpośrednich (mała tabela)
+type HeadToHead struct {
+	TeamID         uint `json:"team_id"`
+	Played         int  `json:"played"`
+	Points         int  `json:"points"`
+	GoalsFor       int  `json:"goals_for"`
+	GoalsAgainst   int  `json:"goals_against"`
+	GoalDifference int  `json:"goal_difference"`
+	AwayGoals      int  `json:"away_goals"` // bramki zdobyte na wyjeździe
+}
+
+// MiniTable - mała tabela meczów rozegranych wyłącznie między podanymi drużynami
+func MiniTable(teamIDs []uint, games []GameResult, points PointsRules) map[uint]*HeadToHead {
+	table := make(map[uint]*HeadToHead, len(teamIDs))
+	for _, id := range teamIDs {
+		table[id] = &HeadToHead{TeamID: id}
+	}
+
+	for _, game := range GamesBetween(games, teamIDs...) {
+		home, away := table[game.HomeTeamID], table[game.AwayTeamID]
+		home.add(game.HomeGoals, game.AwayGoals, game.HomeDSQ, game.AwayDSQ, points)
+		away.add(game.AwayGoals, game.HomeGoals, game.AwayDSQ, game.HomeDSQ, points)
+		away.AwayGoals += game.AwayGoals
+	}
+	return table
+}
+
+// add - dopisuje wynik meczu bezpośredniego
+func (h *HeadToHead) add(goalsFor, goalsAgainst int, dsq, opponentDSQ bool, points PointsRules) {
+	h.Played++
+	h.GoalsFor += goalsFor
+	h.GoalsAgainst += goalsAgainst
+	h.GoalDifference = h.GoalsFor - h.GoalsAgainst
+	_, earned := matchOutcome(goalsFor, goalsAgainst, dsq, opponentDSQ, points)
+	h.Points += earned
+}
+
+// compareDesc - porównanie "więcej = wyżej" (-1 gdy a wyżej, 1 gdy b wyżej)
+func compareDesc(a, b int) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
+}
+
+// headToHeadCriteria - kryteria małej tabeli: punkty, różnica bramek, bramki zdobyte, bramki na wyjeździe
+var headToHeadCriteria = []string{CriterionH2HPoints, CriterionH2HGoalDiff, CriterionH2HGoalsFor, CriterionH2HAway}
+
+// overallCriteria - różnica bramek i bramki zdobyte we wszystkich meczach
+var overallCriteria = []string{CriterionGoalDiff, CriterionGoalsFor}
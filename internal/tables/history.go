@@ -0,0 +1,104 @@
+package tables
+
+import (
+	"recorder-server/internal/models"
+	"sort"
+)
+
+// RoundPosition - pozycja drużyny po kolejce
+type RoundPosition struct {
+	TeamID   uint `json:"team_id"`
+	Position int  `json:"position"`
+	Points   int  `json:"points"`
+	Played   int  `json:"played"`
+	Movement int  `json:"movement"` // zmiana względem poprzedniej kolejki (dodatnia = awans w tabeli)
+}
+
+// RoundSnapshot - tabela po danej kolejce
+type RoundSnapshot struct {
+	Round     int             `json:"round"`
+	Positions []RoundPosition `json:"positions"`
+}
+
+// TeamProgress - pozycje drużyny w kolejnych kolejkach (do wykresu)
+type TeamProgress struct {
+	TeamID    uint   `json:"team_id"`
+	TeamName  string `json:"team_name"`
+	Positions []int  `json:"positions"` // pozycja po każdej kolejce z TableHistory.Rounds
+	Movement  int    `json:"movement"`  // zmiana po ostatniej kolejce
+}
+
+// TableHistory - przebieg tabeli grupy kolejka po kolejce
+type TableHistory struct {
+	GroupID   uint            `json:"group_id"`
+	Algorithm string          `json:"algorithm"`
+	Rounds    []int           `json:"rounds"`
+	Snapshots []RoundSnapshot `json:"snapshots"`
+	Teams     []TeamProgress  `json:"teams"`
+}
+
+// BuildHistory - oblicza tabelę po każdej kolejce (Game.Round) z meczów do tej kolejki włącznie
+func BuildHistory(algorithm TableOrderAlgorithm, groupID uint, teams []models.Team, games []GameResult, points PointsRules) (*TableHistory, error) {
+	history := &TableHistory{
+		GroupID:   groupID,
+		Algorithm: algorithm.GetName(),
+		Rounds:    []int{},
+		Snapshots: []RoundSnapshot{},
+		Teams:     make([]TeamProgress, len(teams)),
+	}
+
+	roundSet := map[int]bool{}
+	for _, game := range games {
+		roundSet[game.Round] = true
+	}
+	for round := range roundSet {
+		history.Rounds = append(history.Rounds, round)
+	}
+	sort.Ints(history.Rounds)
+
+	progress := make(map[uint]*TeamProgress, len(teams))
+	for i, team := range teams {
+		history.Teams[i] = TeamProgress{TeamID: team.ID, TeamName: team.Name, Positions: []int{}}
+		progress[team.ID] = &history.Teams[i]
+	}
+
+	previous := map[uint]int{}
+	for _, round := range history.Rounds {
+		played := []GameResult{}
+		for _, game := range games {
+			if game.Round <= round {
+				played = append(played, game)
+			}
+		}
+
+		table, err := algorithm.CalculateTable(groupID, teams, played, points)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot := RoundSnapshot{Round: round, Positions: make([]RoundPosition, len(table.Standings))}
+		current := make(map[uint]int, len(table.Standings))
+		for i, standing := range table.Standings {
+			movement := 0
+			if before, ok := previous[standing.TeamID]; ok {
+				movement = before - standing.Position
+			}
+			snapshot.Positions[i] = RoundPosition{
+				TeamID:   standing.TeamID,
+				Position: standing.Position,
+				Points:   standing.Points,
+				Played:   standing.Played,
+				Movement: movement,
+			}
+			current[standing.TeamID] = standing.Position
+
+			if team := progress[standing.TeamID]; team != nil {
+				team.Positions = append(team.Positions, standing.Position)
+				team.Movement = movement
+			}
+		}
+		history.Snapshots = append(history.Snapshots, snapshot)
+		previous = current
+	}
+	return history, nil
+}
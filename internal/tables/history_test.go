@@ -0,0 +1,38 @@
+package tables
+
+import "testing"
+
+func TestBuildHistory(t *testing.T) {
+	games := []GameResult{
+		{GameID: 1, Round: 1, HomeTeamID: 1, AwayTeamID: 2, HomeGoals: 1, AwayGoals: 0},
+		{GameID: 2, Round: 2, HomeTeamID: 3, AwayTeamID: 1, HomeGoals: 2, AwayGoals: 0},
+		{GameID: 3, Round: 3, HomeTeamID: 2, AwayTeamID: 3, HomeGoals: 3, AwayGoals: 0},
+	}
+
+	history, err := BuildHistory(&StandardAlgorithm{}, 1, testTeams(3), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Rounds) != 3 || len(history.Snapshots) != 3 {
+		t.Fatalf("kolejki = %v, migawki = %d", history.Rounds, len(history.Snapshots))
+	}
+
+	// Kolejka 2: C awansuje z 2. na 1. miejsce, A spada z 1. na 2.
+	second := history.Snapshots[1]
+	if second.Positions[0].TeamID != 3 || second.Positions[0].Movement != 1 ||
+		second.Positions[1].TeamID != 1 || second.Positions[1].Movement != -1 {
+		t.Fatalf("kolejka 2: %+v", second.Positions)
+	}
+
+	want := map[uint][]int{1: {1, 2, 3}, 2: {3, 3, 1}, 3: {2, 1, 2}}
+	for _, team := range history.Teams {
+		for i, position := range want[team.TeamID] {
+			if team.Positions[i] != position {
+				t.Fatalf("drużyna %s: pozycje %v, oczekiwano %v", team.TeamName, team.Positions, want[team.TeamID])
+			}
+		}
+	}
+	if history.Teams[1].Movement != 2 {
+		t.Fatalf("B: ruch po ostatniej kolejce = %d, oczekiwano 2", history.Teams[1].Movement)
+	}
+}
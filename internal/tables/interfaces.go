@@ -7,42 +7,91 @@ import (
 
 // TeamStanding - pozycja drużyny w tabeli
 type TeamStanding struct {
-	Position       int           `json:"position"`        // Pozycja w tabeli
-	Team           *models.Team  `json:"team"`            // Drużyna
-	TeamID         uint          `json:"team_id"`         // ID drużyny
-	Played         int           `json:"played"`          // Rozegrane mecze
-	Won            int           `json:"won"`             // Wygrane
-	Drawn          int           `json:"drawn"`           // Remisy
-	Lost           int           `json:"lost"`            // Przegrane
-	GoalsFor       int           `json:"goals_for"`       // Bramki zdobyte
-	GoalsAgainst   int           `json:"goals_against"`   // Bramki stracone
-	GoalDifference int           `json:"goal_difference"` // Różnica bramek
-	Points         int           `json:"points"`          // Punkty
-	Form           string        `json:"form"`            // Forma (np. "WWLDW")
+	Position       int                    `json:"position"`              // Pozycja w tabeli
+	Team           *models.Team           `json:"team"`                  // Drużyna
+	TeamID         uint                   `json:"team_id"`               // ID drużyny
+	Played         int                    `json:"played"`                // Rozegrane mecze
+	Won            int                    `json:"won"`                   // Wygrane
+	Drawn          int                    `json:"drawn"`                 // Remisy
+	Lost           int                    `json:"lost"`                  // Przegrane
+	GoalsFor       int                    `json:"goals_for"`             // Bramki zdobyte
+	GoalsAgainst   int                    `json:"goals_against"`         // Bramki stracone
+	GoalDifference int                    `json:"goal_difference"`       // Różnica bramek
+	Points         int                    `json:"points"`                // Punkty
+	Form           string                 `json:"form"`                  // Forma (np. "WWLDW")
 	CustomData     map[string]interface{} `json:"custom_data,omitempty"` // Dodatkowe dane specyficzne dla algorytmu
+	Tiebreak       *TiebreakReason        `json:"tiebreak,omitempty"`    // Dlaczego drużyna jest wyżej od następnej
+	Zone           string                 `json:"zone,omitempty"`        // Strefa awansu/spadku (PromotionRules)
 }
 
 // Table - kompletna tabela
 type Table struct {
-	GroupID    uint            `json:"group_id"`
-	GroupName  string          `json:"group_name"`
-	Standings  []TeamStanding  `json:"standings"`
-	UpdatedAt  string          `json:"updated_at"`
-	Algorithm  string          `json:"algorithm"` // Użyty algorytm
+	GroupID   uint           `json:"group_id"`
+	GroupName string         `json:"group_name"`
+	Standings []TeamStanding `json:"standings"`
+	UpdatedAt string         `json:"updated_at"`
+	Algorithm string         `json:"algorithm"`          // Użyty algorytm
+	Criteria  []string       `json:"criteria,omitempty"` // Kolejne kryteria algorytmu
+}
+
+// GameResult - wynik zakończonego meczu (strony z GameTeam, bramki z GameValue)
+type GameResult struct {
+	GameID     uint   `json:"game_id"`
+	Round      int    `json:"round"`
+	DateTime   string `json:"date_time"`
+	HomeTeamID uint   `json:"home_team_id"`
+	AwayTeamID uint   `json:"away_team_id"`
+	HomeGoals  int    `json:"home_goals"`
+	AwayGoals  int    `json:"away_goals"`
+
+	// Dyskwalifikacje (GameValue.IsDSQ) i kartki drużyn - używane przez algorytmy z walkowerami i fair play
+	HomeDSQ         bool `json:"home_dsq,omitempty"`
+	AwayDSQ         bool `json:"away_dsq,omitempty"`
+	HomeYellowCards int  `json:"home_yellow_cards,omitempty"`
+	HomeRedCards    int  `json:"home_red_cards,omitempty"`
+	AwayYellowCards int  `json:"away_yellow_cards,omitempty"`
+	AwayRedCards    int  `json:"away_red_cards,omitempty"`
+}
+
+// PointsRules - punkty za wynik meczu (Variable "table_points")
+type PointsRules struct {
+	Win      int `json:"win"`
+	Draw     int `json:"draw"`
+	Loss     int `json:"loss"`
+	Walkover int `json:"walkover"` // punkty drużyny przegrywającej walkowerem (IsDSQ)
+}
+
+// DefaultPointsRules - 3 pkt za zwycięstwo, 1 za remis, 0 za porażkę i walkower
+func DefaultPointsRules() PointsRules {
+	return PointsRules{Win: 3, Draw: 1, Loss: 0, Walkover: 0}
 }
 
 // TableOrderAlgorithm - interfejs dla algorytmów sortowania tabeli
 type TableOrderAlgorithm interface {
 	GetName() string // Nazwa algorytmu
-	
+
+	// Criteria - kolejne kryteria kolejności (do wyjaśnień i porównań krok po kroku)
+	Criteria() []string
+
 	// CalculateTable - oblicza tabelę dla danej grupy
-	CalculateTable(groupID uint, teams []models.Team, games []models.Game) (*Table, error)
-	
+	CalculateTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules) (*Table, error)
+
+	// CompareInTable - porównanie dwóch drużyn krok po kroku tą samą drogą, którą algorytm ustala kolejność tabeli
+	CompareInTable(groupID uint, teams []models.Team, games []GameResult, points PointsRules, team1ID, team2ID uint) (*TeamComparison, error)
+
 	// CompareTeams - porównuje dwie drużyny i zwraca:
 	// -1 jeśli team1 powinna być wyżej
 	//  0 jeśli są równe
 	// +1 jeśli team2 powinna być wyżej
-	CompareTeams(standing1, standing2 *TeamStanding, headToHeadGames []models.Game) int
+	CompareTeams(standing1, standing2 *TeamStanding, headToHeadGames []GameResult) int
+}
+
+// ConfigurableTableAlgorithm - algorytm, którego kryteria pochodzą z Variable rozgrywek
+type ConfigurableTableAlgorithm interface {
+	TableOrderAlgorithm
+
+	// Configure - zwraca algorytm skonfigurowany danymi z Variable (instancja z rejestru pozostaje bez zmian)
+	Configure(variable map[string]interface{}) (TableOrderAlgorithm, error)
 }
 
 // Errors
@@ -50,4 +99,6 @@ var (
 	ErrAlgorithmNotFound = errors.New("table order algorithm not found")
 	ErrInvalidGroupData  = errors.New("invalid group data")
 	ErrCalculationFailed = errors.New("table calculation failed")
+	ErrInvalidCriterion  = errors.New("invalid tiebreak criterion")
+	ErrTooManyScenarios  = errors.New("too many scenarios to evaluate")
 )
@@ -0,0 +1,44 @@
+package tables
+
+// Regulamin NALF Futsal - walkower i punktacja fair play
+//
+// TODO: wartości niepotwierdzone - sprawdzić z aktualnym regulaminem rozgrywek NALF (nalffutsal.pl)
+// i podać tu numer paragrafu dla walkowera i dla klasyfikacji fair play.
+const (
+	NalffutsalWalkoverGoals = 5 // walkower liczony jako 5:0 niezależnie od zapisanego wyniku
+	FairPlayYellowPoints    = 1 // punkty karne za żółtą kartkę
+	FairPlayRedPoints       = 3 // punkty karne za czerwoną kartkę
+)
+
+// walkoverResults - wyniki z walkowerami zamienionymi na 5:0 dla drużyny niezdyskwalifikowanej
+//
+// Mecze, w których zdyskwalifikowano obie drużyny, zwracane są osobno - każda z drużyn przegrywa 0:5.
+func walkoverResults(games []GameResult) (results []GameResult, doubleWalkovers []GameResult) {
+	results = make([]GameResult, 0, len(games))
+	for _, game := range games {
+		switch {
+		case game.HomeDSQ && game.AwayDSQ:
+			doubleWalkovers = append(doubleWalkovers, game)
+			continue
+		case game.HomeDSQ:
+			game.HomeGoals, game.AwayGoals = 0, NalffutsalWalkoverGoals
+		case game.AwayDSQ:
+			game.HomeGoals, game.AwayGoals = NalffutsalWalkoverGoals, 0
+		}
+		results = append(results, game)
+	}
+	return results, doubleWalkovers
+}
+
+// FairPlayPoints - punkty karne drużyn za kartki (mniej = lepiej)
+func FairPlayPoints(games []GameResult) map[uint]int {
+	points := map[uint]int{}
+	for _, game := range games {
+		points[game.HomeTeamID] += game.HomeYellowCards*FairPlayYellowPoints + game.HomeRedCards*FairPlayRedPoints
+		points[game.AwayTeamID] += game.AwayYellowCards*FairPlayYellowPoints + game.AwayRedCards*FairPlayRedPoints
+	}
+	return points
+}
+
+// nalffutsalTiebreakers - kryteria przy równej liczbie punktów
+var nalffutsalTiebreakers = []string{CriterionH2HPoints, CriterionH2HGoalDiff, CriterionGoalDiff, CriterionGoalsFor, CriterionFairPlay}
@@ -0,0 +1,146 @@
+package tables
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"recorder-server/internal/models"
+	"testing"
+)
+
+// seasonFixture - sezon zapisany w testdata (drużyny, wyniki i oczekiwana tabela)
+type seasonFixture struct {
+	Description string        `json:"description"`
+	Source      string        `json:"source"` // opublikowane wyniki i tabela końcowa (puste dla sezonu syntetycznego)
+	Points      PointsRules   `json:"points"`
+	Teams       []models.Team `json:"teams"`
+	Games       []GameResult  `json:"games"`
+	Expected    []struct {
+		TeamID         uint   `json:"team_id"`
+		Points         int    `json:"points"`
+		GoalsFor       int    `json:"goals_for"`
+		GoalsAgainst   int    `json:"goals_against"`
+		FairPlayPoints int    `json:"fair_play_points"`
+		Walkovers      int    `json:"walkovers"`
+		Tiebreak       string `json:"tiebreak"`
+	} `json:"expected"`
+}
+
+func loadSeasonFixture(t *testing.T, name string) seasonFixture {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture seasonFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatal(err)
+	}
+	return fixture
+}
+
+// TestNalffutsalSeasonFixtures - każdy sezon z testdata/nalffutsal_*.json; sezony rzeczywiste podają źródło
+// wyników i tabeli końcowej w polu source
+func TestNalffutsalSeasonFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "nalffutsal_*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("brak sezonów w testdata")
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			checkSeasonFixture(t, loadSeasonFixture(t, filepath.Base(file)))
+		})
+	}
+}
+
+// checkSeasonFixture - tabela NALF zgodna z oczekiwaną tabelą sezonu
+func checkSeasonFixture(t *testing.T, fixture seasonFixture) {
+	t.Helper()
+	table, err := (&NalffutsalAlgorithm{}).CalculateTable(1, fixture.Teams, fixture.Games, fixture.Points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Standings) != len(fixture.Expected) {
+		t.Fatalf("liczba pozycji = %d, oczekiwano %d", len(table.Standings), len(fixture.Expected))
+	}
+
+	for i, expected := range fixture.Expected {
+		standing := table.Standings[i]
+		if standing.TeamID != expected.TeamID {
+			t.Fatalf("pozycja %d: drużyna %d, oczekiwano %d", i+1, standing.TeamID, expected.TeamID)
+		}
+		if standing.Position != i+1 || standing.Points != expected.Points ||
+			standing.GoalsFor != expected.GoalsFor || standing.GoalsAgainst != expected.GoalsAgainst {
+			t.Errorf("pozycja %d: %+v, oczekiwano %+v", i+1, standing, expected)
+		}
+		if got := standing.CustomData["fair_play_points"]; got != expected.FairPlayPoints {
+			t.Errorf("pozycja %d: fair play = %v, oczekiwano %d", i+1, got, expected.FairPlayPoints)
+		}
+		if got := standing.CustomData["walkovers"]; got != expected.Walkovers {
+			t.Errorf("pozycja %d: walkowery = %v, oczekiwano %d", i+1, got, expected.Walkovers)
+		}
+
+		reason := standing.Tiebreak
+		if i == len(fixture.Expected)-1 {
+			if reason != nil {
+				t.Errorf("ostatnia pozycja: nieoczekiwane wyjaśnienie %+v", reason)
+			}
+			continue
+		}
+		if reason == nil || reason.Versus != table.Standings[i+1].TeamID || reason.Description == "" {
+			t.Fatalf("pozycja %d: niepełne wyjaśnienie %+v", i+1, reason)
+		}
+		if expected.Tiebreak != "" && reason.Criterion != expected.Tiebreak {
+			t.Errorf("pozycja %d: rozstrzygnięcie = %+v, oczekiwano %s", i+1, reason, expected.Tiebreak)
+		}
+	}
+}
+
+func TestNalffutsalWalkoverOverridesRecordedScore(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 2, 3)
+	games[0].AwayDSQ = true
+
+	table, err := (&NalffutsalAlgorithm{}).CalculateTable(1, testTeams(2), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	winner, loser := table.Standings[0], table.Standings[1]
+	if winner.TeamID != 1 || winner.Points != 3 || winner.GoalsFor != NalffutsalWalkoverGoals || loser.GoalsFor != 0 {
+		t.Fatalf("walkower nie został zastosowany: %+v / %+v", winner, loser)
+	}
+}
+
+func TestNalffutsalDoubleWalkover(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 2, 0, 0)
+	games[0].HomeDSQ, games[0].AwayDSQ = true, true
+
+	table, err := (&NalffutsalAlgorithm{}).CalculateTable(1, testTeams(2), games, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, standing := range table.Standings {
+		if standing.Lost != 1 || standing.Points != 0 || standing.GoalsAgainst != NalffutsalWalkoverGoals {
+			t.Fatalf("drużyna %s: %+v, oczekiwano porażki 0:%d", standing.Team.Name, standing, NalffutsalWalkoverGoals)
+		}
+	}
+}
+
+func TestNalffutsalCompareTeamsPointsFirst(t *testing.T) {
+	var games []GameResult
+	result(&games, 1, 3, 9, 0)
+	result(&games, 2, 3, 1, 0)
+	result(&games, 1, 2, 0, 1)
+	result(&games, 1, 3, 0, 0)
+
+	standings := ComputeStandings(testTeams(3), games, DefaultPointsRules())
+	algorithm := &NalffutsalAlgorithm{}
+	// A: 4 pkt, bilans +8; B: 6 pkt, bilans +2 - decydują punkty
+	if got := algorithm.CompareTeams(&standings[0], &standings[1], GamesBetween(games, 1, 2)); got != 1 {
+		t.Fatalf("CompareTeams(A, B) = %d, oczekiwano 1", got)
+	}
+}
@@ -0,0 +1,66 @@
+package tables
+
+import "recorder-server/internal/models"
+
+// ProjectedPosition - pozycja drużyny w tabeli "na żywo" względem tabeli z zakończonych meczów
+type ProjectedPosition struct {
+	TeamID          uint `json:"team_id"`
+	Position        int  `json:"position"`         // pozycja, gdyby trwające mecze skończyły się obecnym wynikiem
+	CurrentPosition int  `json:"current_position"` // pozycja w tabeli z zakończonych meczów
+	Movement        int  `json:"movement"`         // CurrentPosition - Position (dodatnia = awans w tabeli)
+	PointsGained    int  `json:"points_gained"`    // punkty zdobywane w trwających meczach
+	Playing         bool `json:"playing"`          // drużyna gra teraz w jednym z trwających meczów
+}
+
+// ProjectedTable - tabela "na żywo": trwające mecze liczone tak, jakby skończyły się obecnym wynikiem
+type ProjectedTable struct {
+	GroupID   uint                `json:"group_id"`
+	Table     *Table              `json:"table"`
+	LiveGames []GameResult        `json:"live_games"`
+	Positions []ProjectedPosition `json:"positions"` // w kolejności tabeli na żywo
+}
+
+// ProjectTable - oblicza tabelę z zakończonych meczów i tabelę z trwającymi meczami oraz różnice pozycji
+func ProjectTable(algorithm TableOrderAlgorithm, groupID uint, teams []models.Team, finished, live []GameResult, points PointsRules) (*ProjectedTable, error) {
+	current, err := algorithm.CalculateTable(groupID, teams, finished, points)
+	if err != nil {
+		return nil, err
+	}
+
+	games := make([]GameResult, 0, len(finished)+len(live))
+	games = append(append(games, finished...), live...)
+	SortResults(games)
+	table, err := algorithm.CalculateTable(groupID, teams, games, points)
+	if err != nil {
+		return nil, err
+	}
+
+	before := make(map[uint]TeamStanding, len(current.Standings))
+	for _, standing := range current.Standings {
+		before[standing.TeamID] = standing
+	}
+	playing := map[uint]bool{}
+	for _, game := range live {
+		playing[game.HomeTeamID] = true
+		playing[game.AwayTeamID] = true
+	}
+
+	projected := &ProjectedTable{
+		GroupID:   groupID,
+		Table:     table,
+		LiveGames: live,
+		Positions: make([]ProjectedPosition, len(table.Standings)),
+	}
+	for i, standing := range table.Standings {
+		previous := before[standing.TeamID]
+		projected.Positions[i] = ProjectedPosition{
+			TeamID:          standing.TeamID,
+			Position:        standing.Position,
+			CurrentPosition: previous.Position,
+			Movement:        previous.Position - standing.Position,
+			PointsGained:    standing.Points - previous.Points,
+			Playing:         playing[standing.TeamID],
+		}
+	}
+	return projected, nil
+}
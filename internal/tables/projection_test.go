@@ -0,0 +1,33 @@
+package tables
+
+import "testing"
+
+func TestProjectTable(t *testing.T) {
+	var finished, live []GameResult
+	result(&finished, 1, 2, 2, 0)
+	result(&finished, 3, 4, 1, 0)
+	result(&live, 4, 1, 3, 0) // D prowadzi z A
+	live[0].GameID = 3
+
+	projected, err := ProjectTable(&StandardAlgorithm{}, 1, testTeams(4), finished, live, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Po zakończonych meczach: A, C, D, B; na żywo D z 3 pkt i bilansem +2 wyprzedza C i A
+	if got := order(projected.Table); got != "DCAB" {
+		t.Fatalf("kolejność na żywo = %s, oczekiwano DCAB", got)
+	}
+
+	want := map[uint]ProjectedPosition{
+		1: {TeamID: 1, Position: 3, CurrentPosition: 1, Movement: -2, PointsGained: 0, Playing: true},
+		2: {TeamID: 2, Position: 4, CurrentPosition: 4, Movement: 0, PointsGained: 0, Playing: false},
+		3: {TeamID: 3, Position: 2, CurrentPosition: 2, Movement: 0, PointsGained: 0, Playing: false},
+		4: {TeamID: 4, Position: 1, CurrentPosition: 3, Movement: 2, PointsGained: 3, Playing: true},
+	}
+	for _, position := range projected.Positions {
+		if position != want[position.TeamID] {
+			t.Fatalf("pozycja drużyny ID=%d = %+v, oczekiwano %+v", position.TeamID, position, want[position.TeamID])
+		}
+	}
+}
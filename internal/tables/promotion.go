@@ -0,0 +1,246 @@
+package tables
+
+import (
+	"encoding/json"
+	"fmt"
+	"recorder-server/internal/models"
+	"sort"
+	"strings"
+)
+
+// Strefy tabeli
+const (
+	ZonePromoted          = "promoted"           // awans
+	ZonePromotionPlayoff  = "promotion_playoff"  // baraże o awans
+	ZoneRelegationPlayoff = "relegation_playoff" // baraże o utrzymanie
+	ZoneRelegated         = "relegated"          // spadek
+)
+
+// BestPlacedRule - najlepsze drużyny z danego miejsca we wszystkich grupach etapu (np. 2 najlepsze z 3. miejsc)
+type BestPlacedRule struct {
+	Position int    `json:"position"`
+	Count    int    `json:"count"`
+	Zone     string `json:"zone"` // domyślnie ZonePromoted
+}
+
+// PromotionRules - zasady awansu i spadku (JSON w Stage.PromotionRules, nadpisywany przez Group.SpecificPromotionRules)
+type PromotionRules struct {
+	Promoted          int              `json:"promoted"`           // N pierwszych drużyn awansuje
+	PromotionPlayoff  int              `json:"promotion_playoff"`  // kolejne drużyny grają baraże o awans
+	RelegationPlayoff int              `json:"relegation_playoff"` // drużyny nad strefą spadku grają baraże o utrzymanie
+	Relegated         int              `json:"relegated"`          // M ostatnich drużyn spada
+	BestPlaced        []BestPlacedRule `json:"best_placed,omitempty"`
+	NextStageID       uint             `json:"next_stage_id,omitempty"` // etap, do którego awansują drużyny
+	AdvanceZones      []string         `json:"advance_zones,omitempty"` // strefy awansujące do NextStageID (domyślnie ZonePromoted)
+}
+
+// ParsePromotionRules - zasady z JSON; pusty tekst to brak zasad
+//
+// base to zasady etapu - pola obecne w JSON grupy nadpisują tylko odpowiadające im pola.
+func ParsePromotionRules(text *string, base *PromotionRules) (*PromotionRules, error) {
+	rules := &PromotionRules{}
+	if base != nil {
+		copied := *base
+		rules = &copied
+	}
+	if text == nil || strings.TrimSpace(*text) == "" {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(*text), rules); err != nil {
+		return nil, fmt.Errorf("nieprawidłowe zasady awansu: %w", err)
+	}
+	for _, rule := range rules.BestPlaced {
+		if rule.Position < 1 || rule.Count < 1 {
+			return nil, fmt.Errorf("nieprawidłowe zasady awansu: best_placed wymaga position i count > 0")
+		}
+	}
+	return rules, nil
+}
+
+// IsEmpty - czy zasady nie wyznaczają żadnych stref
+func (r *PromotionRules) IsEmpty() bool {
+	return r == nil || (r.Promoted == 0 && r.PromotionPlayoff == 0 && r.RelegationPlayoff == 0 &&
+		r.Relegated == 0 && len(r.BestPlaced) == 0)
+}
+
+// Advances - czy drużyna ze strefy awansuje do następnego etapu
+func (r *PromotionRules) Advances(zone string) bool {
+	if zone == "" {
+		return false
+	}
+	if len(r.AdvanceZones) == 0 {
+		return zone == ZonePromoted
+	}
+	for _, advancing := range r.AdvanceZones {
+		if advancing == zone {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyZones - oznacza strefy awansu i spadku w tabeli grupy
+//
+// Strefy spadku nie nachodzą na strefy awansu w małych grupach - pierwszeństwo ma awans.
+func ApplyZones(table *Table, rules *PromotionRules) {
+	if rules == nil {
+		return
+	}
+	count := len(table.Standings)
+	for i := range table.Standings {
+		position := i + 1
+		fromBottom := count - i
+		zone := ""
+		switch {
+		case position <= rules.Promoted:
+			zone = ZonePromoted
+		case position <= rules.Promoted+rules.PromotionPlayoff:
+			zone = ZonePromotionPlayoff
+		case fromBottom <= rules.Relegated:
+			zone = ZoneRelegated
+		case fromBottom <= rules.Relegated+rules.RelegationPlayoff:
+			zone = ZoneRelegationPlayoff
+		}
+		table.Standings[i].Zone = zone
+	}
+}
+
+// GroupResults - tabela grupy wraz z wynikami (do rankingu między grupami)
+type GroupResults struct {
+	Table  *Table
+	Games  []GameResult
+	Points PointsRules
+}
+
+// CrossGroupEntry - drużyna z danego miejsca w rankingu między grupami
+type CrossGroupEntry struct {
+	Rank      int             `json:"rank"`
+	GroupID   uint            `json:"group_id"`
+	GroupName string          `json:"group_name"`
+	Position  int             `json:"position"` // miejsce w grupie
+	Standing  TeamStanding    `json:"standing"` // statystyki po wyrównaniu liczebności grup
+	Zone      string          `json:"zone,omitempty"`
+	Tiebreak  *TiebreakReason `json:"tiebreak,omitempty"`
+}
+
+// crossGroupCriteria - kryteria rankingu drużyn z tego samego miejsca w różnych grupach
+var crossGroupCriteria = []string{CriterionPoints, CriterionGoalDiff, CriterionGoalsFor, CriterionWins, CriterionFairPlay}
+
+// RankAcrossGroups - ranking drużyn z danego miejsca we wszystkich grupach
+//
+// Gdy grupy mają różną liczbę drużyn, w większych grupach pomijane są mecze z drużynami
+// z miejsc poniżej liczebności najmniejszej grupy. Kolejność: punkty, różnica bramek,
+// bramki zdobyte, zwycięstwa, fair play, a przy pełnej równości kolejność grup.
+func RankAcrossGroups(groups []GroupResults, position int) []CrossGroupEntry {
+	minSize := 0
+	for _, group := range groups {
+		if size := len(group.Table.Standings); minSize == 0 || size < minSize {
+			minSize = size
+		}
+	}
+
+	entries := []CrossGroupEntry{}
+	for _, group := range groups {
+		standings := group.Table.Standings
+		if position < 1 || position > len(standings) {
+			continue
+		}
+		standing := standings[position-1]
+
+		if len(standings) > minSize {
+			kept := make(map[uint]bool, minSize)
+			for i := 0; i < minSize; i++ {
+				kept[standings[i].TeamID] = true
+			}
+			kept[standing.TeamID] = true
+
+			games := []GameResult{}
+			for _, game := range group.Games {
+				if kept[game.HomeTeamID] && kept[game.AwayTeamID] {
+					games = append(games, game)
+				}
+			}
+			adjusted := ComputeStandings(teamsOf(standings), games, group.Points)
+			for _, candidate := range adjusted {
+				if candidate.TeamID == standing.TeamID {
+					candidate.Position = standing.Position
+					candidate.CustomData = map[string]interface{}{
+						"fair_play_points": FairPlayPoints(games)[standing.TeamID],
+					}
+					standing = candidate
+					break
+				}
+			}
+		} else if standing.CustomData == nil || standing.CustomData["fair_play_points"] == nil {
+			standing.CustomData = map[string]interface{}{
+				"fair_play_points": FairPlayPoints(group.Games)[standing.TeamID],
+			}
+		}
+
+		entries = append(entries, CrossGroupEntry{
+			GroupID:   group.Table.GroupID,
+			GroupName: group.Table.GroupName,
+			Position:  position,
+			Standing:  standing,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		result, _, _ := compareCriteria(crossGroupCriteria, &entries[i].Standing, &entries[j].Standing, nil, nil)
+		return result < 0
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i < len(entries)-1 {
+			_, criterion, values := compareCriteria(crossGroupCriteria, &entries[i].Standing, &entries[i+1].Standing, nil, nil)
+			explain(&entries[i].Standing, &entries[i+1].Standing, criterion, values)
+			entries[i].Tiebreak = entries[i].Standing.Tiebreak
+		}
+		entries[i].Standing.Tiebreak = nil
+	}
+	return entries
+}
+
+// teamsOf - drużyny z pozycji tabeli
+func teamsOf(standings []TeamStanding) []models.Team {
+	teams := make([]models.Team, len(standings))
+	for i, standing := range standings {
+		if standing.Team != nil {
+			teams[i] = *standing.Team
+		} else {
+			teams[i] = models.Team{ID: standing.TeamID}
+		}
+	}
+	return teams
+}
+
+// ApplyBestPlaced - oznacza strefy najlepszych drużyn z danego miejsca we wszystkich grupach
+//
+// Zwraca rankingi dla każdej reguły (klucz - miejsce w grupie).
+func ApplyBestPlaced(groups []GroupResults, rules *PromotionRules) map[int][]CrossGroupEntry {
+	rankings := map[int][]CrossGroupEntry{}
+	if rules == nil {
+		return rankings
+	}
+	for _, rule := range rules.BestPlaced {
+		zone := rule.Zone
+		if zone == "" {
+			zone = ZonePromoted
+		}
+		ranking := RankAcrossGroups(groups, rule.Position)
+		for i := range ranking {
+			if i >= rule.Count {
+				break
+			}
+			ranking[i].Zone = zone
+			for _, group := range groups {
+				if group.Table.GroupID != ranking[i].GroupID {
+					continue
+				}
+				group.Table.Standings[rule.Position-1].Zone = zone
+			}
+		}
+		rankings[rule.Position] = ranking
+	}
+	return rankings
+}
@@ -0,0 +1,104 @@
+package tables
+
+import "testing"
+
+// promotionGroups - grupa 1 (3 drużyny) i grupa 2 (4 drużyny)
+func promotionGroups(t *testing.T) []GroupResults {
+	t.Helper()
+	var first, second []GameResult
+	result(&first, 1, 2, 2, 0)
+	result(&first, 1, 3, 1, 0)
+	result(&first, 2, 3, 3, 0)
+
+	result(&second, 4, 5, 1, 0)
+	result(&second, 4, 6, 1, 0)
+	result(&second, 4, 7, 1, 0)
+	result(&second, 5, 6, 2, 0)
+	result(&second, 5, 7, 5, 0)
+	result(&second, 6, 7, 1, 0)
+
+	teams := testTeams(7)
+	algorithm := &StandardAlgorithm{}
+	firstTable, err := algorithm.CalculateTable(1, teams[:3], first, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondTable, err := algorithm.CalculateTable(2, teams[3:], second, DefaultPointsRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []GroupResults{
+		{Table: firstTable, Games: first, Points: DefaultPointsRules()},
+		{Table: secondTable, Games: second, Points: DefaultPointsRules()},
+	}
+}
+
+func TestParsePromotionRulesGroupOverride(t *testing.T) {
+	stageText := `{"promoted": 2, "relegated": 1, "next_stage_id": 5}`
+	groupText := `{"promoted": 1}`
+
+	stage, err := ParsePromotionRules(&stageText, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, err := ParsePromotionRules(&groupText, stage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.Promoted != 1 || group.Relegated != 1 || group.NextStageID != 5 || stage.Promoted != 2 {
+		t.Fatalf("zasady grupy = %+v, etapu = %+v", group, stage)
+	}
+
+	legacy := "Awans uzyskują dwie pierwsze drużyny"
+	if _, err := ParsePromotionRules(&legacy, nil); err == nil {
+		t.Fatal("oczekiwano błędu dla zasad zapisanych tekstem")
+	}
+}
+
+func TestApplyZones(t *testing.T) {
+	groups := promotionGroups(t)
+	rules := &PromotionRules{Promoted: 1, PromotionPlayoff: 1, RelegationPlayoff: 1, Relegated: 1}
+	ApplyZones(groups[1].Table, rules)
+
+	want := []string{ZonePromoted, ZonePromotionPlayoff, ZoneRelegationPlayoff, ZoneRelegated}
+	for i, standing := range groups[1].Table.Standings {
+		if standing.Zone != want[i] {
+			t.Fatalf("pozycja %d: strefa %q, oczekiwano %q", i+1, standing.Zone, want[i])
+		}
+	}
+}
+
+func TestRankAcrossGroupsIgnoresExtraTeams(t *testing.T) {
+	groups := promotionGroups(t)
+
+	// E (2. w grupie 2) ma 6 pkt, ale bez meczu z ostatnią G - 3 pkt, bilans +1, 2 bramki; B: 3 pkt, +1, 3 bramki
+	ranking := RankAcrossGroups(groups, 2)
+	if len(ranking) != 2 || ranking[0].Standing.TeamID != 2 || ranking[1].Standing.TeamID != 5 {
+		t.Fatalf("ranking = %+v", ranking)
+	}
+	if adjusted := ranking[1].Standing; adjusted.Points != 3 || adjusted.Played != 2 || adjusted.GoalsFor != 2 {
+		t.Fatalf("statystyki E po wyrównaniu = %+v", adjusted)
+	}
+	if reason := ranking[0].Tiebreak; reason == nil || reason.Criterion != CriterionGoalsFor {
+		t.Fatalf("wyjaśnienie = %+v", reason)
+	}
+}
+
+func TestApplyBestPlaced(t *testing.T) {
+	groups := promotionGroups(t)
+	rules := &PromotionRules{Promoted: 1, BestPlaced: []BestPlacedRule{{Position: 2, Count: 1}}}
+	for _, group := range groups {
+		ApplyZones(group.Table, rules)
+	}
+	ApplyBestPlaced(groups, rules)
+
+	if zone := groups[0].Table.Standings[1].Zone; zone != ZonePromoted {
+		t.Fatalf("B: strefa %q, oczekiwano awansu", zone)
+	}
+	if zone := groups[1].Table.Standings[1].Zone; zone != "" {
+		t.Fatalf("E: strefa %q, oczekiwano braku strefy", zone)
+	}
+	if !rules.Advances(ZonePromoted) || rules.Advances(ZonePromotionPlayoff) {
+		t.Fatal("domyślnie awansuje tylko strefa awansu")
+	}
+}
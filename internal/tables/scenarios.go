@@ -0,0 +1,756 @@
+package tables
+
+import (
+	"fmt"
+	"recorder-server/internal/models"
+	"sort"
+)
+
+// Wyniki meczu w scenariuszach (z perspektywy gospodarza)
+const (
+	OutcomeHomeWin = "home_win"
+	OutcomeDraw    = "draw"
+	OutcomeAwayWin = "away_win"
+)
+
+// scenarioOutcomes - klasy wyników w kolejności indeksów używanych przez silnik
+var scenarioOutcomes = []string{OutcomeHomeWin, OutcomeDraw, OutcomeAwayWin}
+
+// Status miejsca w tabeli końcowej (zajęcie tego miejsca lub wyższego)
+const (
+	PositionGuaranteed = "guaranteed"
+	PositionPossible   = "possible"
+	PositionImpossible = "impossible"
+)
+
+// Domyślne limity kalkulatora scenariuszy
+const (
+	DefaultScenarioRequirements = 5
+	DefaultScenarioEvaluations  = 1000000
+)
+
+// Fixture - mecz pozostały do rozegrania
+type Fixture struct {
+	GameID     uint   `json:"game_id"`
+	Round      int    `json:"round"`
+	DateTime   string `json:"date_time"`
+	HomeTeamID uint   `json:"home_team_id"`
+	AwayTeamID uint   `json:"away_team_id"`
+}
+
+// RequiredResult - wynik meczu wymagany w zestawie wyników
+type RequiredResult struct {
+	Fixture
+	Outcome string `json:"outcome"` // OutcomeHomeWin / OutcomeDraw / OutcomeAwayWin
+}
+
+// PositionScenario - możliwości drużyny dla danego miejsca w tabeli końcowej
+type PositionScenario struct {
+	Position     int                `json:"position"`
+	Reachable    bool               `json:"reachable"`              // drużyna może skończyć dokładnie na tym miejscu
+	Status       string             `json:"status"`                 // zajęcie tego miejsca lub wyższego
+	Requirements [][]RequiredResult `json:"requirements,omitempty"` // minimalne zestawy wyników gwarantujące to miejsce lub wyższe
+}
+
+// ScenarioOptions - ustawienia kalkulatora scenariuszy
+type ScenarioOptions struct {
+	MaxMargin       int // 0 lub 1 - tylko zwycięstwo/remis/porażka (kryteria bramkowe nierozstrzygnięte); N - zwycięstwa różnicą 1..N (N:0)
+	MaxRequirements int // maksymalna liczba zestawów wyników na miejsce (domyślnie DefaultScenarioRequirements)
+	MaxEvaluations  int // limit sprawdzonych tabel końcowych (domyślnie DefaultScenarioEvaluations)
+}
+
+// ScenarioReport - wynik kalkulatora "co musi się stać" dla drużyny
+type ScenarioReport struct {
+	GroupID         uint               `json:"group_id"`
+	TeamID          uint               `json:"team_id"`
+	TeamName        string             `json:"team_name"`
+	CurrentPosition int                `json:"current_position"`
+	BestPosition    int                `json:"best_position"`
+	WorstPosition   int                `json:"worst_position"`
+	Remaining       []Fixture          `json:"remaining"`
+	MaxMargin       int                `json:"max_margin"`
+	Evaluated       int                `json:"evaluated"` // liczba sprawdzonych tabel końcowych
+	Positions       []PositionScenario `json:"positions"`
+}
+
+// AnalyzeScenarios - możliwe miejsca drużyny w tabeli końcowej i wyniki, które je gwarantują
+//
+// Przeszukuje wyniki pozostałych meczów (zwycięstwo, remis, porażka, opcjonalnie różnice bramek
+// do MaxMargin). Gdy algorytm sortuje najpierw po punktach, gałęzie są odcinane na podstawie zakresów
+// punktów, a mecze drużyn na pewno wyżej lub niżej od analizowanej nie są rozgrywane - tabela
+// końcowa jest liczona algorytmem tylko wtedy, gdy o miejscu decydują kryteria po punktach.
+// Bez różnic bramek (MaxMargin 0 lub 1) kolejność drużyn rozstrzygana kryteriami bramkowymi jest
+// nieznana - drużyna może zająć każde z tych miejsc, więc nie są one pewne.
+func AnalyzeScenarios(algorithm TableOrderAlgorithm, groupID uint, teams []models.Team, played []GameResult,
+	remaining []Fixture, points PointsRules, teamID uint, options ScenarioOptions) (*ScenarioReport, error) {
+	index := make(map[uint]int, len(teams))
+	for i, team := range teams {
+		index[team.ID] = i
+	}
+	target, ok := index[teamID]
+	if !ok {
+		return nil, fmt.Errorf("%w: drużyna ID=%d nie należy do grupy", ErrInvalidGroupData, teamID)
+	}
+	for _, fixture := range remaining {
+		_, homeOK := index[fixture.HomeTeamID]
+		_, awayOK := index[fixture.AwayTeamID]
+		if !homeOK || !awayOK || fixture.HomeTeamID == fixture.AwayTeamID {
+			return nil, fmt.Errorf("%w: mecz ID=%d - drużyny spoza grupy", ErrInvalidGroupData, fixture.GameID)
+		}
+	}
+	if options.MaxMargin < 1 {
+		options.MaxMargin = 1
+	}
+	if options.MaxRequirements < 1 {
+		options.MaxRequirements = DefaultScenarioRequirements
+	}
+	if options.MaxEvaluations < 1 {
+		options.MaxEvaluations = DefaultScenarioEvaluations
+	}
+
+	current, err := algorithm.CalculateTable(groupID, teams, played, points)
+	if err != nil {
+		return nil, err
+	}
+
+	e := newScenarioEngine(algorithm, groupID, teams, played, remaining, points, target, index, options)
+	report := &ScenarioReport{
+		GroupID:   groupID,
+		TeamID:    teamID,
+		TeamName:  teams[target].Name,
+		Remaining: e.fixtures,
+		MaxMargin: options.MaxMargin,
+		Positions: make([]PositionScenario, len(teams)),
+	}
+	for _, standing := range current.Standings {
+		if standing.TeamID == teamID {
+			report.CurrentPosition = standing.Position
+		}
+	}
+
+	reachable := make([]bool, len(teams)+1)
+	e.collect(reachable)
+	if e.err != nil {
+		return nil, e.err
+	}
+	for position := 1; position <= len(teams); position++ {
+		if !reachable[position] {
+			continue
+		}
+		if report.BestPosition == 0 {
+			report.BestPosition = position
+		}
+		report.WorstPosition = position
+	}
+
+	for i := range report.Positions {
+		position := i + 1
+		scenario := PositionScenario{Position: position, Reachable: reachable[position]}
+		switch {
+		case report.WorstPosition <= position:
+			scenario.Status = PositionGuaranteed
+		case report.BestPosition > position:
+			scenario.Status = PositionImpossible
+		default:
+			scenario.Status = PositionPossible
+			scenario.Requirements = e.requirements(position, options.MaxRequirements)
+			if e.err != nil {
+				return nil, e.err
+			}
+		}
+		report.Positions[i] = scenario
+	}
+	report.Evaluated = e.evaluated
+	return report, nil
+}
+
+// goalCriteria - kryteria zależne od liczby bramek, a nie tylko od klasy wyniku meczu
+var goalCriteria = map[string]bool{
+	CriterionH2HGoalDiff: true,
+	CriterionH2HGoalsFor: true,
+	CriterionH2HAway:     true,
+	CriterionGoalDiff:    true,
+	CriterionGoalsFor:    true,
+	CriterionAwayGoals:   true,
+}
+
+// scenarioScore - konkretny wynik pozostałego meczu
+type scenarioScore struct {
+	home, away int
+	outcome    int // indeks w scenarioOutcomes
+}
+
+// scenarioEngine - przeszukiwanie wyników pozostałych meczów z odcinaniem po zakresach punktów
+type scenarioEngine struct {
+	algorithm TableOrderAlgorithm
+	groupID   uint
+	teams     []models.Team
+	points    PointsRules
+	target    int  // indeks analizowanej drużyny
+	byPoints  bool // algorytm sortuje najpierw po punktach - zakresy punktów ograniczają miejsca
+	minGain   int  // najmniej i najwięcej punktów za jeden mecz
+	maxGain   int
+	decisive  map[string]bool // bez różnic bramek: kryteria rozstrzygające niezależnie od bramek (nil - wszystkie)
+
+	fixtures []Fixture // mecze analizowanej drużyny, potem rywali, potem pozostałe
+	home     []int     // indeksy drużyn meczów
+	away     []int
+	domain   [][]scenarioScore
+
+	// Stan przeszukiwania
+	pts         []int  // punkty po zakończonych meczach i wybranych wynikach
+	left        []int  // liczba meczów drużyny bez wybranego wyniku
+	assigned    []bool // mecz ma wybrany wynik
+	chosen      []int  // wybrany wynik meczu (indeks w domain)
+	constraints []int  // wymagana klasa wyniku meczu (-1 = dowolna)
+	games       []GameResult
+	played      int // liczba zakończonych meczów na początku games
+
+	cache     map[string][2]int // zakres miejsc analizowanej drużyny dla wyników meczów drużyn z równą liczbą punktów
+	evaluated int
+	limit     int
+	err       error
+}
+
+// newScenarioEngine - przygotowuje punkty po zakończonych meczach, kolejność i możliwe wyniki pozostałych meczów
+func newScenarioEngine(algorithm TableOrderAlgorithm, groupID uint, teams []models.Team, played []GameResult,
+	remaining []Fixture, points PointsRules, target int, index map[uint]int, options ScenarioOptions) *scenarioEngine {
+	criteria := algorithm.Criteria()
+	e := &scenarioEngine{
+		algorithm: algorithm,
+		groupID:   groupID,
+		teams:     teams,
+		points:    points,
+		target:    target,
+		byPoints:  len(criteria) > 0 && criteria[0] == CriterionPoints,
+		minGain:   points.Win,
+		maxGain:   points.Win,
+		pts:       make([]int, len(teams)),
+		left:      make([]int, len(teams)),
+		games:     append(make([]GameResult, 0, len(played)+len(remaining)), played...),
+		played:    len(played),
+		cache:     map[string][2]int{},
+		limit:     options.MaxEvaluations,
+	}
+	if options.MaxMargin == 1 {
+		// Zwycięstwa liczone jako 1:0 - rozstrzygają tylko kryteria przed pierwszym kryterium bramkowym
+		e.decisive = map[string]bool{}
+		for _, criterion := range criteria {
+			if goalCriteria[criterion] {
+				break
+			}
+			e.decisive[criterion] = true
+		}
+	}
+	for _, earned := range []int{points.Draw, points.Loss} {
+		if earned > e.maxGain {
+			e.maxGain = earned
+		}
+		if earned < e.minGain {
+			e.minGain = earned
+		}
+	}
+	for i, standing := range ComputeStandings(teams, played, points) {
+		e.pts[i] = standing.Points
+	}
+	for _, fixture := range remaining {
+		e.left[index[fixture.HomeTeamID]]++
+		e.left[index[fixture.AwayTeamID]]++
+	}
+
+	// Kolejność meczów w zestawach wyników: analizowana drużyna, mecze rywali, pozostałe
+	relevance := func(fixture Fixture) int {
+		home, away := index[fixture.HomeTeamID], index[fixture.AwayTeamID]
+		switch {
+		case home == target || away == target:
+			return 0
+		case e.undecided(home) && e.undecided(away):
+			return 1
+		case e.undecided(home) || e.undecided(away):
+			return 2
+		}
+		return 3
+	}
+	e.fixtures = append([]Fixture{}, remaining...)
+	sort.SliceStable(e.fixtures, func(i, j int) bool {
+		if a, b := relevance(e.fixtures[i]), relevance(e.fixtures[j]); a != b {
+			return a < b
+		}
+		if e.fixtures[i].Round != e.fixtures[j].Round {
+			return e.fixtures[i].Round < e.fixtures[j].Round
+		}
+		return e.fixtures[i].GameID < e.fixtures[j].GameID
+	})
+
+	count := len(e.fixtures)
+	e.home = make([]int, count)
+	e.away = make([]int, count)
+	e.domain = make([][]scenarioScore, count)
+	e.assigned = make([]bool, count)
+	e.chosen = make([]int, count)
+	e.constraints = make([]int, count)
+	for f, fixture := range e.fixtures {
+		e.home[f] = index[fixture.HomeTeamID]
+		e.away[f] = index[fixture.AwayTeamID]
+		e.constraints[f] = -1
+
+		scores := []scenarioScore{}
+		for margin := 1; margin <= options.MaxMargin; margin++ {
+			scores = append(scores, scenarioScore{home: margin, outcome: 0})
+		}
+		scores = append(scores, scenarioScore{outcome: 1})
+		for margin := 1; margin <= options.MaxMargin; margin++ {
+			scores = append(scores, scenarioScore{away: margin, outcome: 2})
+		}
+		e.domain[f] = scores
+	}
+	return e
+}
+
+// low, high - zakres punktów drużyny na koniec przy obecnie wybranych wynikach
+func (e *scenarioEngine) low(team int) int  { return e.pts[team] + e.left[team]*e.minGain }
+func (e *scenarioEngine) high(team int) int { return e.pts[team] + e.left[team]*e.maxGain }
+
+// undecided - czy wynik meczów drużyny może zmienić jej kolejność względem analizowanej drużyny
+func (e *scenarioEngine) undecided(team int) bool {
+	if !e.byPoints || team == e.target {
+		return true
+	}
+	return e.low(team) <= e.high(e.target) && e.high(team) >= e.low(e.target)
+}
+
+// bounds - zakres miejsc analizowanej drużyny przy obecnie wybranych wynikach
+func (e *scenarioEngine) bounds() (int, int) {
+	if !e.byPoints {
+		return 1, len(e.teams)
+	}
+	above, below := 0, 0
+	for team := range e.teams {
+		switch {
+		case team == e.target:
+		case e.low(team) > e.high(e.target):
+			above++
+		case e.high(team) < e.low(e.target):
+			below++
+		}
+	}
+	return above + 1, len(e.teams) - below
+}
+
+// earned - punkty gospodarza i gościa za wynik
+func (e *scenarioEngine) earned(score scenarioScore) (int, int) {
+	switch score.outcome {
+	case 0:
+		return e.points.Win, e.points.Loss
+	case 2:
+		return e.points.Loss, e.points.Win
+	}
+	return e.points.Draw, e.points.Draw
+}
+
+// assign - wybiera wynik meczu f (sign = -1 cofa wybór)
+func (e *scenarioEngine) assign(f, choice, sign int) {
+	home, away := e.earned(e.domain[f][choice])
+	e.pts[e.home[f]] += sign * home
+	e.pts[e.away[f]] += sign * away
+	e.left[e.home[f]] -= sign
+	e.left[e.away[f]] -= sign
+	e.assigned[f] = sign > 0
+	e.chosen[f] = choice
+}
+
+// next - kolejny mecz do rozstrzygnięcia (-1 gdy pozostałe mecze nie wpływają na miejsce analizowanej drużyny)
+//
+// Mecze drużyn na pewno wyżej lub niżej od analizowanej są pomijane. Najpierw wybierane są mecze
+// analizowanej drużyny, potem mecze dwóch rywali, a wśród nich mecze rywala z najmniejszą liczbą
+// pozostałych meczów - jego punkty najszybciej przestają być niewiadomą.
+func (e *scenarioEngine) next() int {
+	best, bestRank, bestLeft := -1, 0, 0
+	for f := range e.fixtures {
+		if e.assigned[f] {
+			continue
+		}
+		home, away := e.home[f], e.away[f]
+		homeOpen, awayOpen := e.undecided(home), e.undecided(away)
+		if !homeOpen && !awayOpen {
+			continue
+		}
+		rank := 2
+		switch {
+		case e.constraints[f] >= 0:
+			rank = -1
+		case home == e.target || away == e.target:
+			rank = 0
+		case homeOpen && awayOpen:
+			rank = 1
+		}
+		left := len(e.fixtures)
+		if homeOpen {
+			left = e.left[home]
+		}
+		if awayOpen && e.left[away] < left {
+			left = e.left[away]
+		}
+		if best < 0 || rank < bestRank || (rank == bestRank && left < bestLeft) {
+			best, bestRank, bestLeft = f, rank, left
+		}
+	}
+	return best
+}
+
+// choices - kolejność klas wyników meczu f (wynik z różnicą jednej bramki reprezentuje klasę);
+// direction > 0 - najpierw korzystne dla analizowanej drużyny,
+// direction < 0 - najpierw niekorzystne
+func (e *scenarioEngine) choices(f, direction int) []int {
+	order := make([]int, 0, len(scenarioOutcomes))
+	for choice, score := range e.domain[f] {
+		if (e.constraints[f] < 0 || score.outcome == e.constraints[f]) && (choice == 0 || e.domain[f][choice-1].outcome != score.outcome) {
+			order = append(order, choice)
+		}
+	}
+	if direction == 0 {
+		return order
+	}
+
+	// Korzyść: punkty analizowanej drużyny albo odebrane punkty wyżej notowanemu rywalowi
+	benefit := func(choice int) int {
+		home, away := e.earned(e.domain[f][choice])
+		switch {
+		case e.home[f] == e.target:
+			return home - away
+		case e.away[f] == e.target:
+			return away - home
+		case e.pts[e.home[f]] >= e.pts[e.away[f]]:
+			return away - home
+		}
+		return home - away
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return direction*benefit(order[i]) > direction*benefit(order[j])
+	})
+	return order
+}
+
+// unresolved - czy kolejność sąsiednich drużyn zależy od nieznanych różnic bramek
+func (e *scenarioEngine) unresolved(reason *TiebreakReason) bool {
+	return e.decisive != nil && (reason == nil || !e.decisive[reason.Criterion])
+}
+
+// evaluate - zakres miejsc analizowanej drużyny, gdy pozostałe mecze nie mają już wpływu na wynik
+//
+// Bez różnic bramek zakres obejmuje sąsiednie drużyny, od których dzielą ją tylko kryteria bramkowe.
+func (e *scenarioEngine) evaluate() (int, int) {
+	if lo, hi := e.bounds(); lo == hi {
+		return lo, hi
+	}
+
+	// Mecze bez wpływu na wynik - dowolny dozwolony wynik
+	choices := make([]int, len(e.fixtures))
+	for f := range e.fixtures {
+		choices[f] = e.chosen[f]
+		if !e.assigned[f] {
+			choices[f] = e.choices(f, 0)[0]
+		}
+	}
+
+	// Miejsce zależy od liczby drużyn z większą liczbą punktów i kolejności drużyn z równą liczbą
+	// punktów, a ta tylko od meczów tych drużyn - pozostałe mecze nie wchodzą do klucza
+	lo, _ := e.bounds()
+	key := []byte{byte(lo)}
+	for team := range e.teams {
+		if e.undecided(team) {
+			key = append(key, byte(team))
+		}
+	}
+	for f := range e.fixtures {
+		choice := byte(255)
+		if e.undecided(e.home[f]) || e.undecided(e.away[f]) {
+			choice = byte(choices[f])
+		}
+		key = append(key, choice)
+	}
+	e.evaluated++
+	if e.evaluated > e.limit {
+		e.err = fmt.Errorf("%w: przekroczono limit %d tabel", ErrTooManyScenarios, e.limit)
+		return 0, 0
+	}
+	if positions, ok := e.cache[string(key)]; ok {
+		return positions[0], positions[1]
+	}
+
+	e.games = e.games[:e.played]
+	for f, fixture := range e.fixtures {
+		score := e.domain[f][choices[f]]
+		e.games = append(e.games, GameResult{
+			GameID:     fixture.GameID,
+			Round:      fixture.Round,
+			DateTime:   fixture.DateTime,
+			HomeTeamID: fixture.HomeTeamID,
+			AwayTeamID: fixture.AwayTeamID,
+			HomeGoals:  score.home,
+			AwayGoals:  score.away,
+		})
+	}
+	table, err := e.algorithm.CalculateTable(e.groupID, e.teams, e.games, e.points)
+	if err != nil {
+		e.err = err
+		return 0, 0
+	}
+	standings := table.Standings
+	for i, standing := range standings {
+		if standing.TeamID != e.teams[e.target].ID {
+			continue
+		}
+		first, last := i, i
+		for first > 0 && e.unresolved(standings[first-1].Tiebreak) {
+			first--
+		}
+		for last < len(standings)-1 && e.unresolved(standings[last].Tiebreak) {
+			last++
+		}
+		positions := [2]int{standings[first].Position, standings[last].Position}
+		e.cache[string(key)] = positions
+		return positions[0], positions[1]
+	}
+	e.err = fmt.Errorf("%w: brak drużyny w tabeli", ErrCalculationFailed)
+	return 0, 0
+}
+
+// leaf - przekazuje do visit miejsca analizowanej drużyny dla wszystkich różnic bramek w meczach drużyn
+// z równą liczbą punktów (pozostałe mecze nie mają wpływu na miejsce); visit zwraca false, by przerwać
+func (e *scenarioEngine) leaf(visit func(position int) bool) {
+	vary := []int{}
+	if lo, hi := e.bounds(); lo < hi {
+		for f := range e.fixtures {
+			if e.assigned[f] && e.domain[f][e.chosen[f]].outcome != 1 && (e.undecided(e.home[f]) || e.undecided(e.away[f])) {
+				vary = append(vary, f)
+			}
+		}
+	}
+
+	var walk func(i int) bool
+	walk = func(i int) bool {
+		if i == len(vary) {
+			first, last := e.evaluate()
+			for position := first; position <= last && e.err == nil; position++ {
+				if !visit(position) {
+					return false
+				}
+			}
+			return e.err == nil
+		}
+		f := vary[i]
+		saved := e.chosen[f]
+		defer func() { e.chosen[f] = saved }()
+		for choice, score := range e.domain[f] {
+			if score.outcome != e.domain[f][saved].outcome {
+				continue
+			}
+			e.chosen[f] = choice
+			if !walk(i + 1) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(0)
+}
+
+// collect - oznacza miejsca możliwe do zajęcia (gałęzie bez nowych miejsc są pomijane)
+func (e *scenarioEngine) collect(reachable []bool) {
+	if e.err != nil {
+		return
+	}
+	lo, hi := e.bounds()
+	known := true
+	for position := lo; position <= hi; position++ {
+		known = known && reachable[position]
+	}
+	if known {
+		return
+	}
+
+	f := e.next()
+	if lo == hi || f < 0 {
+		e.leaf(func(position int) bool {
+			reachable[position] = true
+			for position := lo; position <= hi; position++ {
+				if !reachable[position] {
+					return true
+				}
+			}
+			return false
+		})
+		return
+	}
+	for _, choice := range e.choices(f, 0) {
+		e.assign(f, choice, 1)
+		e.collect(reachable)
+		e.assign(f, choice, -1)
+	}
+}
+
+// exists - czy przy wymaganych klasach wyników (constraints) drużyna może zająć miejsce spełniające match
+func (e *scenarioEngine) exists(match func(position int) bool, direction int) bool {
+	if e.err != nil {
+		return false
+	}
+	lo, hi := e.bounds()
+	any, all := false, true
+	for position := lo; position <= hi; position++ {
+		ok := match(position)
+		any = any || ok
+		all = all && ok
+	}
+	switch {
+	case !any:
+		return false
+	case all:
+		// wymagane klasy wyników tylko zawężają zakres - dowolne dokończenie spełnia match
+		return true
+	}
+
+	f := e.next()
+	if f < 0 {
+		found := false
+		e.leaf(func(position int) bool {
+			found = match(position)
+			return !found
+		})
+		return found && e.err == nil
+	}
+	for _, choice := range e.choices(f, direction) {
+		e.assign(f, choice, 1)
+		found := e.exists(match, direction)
+		e.assign(f, choice, -1)
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// matters - czy wynik meczu f może wpłynąć na miejsce analizowanej drużyny przy wymaganych klasach wyników
+func (e *scenarioEngine) matters(f int) bool {
+	fixed := []int{}
+	for other := range e.fixtures {
+		if other != f && !e.assigned[other] && e.constraints[other] >= 0 {
+			choice := e.choices(other, 0)[0]
+			e.assign(other, choice, 1)
+			fixed = append(fixed, other)
+		}
+	}
+	matters := e.undecided(e.home[f]) || e.undecided(e.away[f])
+	for _, other := range fixed {
+		e.assign(other, e.chosen[other], -1)
+	}
+	return matters
+}
+
+// requirements - minimalne zestawy wyników gwarantujące miejsce position lub wyższe
+//
+// Zestawy są szukane w kolejności meczów (najpierw mecze analizowanej drużyny), a następnie
+// pomniejszane o wyniki, bez których gwarancja nadal obowiązuje.
+func (e *scenarioEngine) requirements(position, limit int) [][]RequiredResult {
+	worse := func(p int) bool { return p > position }
+	better := func(p int) bool { return p <= position }
+	sufficient := func() bool { return !e.exists(worse, -1) }
+
+	found := [][]int{}
+	var walk func(f int)
+	walk = func(f int) {
+		if e.err != nil || len(found) >= limit*4 {
+			return
+		}
+		if sufficient() {
+			found = append(found, append([]int{}, e.constraints...))
+			return
+		}
+		if f == len(e.fixtures) || !e.exists(better, 1) {
+			return
+		}
+		if !e.matters(f) {
+			walk(f + 1)
+			return
+		}
+		for outcome := range scenarioOutcomes {
+			e.constraints[f] = outcome
+			walk(f + 1)
+		}
+		e.constraints[f] = -1
+	}
+	walk(0)
+
+	// Pomniejszenie zestawów i usunięcie powtórzeń
+	seen := map[string]bool{}
+	minimal := [][]int{}
+	for _, set := range found {
+		copy(e.constraints, set)
+		for f := range set {
+			if set[f] < 0 || e.err != nil {
+				continue
+			}
+			e.constraints[f] = -1
+			if !sufficient() {
+				e.constraints[f] = set[f]
+			}
+		}
+		set = append([]int{}, e.constraints...)
+		if key := fmt.Sprint(set); !seen[key] {
+			seen[key] = true
+			minimal = append(minimal, set)
+		}
+	}
+	for f := range e.constraints {
+		e.constraints[f] = -1
+	}
+	if e.err != nil {
+		return nil
+	}
+
+	size := func(set []int) int {
+		count := 0
+		for _, outcome := range set {
+			if outcome >= 0 {
+				count++
+			}
+		}
+		return count
+	}
+	sort.SliceStable(minimal, func(i, j int) bool { return size(minimal[i]) < size(minimal[j]) })
+
+	sets := [][]RequiredResult{}
+	for i, set := range minimal {
+		if len(sets) == limit {
+			break
+		}
+		if containsSet(set, minimal[:i]) {
+			continue
+		}
+		results := []RequiredResult{}
+		for f, outcome := range set {
+			if outcome >= 0 {
+				results = append(results, RequiredResult{Fixture: e.fixtures[f], Outcome: scenarioOutcomes[outcome]})
+			}
+		}
+		sets = append(sets, results)
+	}
+	return sets
+}
+
+// containsSet - czy zestaw zawiera w sobie jeden z wcześniejszych (nie większych) zestawów
+func containsSet(set []int, earlier [][]int) bool {
+	for _, other := range earlier {
+		contained := true
+		for f, outcome := range other {
+			if outcome >= 0 && set[f] != outcome {
+				contained = false
+				break
+			}
+		}
+		if contained {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package tables
+
+import (
+	"testing"
+	"time"
+)
+
+// roundRobin - terminarz każdy z każdym (metoda kołowa) dla parzystej liczby drużyn
+func roundRobin(teams int) []Fixture {
+	fixtures := []Fixture{}
+	rotation := make([]uint, teams)
+	for i := range rotation {
+		rotation[i] = uint(i + 1)
+	}
+	for round := 1; round < teams; round++ {
+		for i := 0; i < teams/2; i++ {
+			fixtures = append(fixtures, Fixture{
+				GameID:     uint(len(fixtures) + 1),
+				Round:      round,
+				HomeTeamID: rotation[i],
+				AwayTeamID: rotation[teams-1-i],
+			})
+		}
+		last := rotation[teams-1]
+		copy(rotation[2:], rotation[1:teams-1])
+		rotation[1] = last
+	}
+	return fixtures
+}
+
+// playFixtures - wyniki rozegranych meczów (deterministyczne, zależne od ID meczu)
+func playFixtures(fixtures []Fixture) []GameResult {
+	games := make([]GameResult, len(fixtures))
+	for i, fixture := range fixtures {
+		games[i] = GameResult{
+			GameID:     fixture.GameID,
+			Round:      fixture.Round,
+			HomeTeamID: fixture.HomeTeamID,
+			AwayTeamID: fixture.AwayTeamID,
+			HomeGoals:  int(fixture.GameID*7+fixture.HomeTeamID) % 4,
+			AwayGoals:  int(fixture.GameID*3+fixture.AwayTeamID) % 3,
+		}
+	}
+	return games
+}
+
+// bruteForcePositions - miejsca drużyny we wszystkich kombinacjach wyników (k:0, 0:0, 0:k dla k do maxMargin)
+// spełniających required; przy maxMargin 1 także miejsca sąsiadów, od których dzielą ją tylko kryteria bramkowe
+func bruteForcePositions(t *testing.T, algorithm TableOrderAlgorithm, teams, maxMargin int, played []GameResult, remaining []Fixture, teamID uint, required []RequiredResult) map[int]bool {
+	t.Helper()
+	scores := [][2]int{{0, 0}}
+	classes := []string{OutcomeDraw}
+	for margin := 1; margin <= maxMargin; margin++ {
+		scores = append(scores, [2]int{margin, 0}, [2]int{0, margin})
+		classes = append(classes, OutcomeHomeWin, OutcomeAwayWin)
+	}
+	positions := map[int]bool{}
+	outcomes := make([]int, len(remaining))
+	for {
+		games := append([]GameResult{}, played...)
+		allowed := true
+		for i, fixture := range remaining {
+			for _, result := range required {
+				if result.GameID == fixture.GameID && result.Outcome != classes[outcomes[i]] {
+					allowed = false
+				}
+			}
+			games = append(games, GameResult{
+				GameID: fixture.GameID, Round: fixture.Round,
+				HomeTeamID: fixture.HomeTeamID, AwayTeamID: fixture.AwayTeamID,
+				HomeGoals: scores[outcomes[i]][0], AwayGoals: scores[outcomes[i]][1],
+			})
+		}
+		if allowed {
+			table, err := algorithm.CalculateTable(1, testTeams(teams), games, DefaultPointsRules())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, standing := range table.Standings {
+				if standing.TeamID != teamID {
+					continue
+				}
+				positions[standing.Position] = true
+				for j := i - 1; maxMargin == 1 && j >= 0 && goalDecided(algorithm, table.Standings[j].Tiebreak); j-- {
+					positions[table.Standings[j].Position] = true
+				}
+				for j := i; maxMargin == 1 && j < len(table.Standings)-1 && goalDecided(algorithm, table.Standings[j].Tiebreak); j++ {
+					positions[table.Standings[j+1].Position] = true
+				}
+			}
+		}
+
+		i := 0
+		for i < len(outcomes) && outcomes[i] == len(scores)-1 {
+			outcomes[i] = 0
+			i++
+		}
+		if i == len(outcomes) {
+			return positions
+		}
+		outcomes[i]++
+	}
+}
+
+// goalDecided - czy kolejność sąsiednich drużyn rozstrzygnęło kryterium bramkowe lub kryterium po nim
+func goalDecided(algorithm TableOrderAlgorithm, reason *TiebreakReason) bool {
+	for _, criterion := range algorithm.Criteria() {
+		switch {
+		case goalCriteria[criterion]:
+			return true
+		case criterion == reason.Criterion:
+			return false
+		}
+	}
+	return true
+}
+
+func TestAnalyzeScenariosMatchesBruteForce(t *testing.T) {
+	for _, check := range []struct{ teams, maxMargin int }{{4, 1}, {6, 1}, {4, 2}} {
+		fixtures := roundRobin(check.teams)
+		remaining := fixtures[len(fixtures)-check.teams:] // dwie ostatnie kolejki
+		played := playFixtures(fixtures[:len(fixtures)-check.teams])
+
+		for _, algorithm := range []TableOrderAlgorithm{&StandardAlgorithm{}, &MZPNAlgorithm{}} {
+			for teamID := uint(1); teamID <= uint(check.teams); teamID++ {
+				checkAgainstBruteForce(t, algorithm, check.teams, check.maxMargin, played, remaining, teamID)
+			}
+		}
+	}
+}
+
+// checkAgainstBruteForce - miejsca, statusy i zestawy wyników zgodne z pełnym przeglądem wyników
+func checkAgainstBruteForce(t *testing.T, algorithm TableOrderAlgorithm, teams, maxMargin int, played []GameResult, remaining []Fixture, teamID uint) {
+	t.Helper()
+	options := ScenarioOptions{MaxMargin: maxMargin}
+	report, err := AnalyzeScenarios(algorithm, 1, testTeams(teams), played, remaining, DefaultPointsRules(), teamID, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bruteForcePositions(t, algorithm, teams, maxMargin, played, remaining, teamID, nil)
+	// Miejsca osiągalne przy większych różnicach bramek nie mogą być pominięte ani uznane za pewne
+	margins := want
+	if maxMargin == 1 {
+		margins = bruteForcePositions(t, algorithm, teams, 2, played, remaining, teamID, nil)
+	}
+	for _, scenario := range report.Positions {
+		if scenario.Reachable != want[scenario.Position] {
+			t.Fatalf("%s, drużyna ID=%d, miejsce %d: osiągalne = %v, oczekiwano %v",
+				algorithm.GetName(), teamID, scenario.Position, scenario.Reachable, want[scenario.Position])
+		}
+		if margins[scenario.Position] && !scenario.Reachable {
+			t.Fatalf("%s, drużyna ID=%d: miejsce %d osiągalne przy różnicy 2 bramek", algorithm.GetName(), teamID, scenario.Position)
+		}
+		if scenario.Status == PositionGuaranteed && worstOf(margins) > scenario.Position {
+			t.Fatalf("%s, drużyna ID=%d: miejsce %d nie jest pewne (możliwe %d)",
+				algorithm.GetName(), teamID, scenario.Position, worstOf(margins))
+		}
+		if scenario.Status == PositionPossible && len(scenario.Requirements) == 0 {
+			t.Fatalf("drużyna ID=%d, miejsce %d: brak zestawów wyników", teamID, scenario.Position)
+		}
+		if scenario.Status != PositionPossible && len(scenario.Requirements) > 0 {
+			t.Fatalf("drużyna ID=%d, miejsce %d: zestawy wyników dla statusu %s", teamID, scenario.Position, scenario.Status)
+		}
+
+		for _, set := range scenario.Requirements {
+			// Zestaw gwarantuje miejsce, a bez dowolnego wyniku gwarancja znika
+			if worst := worstOf(bruteForcePositions(t, algorithm, teams, maxMargin, played, remaining, teamID, set)); worst > scenario.Position {
+				t.Fatalf("drużyna ID=%d: zestaw %+v nie gwarantuje miejsca %d (możliwe %d)", teamID, set, scenario.Position, worst)
+			}
+			for skip := range set {
+				reduced := append(append([]RequiredResult{}, set[:skip]...), set[skip+1:]...)
+				if worstOf(bruteForcePositions(t, algorithm, teams, maxMargin, played, remaining, teamID, reduced)) <= scenario.Position {
+					t.Fatalf("drużyna ID=%d: zestaw %+v nie jest minimalny", teamID, set)
+				}
+			}
+		}
+	}
+}
+
+// worstOf - najniższe miejsce ze zbioru
+func worstOf(positions map[int]bool) int {
+	worst := 0
+	for position := range positions {
+		if position > worst {
+			worst = position
+		}
+	}
+	return worst
+}
+
+func TestAnalyzeScenariosStatuses(t *testing.T) {
+	var played []GameResult
+	result(&played, 1, 2, 2, 0)
+	result(&played, 3, 4, 1, 1)
+	result(&played, 1, 3, 2, 0)
+	result(&played, 2, 4, 1, 0)
+	remaining := []Fixture{
+		{GameID: 5, Round: 3, HomeTeamID: 1, AwayTeamID: 4},
+		{GameID: 6, Round: 3, HomeTeamID: 2, AwayTeamID: 3},
+	}
+
+	// A: 6 pkt, B: 3 pkt - gdy A przegra z D, a B wygra z C, o 1. miejscu decyduje bilans bramek,
+	// więc bez różnic bramek 1. miejsce nie jest pewne
+	report, err := AnalyzeScenarios(&StandardAlgorithm{}, 1, testTeams(4), played, remaining, DefaultPointsRules(), 1, ScenarioOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.BestPosition != 1 || report.WorstPosition != 2 || report.CurrentPosition != 1 {
+		t.Fatalf("A: miejsca %d-%d, oczekiwano 1-2", report.BestPosition, report.WorstPosition)
+	}
+	if first := report.Positions[0]; first.Status != PositionPossible || len(first.Requirements) == 0 {
+		t.Fatalf("A: 1. miejsce %+v", first)
+	}
+	if report.Positions[1].Status != PositionGuaranteed {
+		t.Fatalf("A: 2. miejsce ma status %s", report.Positions[1].Status)
+	}
+
+	// Z różnicami bramek: A przegrywa 0:5, B wygrywa 5:0 i wyprzedza A
+	report, err = AnalyzeScenarios(&StandardAlgorithm{}, 1, testTeams(4), played, remaining, DefaultPointsRules(), 1, ScenarioOptions{MaxMargin: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.WorstPosition != 2 || report.Positions[0].Status != PositionPossible {
+		t.Fatalf("A (różnice do 5): miejsca %d-%d, statusy %+v", report.BestPosition, report.WorstPosition, report.Positions)
+	}
+
+	// D: 1 pkt - nie wyprzedzi A, o 3. miejscu decydują wyniki ostatniej kolejki
+	report, err = AnalyzeScenarios(&StandardAlgorithm{}, 1, testTeams(4), played, remaining, DefaultPointsRules(), 4, ScenarioOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Positions[0].Status != PositionImpossible || report.Positions[3].Status != PositionGuaranteed {
+		t.Fatalf("D: statusy %+v", report.Positions)
+	}
+	if third := report.Positions[2]; third.Status != PositionPossible || len(third.Requirements) == 0 {
+		t.Fatalf("D: 3. miejsce %+v", third)
+	}
+
+	if _, err := AnalyzeScenarios(&StandardAlgorithm{}, 1, testTeams(4), played, remaining, DefaultPointsRules(), 9, ScenarioOptions{}); err == nil {
+		t.Fatal("oczekiwano błędu dla drużyny spoza grupy")
+	}
+}
+
+func TestAnalyzeScenariosLastRoundsOfTwelveTeamLeague(t *testing.T) {
+	fixtures := roundRobin(12)
+	played := playFixtures(fixtures[:7*6])
+	remaining := fixtures[7*6:] // 4 kolejki, 24 mecze
+	teams := testTeams(12)
+
+	started := time.Now()
+	for _, teamID := range []uint{1, 6, 12} {
+		report, err := AnalyzeScenarios(&MZPNAlgorithm{}, 1, teams, played, remaining, DefaultPointsRules(), teamID, ScenarioOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.BestPosition < 1 || report.WorstPosition < report.BestPosition || report.WorstPosition > 12 {
+			t.Fatalf("drużyna ID=%d: miejsca %d-%d", teamID, report.BestPosition, report.WorstPosition)
+		}
+		t.Logf("drużyna %s: miejsca %d-%d, %d tabel", report.TeamName, report.BestPosition, report.WorstPosition, report.Evaluated)
+	}
+	if elapsed := time.Since(started); elapsed > 30*time.Second {
+		t.Fatalf("analiza trwała %v", elapsed)
+	}
+}
@@ -0,0 +1,113 @@
+package tables
+
+import (
+	"recorder-server/internal/models"
+	"sort"
+)
+
+// FormLength - liczba ostatnich meczów w polu Form
+const FormLength = 5
+
+// SortResults - sortuje wyniki chronologicznie (data, kolejka, ID meczu)
+func SortResults(games []GameResult) {
+	sort.SliceStable(games, func(i, j int) bool {
+		if games[i].DateTime != games[j].DateTime && games[i].DateTime != "" && games[j].DateTime != "" {
+			return games[i].DateTime < games[j].DateTime
+		}
+		if games[i].Round != games[j].Round {
+			return games[i].Round < games[j].Round
+		}
+		return games[i].GameID < games[j].GameID
+	})
+}
+
+// ComputeStandings - zlicza mecze, wyniki, bramki, punkty i formę drużyn (bez sortowania)
+//
+// Mecze z drużynami spoza listy są pomijane; kolejność drużyn w wyniku odpowiada kolejności teams.
+func ComputeStandings(teams []models.Team, games []GameResult, points PointsRules) []TeamStanding {
+	standings := make([]TeamStanding, len(teams))
+	index := make(map[uint]int, len(teams))
+	for i := range teams {
+		standings[i] = TeamStanding{Team: &teams[i], TeamID: teams[i].ID}
+		index[teams[i].ID] = i
+	}
+
+	sorted := append([]GameResult{}, games...)
+	SortResults(sorted)
+
+	for _, game := range sorted {
+		home, homeOK := index[game.HomeTeamID]
+		away, awayOK := index[game.AwayTeamID]
+		if !homeOK || !awayOK {
+			continue
+		}
+		addResult(&standings[home], game.HomeGoals, game.AwayGoals, game.HomeDSQ, game.AwayDSQ, points)
+		addResult(&standings[away], game.AwayGoals, game.HomeGoals, game.AwayDSQ, game.HomeDSQ, points)
+	}
+	return standings
+}
+
+// matchOutcome - wynik meczu z perspektywy drużyny ("W", "D", "L") i zdobyte punkty
+//
+// Dyskwalifikacja (walkower) rozstrzyga mecz niezależnie od zapisanego wyniku - zdyskwalifikowana
+// drużyna dostaje punkty za walkower, a jej przeciwnik punkty za zwycięstwo.
+func matchOutcome(goalsFor, goalsAgainst int, dsq, opponentDSQ bool, points PointsRules) (string, int) {
+	switch {
+	case dsq:
+		return "L", points.Walkover
+	case opponentDSQ:
+		return "W", points.Win
+	case goalsFor > goalsAgainst:
+		return "W", points.Win
+	case goalsFor < goalsAgainst:
+		return "L", points.Loss
+	}
+	return "D", points.Draw
+}
+
+// addResult - dopisuje wynik meczu do statystyk drużyny
+func addResult(standing *TeamStanding, goalsFor, goalsAgainst int, dsq, opponentDSQ bool, points PointsRules) {
+	standing.Played++
+	standing.GoalsFor += goalsFor
+	standing.GoalsAgainst += goalsAgainst
+	standing.GoalDifference = standing.GoalsFor - standing.GoalsAgainst
+
+	result, earned := matchOutcome(goalsFor, goalsAgainst, dsq, opponentDSQ, points)
+	standing.Points += earned
+	switch result {
+	case "W":
+		standing.Won++
+	case "L":
+		standing.Lost++
+	default:
+		standing.Drawn++
+	}
+
+	standing.Form += result
+	if len(standing.Form) > FormLength {
+		standing.Form = standing.Form[len(standing.Form)-FormLength:]
+	}
+}
+
+// GamesBetween - mecze rozegrane wyłącznie między podanymi drużynami
+func GamesBetween(games []GameResult, teamIDs ...uint) []GameResult {
+	set := make(map[uint]bool, len(teamIDs))
+	for _, id := range teamIDs {
+		set[id] = true
+	}
+
+	result := []GameResult{}
+	for _, game := range games {
+		if set[game.HomeTeamID] && set[game.AwayTeamID] {
+			result = append(result, game)
+		}
+	}
+	return result
+}
+
+// assignPositions - numeruje pozycje po posortowaniu
+func assignPositions(standings []TeamStanding) {
+	for i := range standings {
+		standings[i].Position = i + 1
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"recorder-server/config"
+	"recorder-server/internal/auth"
 	"recorder-server/internal/database"
 	"recorder-server/internal/handlers"
 	"recorder-server/internal/models"
@@ -78,11 +79,18 @@ func main() {
 	tables.RegisterDefaultAlgorithms()
 
 	// Inicjalizacja stanu aplikacji
-	appState := state.NewAppState(cfg.Recording.AllCameras)
+	appState := state.NewAppState()
 	log.Println("Stan aplikacji zainicjalizowany")
 
+	// Lista kamer z bazy aktualnych rozgrywek
+	cameraService := services.NewCameraService(dbManager, appState)
+	cameraService.RefreshAppState()
+
+	// Uwierzytelnianie tokenami API (auth_config.json)
+	authenticator := auth.NewAuthenticator()
+
 	// Inicjalizacja serwisu Socket.IO
-	socketService := services.NewSocketIOService(appState)
+	socketService := services.NewSocketIOService(appState, authenticator)
 	log.Println("Socket.IO serwis zainicjalizowany")
 
 	// Inicjalizacja serwisu stopera
@@ -92,23 +100,57 @@ func main() {
 	// Inicjalizacja klienta OBS WebSocket
 	obsClient := services.NewOBSClient(cfg.OBS.URL, cfg.OBS.Password)
 	go obsClient.Connect()
-	setupOBSEventHandlers(obsClient)
+	setupOBSEventHandlers(obsClient, appState)
 	log.Println("OBS WebSocket klient zainicjalizowany")
 
+	// Snapshot stanu wysyłany klientom Socket.IO po połączeniu
+	socketService.SetSnapshotService(services.NewSnapshotService(dbManager, appState, cameraService, timerService, obsClient))
+
 	// ===== Inicjalizacja serwisów =====
 	scraperService := services.NewScraperService(dbManager)
-	// tableService := services.NewTableService(dbManager)
+	recordingService := services.NewRecordingService(dbManager)
+	socketService.SetRecordingService(recordingService)
+	footageService := services.NewFootageService(dbManager, socketService)
+	eventService := services.NewEventService(dbManager, appState, timerService, socketService)
+	scoringService := services.NewScoringService(dbManager, socketService)
+	eventService.AddListener(scoringService.HandleEvent) // bramki z wydarzeń aktualizują wynik
+	valueRuleService := services.NewValueRuleService(dbManager, socketService)
+	scoringService.AddListener(valueRuleService.HandleValueChange) // progi wartości akumulowanych (np. faule)
+	gameService := services.NewGameService(dbManager, scoringService)
+	disciplineService := services.NewDisciplineService(dbManager, scoringService)
+	lineupService := services.NewLineupService(dbManager, socketService)
+	operationLogService := services.NewOperationLogService(dbManager, eventService, scoringService, socketService)
+	substitutionService := services.NewSubstitutionService(dbManager, timerService, socketService, operationLogService)
+	crewService := services.NewCrewService(dbManager, cameraService)
+	reportService := services.NewReportService(dbManager, crewService)
+	recorderCommandService := services.NewRecorderCommandService(dbManager, appState, socketService)
+	socketService.SetRecorderCommandService(recorderCommandService)
+	tableService := services.NewTableService(dbManager, socketService)
+	scoringService.AddListener(tableService.HandleValueChange) // tabela na żywo po zmianie wyniku trwającego meczu
 
 	// Inicjalizacja handlerów
-	setupHandler := handlers.NewSetupHandler(dbManager)
-	sessionHandler := handlers.NewSessionHandler(dbManager)
+	setupHandler := handlers.NewSetupHandler(dbManager, cameraService)
+	sessionHandler := handlers.NewSessionHandler(dbManager, cameraService, recorderCommandService)
 	pageHandler := handlers.NewPageHandler()
-	cameraHandler := handlers.NewCameraHandler(appState, socketService)
+	authHandler := handlers.NewAuthHandler(authenticator)
+	cameraHandler := handlers.NewCameraHandler(appState, socketService, recordingService, cameraService)
+	recordingHandler := handlers.NewRecordingHandler(recordingService, footageService)
+	gameHandler := handlers.NewGameHandler(gameService)
+	lineupHandler := handlers.NewLineupHandler(lineupService)
+	substitutionHandler := handlers.NewSubstitutionHandler(substitutionService)
+	crewHandler := handlers.NewCrewHandler(crewService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	disciplineHandler := handlers.NewDisciplineHandler(disciplineService)
+	eventHandler := handlers.NewEventHandler(eventService, operationLogService)
+	scoreHandler := handlers.NewScoreHandler(scoringService, operationLogService)
+	operationHandler := handlers.NewOperationHandler(operationLogService)
+	valueRuleHandler := handlers.NewValueRuleHandler(valueRuleService)
+	recorderCommandHandler := handlers.NewRecorderCommandHandler(recorderCommandService)
 	obsHandler := handlers.NewOBSHandler(obsClient)
 	timerHandler := handlers.NewTimerHandler(timerService)
-	databaseHandler := handlers.NewDatabaseHandler(dbManager)
+	databaseHandler := handlers.NewDatabaseHandler(dbManager, cameraService)
 	scraperHandler := handlers.NewScraperHandler(dbManager) // Przekaż dbManager
-	// tableHandler := handlers.NewTableHandler(tableService)
+	tableHandler := handlers.NewTableHandler(tableService)
 	teamHandler := handlers.NewTeamHandler(dbManager)
 	logoHandler := handlers.NewLogoHandler()
 	teamImportHandler := handlers.NewTeamImportHandler(dbManager)
@@ -133,9 +175,20 @@ func main() {
 		http.StripPrefix("/static/", http.FileServer(http.Dir("./web/static/"))),
 	)
 
+	// Logowanie - BEZ wymogu tokenu
+	router.HandleFunc("/login", authHandler.ShowLoginPage).Methods("GET")
+	router.HandleFunc("/login", authHandler.Login).Methods("POST")
+	router.HandleFunc("/logout", authHandler.Logout).Methods("GET")
+
 	// Middleware sprawdzający konfigurację
 	router.Use(checkSetupMiddleware(dbConfig))
 
+	// Middleware uwierzytelniania i ról
+	router.Use(authenticator.Middleware())
+
+	// API - Auth
+	router.HandleFunc("/api/auth/me", authHandler.WhoAmI).Methods("GET")
+
 	// Strony WWW
 	router.HandleFunc("/", pageHandler.Index).Methods("GET")
 
@@ -151,6 +204,105 @@ func main() {
 	router.HandleFunc("/api/get-record-data", cameraHandler.GetRecordData).Methods("POST")
 	router.HandleFunc("/api/status", cameraHandler.GetStatus).Methods("GET")
 
+	// API - Kamery (konfiguracja w bazie)
+	router.HandleFunc("/api/cameras", cameraHandler.ListCameras).Methods("GET")
+	router.HandleFunc("/api/cameras", cameraHandler.CreateCamera).Methods("POST")
+	router.HandleFunc("/api/cameras/{id}", cameraHandler.GetCamera).Methods("GET")
+	router.HandleFunc("/api/cameras/{id}", cameraHandler.UpdateCamera).Methods("PUT")
+	router.HandleFunc("/api/cameras/{id}", cameraHandler.DeleteCamera).Methods("DELETE")
+	router.HandleFunc("/api/games/{id}/cameras", cameraHandler.GetGameCameras).Methods("GET")
+	router.HandleFunc("/api/games/{id}/cameras", cameraHandler.SetGameCameras).Methods("PUT")
+	router.HandleFunc("/api/program-camera", cameraHandler.SetProgramCamera).Methods("POST")
+
+	// API - Mecze (części meczu i sloty wartości z presetu)
+	router.HandleFunc("/api/games", gameHandler.ListGames).Methods("GET")
+	router.HandleFunc("/api/games", gameHandler.CreateGame).Methods("POST")
+	router.HandleFunc("/api/games/{id}", gameHandler.GetGame).Methods("GET")
+	router.HandleFunc("/api/games/{id}/initialize", gameHandler.InitializeGame).Methods("POST")
+
+	// API - Kadry meczowe i składy
+	router.HandleFunc("/api/games/{id}/lineup", lineupHandler.GetLineup).Methods("GET")
+	router.HandleFunc("/api/games/{id}/lineup/{teamId}", lineupHandler.SetSquad).Methods("PUT")
+
+	// API - Zmiany zawodników
+	router.HandleFunc("/api/games/{id}/substitutions", substitutionHandler.ListBlocks).Methods("GET")
+	router.HandleFunc("/api/games/{id}/substitutions", substitutionHandler.QueueBlock).Methods("POST")
+	router.HandleFunc("/api/games/{id}/substitutions/{block}/execute", substitutionHandler.ExecuteBlock).Methods("POST")
+	router.HandleFunc("/api/games/{id}/substitutions/{block}", substitutionHandler.CancelBlock).Methods("DELETE")
+	router.HandleFunc("/api/games/{id}/on-pitch/{teamId}", substitutionHandler.GetOnPitch).Methods("GET")
+
+	// API - Sędziowie i ekipa TV (kartoteka, obsada meczów, karta realizacji)
+	router.HandleFunc("/api/referees", crewHandler.ListReferees).Methods("GET")
+	router.HandleFunc("/api/referees", crewHandler.CreateReferee).Methods("POST")
+	router.HandleFunc("/api/referees/{id}", crewHandler.UpdateReferee).Methods("PUT")
+	router.HandleFunc("/api/referees/{id}", crewHandler.DeleteReferee).Methods("DELETE")
+	router.HandleFunc("/api/referee-roles", crewHandler.ListRefereeRoles).Methods("GET")
+	router.HandleFunc("/api/tv-staff", crewHandler.ListTVStaff).Methods("GET")
+	router.HandleFunc("/api/tv-staff", crewHandler.CreateTVStaff).Methods("POST")
+	router.HandleFunc("/api/tv-staff/{id}", crewHandler.UpdateTVStaff).Methods("PUT")
+	router.HandleFunc("/api/tv-staff/{id}", crewHandler.DeleteTVStaff).Methods("DELETE")
+	router.HandleFunc("/api/tv-staff-roles", crewHandler.ListTVStaffRoles).Methods("GET")
+	router.HandleFunc("/api/games/{id}/referees", crewHandler.GetGameReferees).Methods("GET")
+	router.HandleFunc("/api/games/{id}/referees", crewHandler.SetGameReferees).Methods("PUT")
+	router.HandleFunc("/api/games/{id}/tv-staff", crewHandler.GetGameTVStaff).Methods("GET")
+	router.HandleFunc("/api/games/{id}/tv-staff", crewHandler.SetGameTVStaff).Methods("PUT")
+	router.HandleFunc("/api/games/{id}/call-sheet", crewHandler.GetCallSheet).Methods("GET")
+
+	// API - Protokół meczu (HTML/PDF)
+	router.HandleFunc("/api/games/{id}/report", reportHandler.GetReport).Methods("GET")
+
+	// API - Dyscyplina (kartki, zawieszenia, dyskwalifikacje)
+	router.HandleFunc("/api/discipline", disciplineHandler.ListDiscipline).Methods("GET")
+	router.HandleFunc("/api/discipline/rules", disciplineHandler.GetRules).Methods("GET")
+	router.HandleFunc("/api/players/{id}/discipline", disciplineHandler.GetPlayerDiscipline).Methods("GET")
+	router.HandleFunc("/api/games/{id}/suspended", disciplineHandler.GetSuspended).Methods("GET")
+	router.HandleFunc("/api/games/{id}/dsq/{teamId}", disciplineHandler.Disqualify).Methods("POST")
+	router.HandleFunc("/api/games/{id}/dsq/{teamId}", disciplineHandler.ClearDisqualification).Methods("DELETE")
+
+	// API - Wydarzenia meczu
+	router.HandleFunc("/api/event-types", eventHandler.ListEventTypes).Methods("GET")
+	router.HandleFunc("/api/event-types", eventHandler.CreateEventType).Methods("POST")
+	router.HandleFunc("/api/games/{id}/events", eventHandler.ListEvents).Methods("GET")
+	router.HandleFunc("/api/games/{id}/events", eventHandler.CreateEvent).Methods("POST")
+	router.HandleFunc("/api/games/{id}/events/{eventId}", eventHandler.GetEvent).Methods("GET")
+	router.HandleFunc("/api/games/{id}/events/{eventId}", eventHandler.UpdateEvent).Methods("PUT")
+	router.HandleFunc("/api/games/{id}/events/{eventId}", eventHandler.DeleteEvent).Methods("DELETE")
+
+	// API - Wynik meczu na żywo
+	router.HandleFunc("/api/games/{id}/score", scoreHandler.GetScore).Methods("GET")
+	router.HandleFunc("/api/games/{id}/score", scoreHandler.SetScore).Methods("PUT")
+	router.HandleFunc("/api/games/{id}/score/adjust", scoreHandler.AdjustScore).Methods("POST")
+	router.HandleFunc("/api/games/{id}/score/recompute", scoreHandler.RecomputeScore).Methods("POST")
+
+	// API - Dziennik operacji meczu (historia, cofnij, ponów)
+	router.HandleFunc("/api/games/{id}/operations", operationHandler.ListOperations).Methods("GET")
+	router.HandleFunc("/api/games/{id}/undo", operationHandler.Undo).Methods("POST")
+	router.HandleFunc("/api/games/{id}/redo", operationHandler.Redo).Methods("POST")
+
+	// API - Reguły wartości akumulowanych (np. faule w futsalu)
+	router.HandleFunc("/api/value-rules", valueRuleHandler.ListRules).Methods("GET")
+	router.HandleFunc("/api/value-rules/{valueTypeId}", valueRuleHandler.GetRule).Methods("GET")
+	router.HandleFunc("/api/value-rules/{valueTypeId}", valueRuleHandler.SetRule).Methods("PUT")
+	router.HandleFunc("/api/value-rules/{valueTypeId}", valueRuleHandler.DeleteRule).Methods("DELETE")
+	router.HandleFunc("/api/games/{id}/accumulated", valueRuleHandler.GetAccumulated).Methods("GET")
+
+	// API - Nagrania (sesje i pliki kamer)
+	router.HandleFunc("/api/recordings", recordingHandler.ListSessions).Methods("GET")
+	router.HandleFunc("/api/recordings/files", recordingHandler.ReportFile).Methods("POST")
+	router.HandleFunc("/api/recordings/files/{id}/upload", recordingHandler.GetUploadStatus).Methods("GET")
+	router.HandleFunc("/api/recordings/files/{id}/upload", recordingHandler.InitUpload).Methods("POST")
+	router.HandleFunc("/api/recordings/files/{id}/upload", recordingHandler.UploadChunk).Methods("PUT")
+	router.HandleFunc("/api/recordings/files/{id}/upload/complete", recordingHandler.CompleteUpload).Methods("POST")
+	router.HandleFunc("/api/recordings/{id}", recordingHandler.GetSession).Methods("GET")
+	router.HandleFunc("/api/games/{id}/footage", recordingHandler.GetGameFootage).Methods("GET")
+
+	// API - Rejestratory kamer (polecenia i miniatury)
+	router.HandleFunc("/api/recorders/commands", recorderCommandHandler.ListCommands).Methods("GET")
+	router.HandleFunc("/api/recorders/commands", recorderCommandHandler.IssueCommand).Methods("POST")
+	router.HandleFunc("/api/recorders/commands/ack", recorderCommandHandler.AckCommand).Methods("POST")
+	router.HandleFunc("/api/recorders/snapshots", recorderCommandHandler.UploadSnapshot).Methods("POST")
+	router.HandleFunc("/api/recorders/snapshots/{file}", recorderCommandHandler.GetSnapshot).Methods("GET")
+
 	// API - OBS
 	router.HandleFunc("/api/obs/start-recording", obsHandler.StartRecording).Methods("POST")
 	router.HandleFunc("/api/obs/stop-recording", obsHandler.StopRecording).Methods("POST")
@@ -185,12 +337,17 @@ func main() {
 	router.HandleFunc("/api/scrape/competition/info", scraperHandler.GetCompetitionScraperInfo).Methods("GET")
 
 	// API - Tables
-	// router.HandleFunc("/api/tables/group", tableHandler.CalculateTableForGroup).Methods("GET")
-	// router.HandleFunc("/api/tables/stage", tableHandler.CalculateTableForStage).Methods("GET")
-	// router.HandleFunc("/api/tables/competition", tableHandler.CalculateTableForCompetition).Methods("GET")
-	// router.HandleFunc("/api/tables/algorithms", tableHandler.GetAvailableAlgorithms).Methods("GET")
-	// router.HandleFunc("/api/tables/competition/algorithm", tableHandler.GetCompetitionAlgorithmInfo).Methods("GET")
-	// router.HandleFunc("/api/tables/compare", tableHandler.CompareTeams).Methods("GET")
+	router.HandleFunc("/api/tables/group", tableHandler.CalculateTableForGroup).Methods("GET")
+	router.HandleFunc("/api/tables/group/{id}/history", tableHandler.GetGroupHistory).Methods("GET")
+	router.HandleFunc("/api/tables/group/{id}/projection", tableHandler.GetGroupProjection).Methods("GET")
+	router.HandleFunc("/api/tables/group/{id}/scenarios", tableHandler.GetTeamScenarios).Methods("GET")
+	router.HandleFunc("/api/tables/stage", tableHandler.CalculateTableForStage).Methods("GET")
+	router.HandleFunc("/api/tables/stage/ranking", tableHandler.CrossGroupRanking).Methods("GET")
+	router.HandleFunc("/api/tables/stage/advance", tableHandler.AdvanceStage).Methods("POST")
+	router.HandleFunc("/api/tables/competition", tableHandler.CalculateTableForCompetition).Methods("GET")
+	router.HandleFunc("/api/tables/algorithms", tableHandler.GetAvailableAlgorithms).Methods("GET")
+	router.HandleFunc("/api/tables/competition/algorithm", tableHandler.GetCompetitionAlgorithmInfo).Methods("GET")
+	router.HandleFunc("/api/tables/compare", tableHandler.CompareTeams).Methods("GET")
 
 	// API - Team Import (tymczasowe drużyny) - MUSI BYĆ PRZED /api/teams/{id}
 	router.HandleFunc("/api/teams/temp", teamImportHandler.GetTempTeams).Methods("GET")
@@ -243,8 +400,8 @@ func main() {
 
 	availableScrapers := scraperService.GetAvailableScrapers()
 	log.Printf("Dostępne scrapery: %v", availableScrapers)
-	// availableAlgorithms := tableService.GetAvailableAlgorithms()
-	// log.Printf("Dostępne algorytmy tabel: %v", availableAlgorithms)
+	availableAlgorithms := tableService.GetAvailableAlgorithms()
+	log.Printf("Dostępne algorytmy tabel: %v", availableAlgorithms)
 
 	log.Printf("=================================")
 
@@ -257,8 +414,10 @@ func main() {
 func checkSetupMiddleware(dbConfig *config.DatabaseConfig) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Pomiń sprawdzanie dla ścieżek setup i static
+			// Pomiń sprawdzanie dla ścieżek setup, logowania i static
 			if strings.HasPrefix(r.URL.Path, "/setup") ||
+				strings.HasPrefix(r.URL.Path, "/login") ||
+				strings.HasPrefix(r.URL.Path, "/logout") ||
 				strings.HasPrefix(r.URL.Path, "/static") ||
 				strings.HasPrefix(r.URL.Path, "/socket.io") {
 				next.ServeHTTP(w, r)
@@ -279,7 +438,7 @@ func checkSetupMiddleware(dbConfig *config.DatabaseConfig) mux.MiddlewareFunc {
 }
 
 // setupOBSEventHandlers - konfiguruje handlery eventów OBS
-func setupOBSEventHandlers(obsClient *services.OBSClient) {
+func setupOBSEventHandlers(obsClient *services.OBSClient, appState *state.AppState) {
 	// Event: Rozpoczęto/zatrzymano nagrywanie w OBS
 	obsClient.OnEvent("RecordStateChanged", func(data map[string]interface{}) {
 		outputActive, _ := data["outputActive"].(bool)
@@ -294,6 +453,14 @@ func setupOBSEventHandlers(obsClient *services.OBSClient) {
 	obsClient.OnEvent("CurrentProgramSceneChanged", func(data map[string]interface{}) {
 		sceneName, _ := data["sceneName"].(string)
 		log.Printf("OBS Event: Zmieniono scenę na: %s", sceneName)
+
+		// Scena o nazwie kamery oznacza, że ta kamera jest na wizji
+		for _, camera := range appState.GetAllCameras() {
+			if camera == sceneName {
+				appState.SetProgramCamera(camera)
+				break
+			}
+		}
 	})
 
 	// Event: OBS uruchomiono/zamknięto
@@ -5,7 +5,7 @@ import (
 	"log"
 	"os"
 
-	// "recorder-server/config"
+	"recorder-server/config"
 	"recorder-server/internal/database"
 	"recorder-server/internal/models"
 )
@@ -18,6 +18,12 @@ func main() {
 
 	command := os.Args[1]
 
+	// Tokeny API nie wymagają bazy danych
+	if command == "token" {
+		manageTokens(os.Args[2:])
+		return
+	}
+
 	// Inicjalizuj manager
 	dbManager := database.GetManager()
 	if err := dbManager.Initialize(); err != nil {
@@ -71,6 +77,9 @@ func printUsage() {
 	fmt.Println("  switch <nazwa>          - Przełącz na inną bazę danych")
 	fmt.Println("  migrate                 - Wykonaj migrację dla aktualnej bazy")
 	fmt.Println("  delete <nazwa>          - Usuń bazę danych")
+	fmt.Println("  token list              - Wyświetl tokeny API")
+	fmt.Println("  token create <nazwa> <rola> - Utwórz token API (role: admin, operator, scorer, overlay, recorder)")
+	fmt.Println("  token revoke <nazwa>    - Usuń token API")
 	fmt.Println("")
 	fmt.Println("Przykłady:")
 	fmt.Println("  db_helper list")
@@ -78,6 +87,62 @@ func printUsage() {
 	fmt.Println("  db_helper switch game_2025_01_15")
 	fmt.Println("  db_helper migrate")
 	fmt.Println("  db_helper delete old_game")
+	fmt.Println("  db_helper token create realizator operator")
+}
+
+func manageTokens(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Użycie: db_helper token <list|create|revoke> [argumenty]")
+		os.Exit(1)
+	}
+
+	authConfig, err := config.LoadAuthConfig()
+	if err != nil {
+		log.Fatal("Błąd wczytywania konfiguracji tokenów:", err)
+	}
+
+	switch args[0] {
+	case "list":
+		fmt.Println("=== Tokeny API ===")
+		if len(authConfig.Tokens) == 0 {
+			fmt.Println("Brak tokenów - serwer działa bez uwierzytelniania")
+			return
+		}
+		for i, t := range authConfig.Tokens {
+			fmt.Printf("  %d. %-20s %-10s utworzono: %s\n", i+1, t.Name, t.Role, t.CreatedAt)
+		}
+	case "create":
+		if len(args) < 3 {
+			fmt.Println("Użycie: db_helper token create <nazwa> <rola>")
+			os.Exit(1)
+		}
+		token, err := authConfig.AddToken(args[1], config.Role(args[2]))
+		if err != nil {
+			log.Fatal("Błąd tworzenia tokenu:", err)
+		}
+		if err := config.SaveAuthConfig(authConfig); err != nil {
+			log.Fatal("Błąd zapisywania konfiguracji tokenów:", err)
+		}
+		fmt.Printf("✓ Utworzono token '%s' (rola: %s)\n", args[1], args[2])
+		fmt.Printf("\nToken: %s\n", token)
+		fmt.Println("Zapisz go teraz - nie będzie można go ponownie wyświetlić.")
+	case "revoke":
+		if len(args) < 2 {
+			fmt.Println("Użycie: db_helper token revoke <nazwa>")
+			os.Exit(1)
+		}
+		if !authConfig.RemoveToken(args[1]) {
+			fmt.Printf("Nie znaleziono tokenu: %s\n", args[1])
+			os.Exit(1)
+		}
+		if err := config.SaveAuthConfig(authConfig); err != nil {
+			log.Fatal("Błąd zapisywania konfiguracji tokenów:", err)
+		}
+		fmt.Printf("✓ Usunięto token '%s'\n", args[1])
+	default:
+		fmt.Printf("Nieznane polecenie token: %s\n", args[0])
+		os.Exit(1)
+	}
 }
 
 func listDatabases(dbManager *database.Manager) {
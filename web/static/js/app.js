@@ -15,6 +15,17 @@ socket.on('disconnect', function() {
     updateStatus('Rozłączono z serwerem ✗');
 });
 
+// Event: pełny stan po połączeniu (lub na żądanie get_state_snapshot)
+socket.on('state_snapshot', function(snapshot) {
+    console.log('State snapshot:', snapshot);
+    renderRecordStatus(snapshot.recording);
+    updateOBSStatus(snapshot.obs.connected,
+        snapshot.obs.connected ? (snapshot.obs.recording ? 'Nagrywanie aktywne ✓' : 'Gotowy (nie nagrywa)') : null);
+    if (snapshot.timers && snapshot.timers.main) {
+        updateTimerDisplay(snapshot.timers.main);
+    }
+});
+
 // Funkcje pomocnicze
 
 function updateStatus(message) {
@@ -34,12 +45,46 @@ function updateOBSStatus(connected, message) {
     }
 }
 
+// Lista kamer pochodzi z bazy (aktywny mecz lub wszystkie kamery rozgrywek)
+let allCameras = [];
+
+function renderCameras(cameras) {
+    if (JSON.stringify(cameras) === JSON.stringify(allCameras)) {
+        return;
+    }
+    allCameras = cameras;
+
+    const container = document.getElementById('cameras');
+    container.innerHTML = '';
+
+    if (cameras.length === 0) {
+        container.innerHTML = '<span>Brak kamer - dodaj kamery w rozgrywkach lub wybierz je dla meczu</span>';
+        return;
+    }
+
+    cameras.forEach(camera => {
+        const label = document.createElement('label');
+        label.className = 'camera-checkbox';
+
+        const checkbox = document.createElement('input');
+        checkbox.type = 'checkbox';
+        checkbox.id = camera;
+        checkbox.checked = true;
+
+        const span = document.createElement('span');
+        span.textContent = camera;
+
+        label.appendChild(checkbox);
+        label.appendChild(span);
+        container.appendChild(label);
+    });
+}
+
 function getCheckedCameras() {
-    const cameras = ['camera_main', 'camera_center', 'camera_left', 'camera_right'];
     const active = [];
     const inactive = [];
     
-    cameras.forEach(camera => {
+    allCameras.forEach(camera => {
         const checkbox = document.getElementById(camera);
         if (checkbox && checkbox.checked) {
             active.push(camera);
@@ -98,28 +143,154 @@ function stopRecording() {
     });
 }
 
+function renderRecordStatus(data) {
+    renderCameras(data.all_cameras || []);
+
+    const statusText = data.record_status ? 
+        '<strong>NAGRYWANIE AKTYWNE</strong>' : 
+        '<strong>ZATRZYMANE</strong>';
+    
+    updateStatus(
+        statusText +
+        '<br>Aktywne kamery: ' + (data.active_cameras.length > 0 ? 
+            data.active_cameras.join(', ') : 'brak') +
+        '<br>Nieaktywne kamery: ' + (data.inactive_cameras.length > 0 ? 
+            data.inactive_cameras.join(', ') : 'brak')
+    );
+}
+
 function getStatus() {
     fetch('/api/status')
         .then(response => response.json())
-        .then(data => {
-            const statusText = data.record_status ? 
-                '<strong>NAGRYWANIE AKTYWNE</strong>' : 
-                '<strong>ZATRZYMANE</strong>';
-            
-            updateStatus(
-                statusText +
-                '<br>Aktywne kamery: ' + (data.active_cameras.length > 0 ? 
-                    data.active_cameras.join(', ') : 'brak') +
-                '<br>Nieaktywne kamery: ' + (data.inactive_cameras.length > 0 ? 
-                    data.inactive_cameras.join(', ') : 'brak')
-            );
-        })
+        .then(data => renderRecordStatus(data))
         .catch(error => {
             console.error('Błąd:', error);
             updateStatus('✗ Błąd pobierania statusu');
         });
 }
 
+// API: Polecenia dla rejestratorów
+
+function sendRecorderCommand(payload) {
+    const cameras = getCheckedCameras().active;
+    payload.cameras = cameras;
+
+    fetch('/api/recorders/commands', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(payload)
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (data.status === 'success') {
+            logRecorderCommand('→ ' + data.command.command + ' #' + data.command.id + ' wysłano');
+        } else {
+            logRecorderCommand('✗ Błąd: ' + (data.error || 'Nieznany błąd'));
+        }
+    })
+    .catch(error => {
+        console.error('Błąd:', error);
+        logRecorderCommand('✗ Błąd połączenia');
+    });
+}
+
+function recorderCommand(command) {
+    sendRecorderCommand({ command: command });
+}
+
+function recorderMarkClip() {
+    const seconds = parseInt(document.getElementById('clip-seconds').value) || 15;
+    const outTime = new Date();
+    const inTime = new Date(outTime.getTime() - seconds * 1000);
+
+    sendRecorderCommand({
+        command: 'mark_clip',
+        in_time: inTime.toISOString(),
+        out_time: outTime.toISOString(),
+        label: document.getElementById('clip-label').value
+    });
+}
+
+function recorderSetQuality() {
+    sendRecorderCommand({
+        command: 'set_quality',
+        profile: document.getElementById('quality-profile').value
+    });
+}
+
+function logRecorderCommand(message) {
+    const log = document.getElementById('command-log');
+    const line = document.createElement('div');
+    line.textContent = new Date().toLocaleTimeString() + ' ' + message;
+    log.prepend(line);
+
+    // Zachowaj 10 ostatnich wpisów
+    while (log.children.length > 10) {
+        log.removeChild(log.lastChild);
+    }
+}
+
+// Socket.IO event: potwierdzenie polecenia przez rejestrator
+socket.on('recorder_command_ack', function(data) {
+    const mark = data.status === 'ok' ? '✓' : '✗';
+    logRecorderCommand(mark + ' ' + data.camera_name + ': ' + data.command + ' #' + data.command_id +
+        (data.message ? ' (' + data.message + ')' : ''));
+});
+
+// Socket.IO event: nowa miniatura z kamery
+socket.on('snapshot_ready', function(data) {
+    const container = document.getElementById('snapshots');
+    let figure = document.getElementById('snapshot-' + data.camera_name);
+    if (!figure) {
+        figure = document.createElement('figure');
+        figure.id = 'snapshot-' + data.camera_name;
+        figure.innerHTML = '<img style="max-width: 240px"><figcaption></figcaption>';
+        container.appendChild(figure);
+    }
+    figure.querySelector('img').src = data.url;
+    figure.querySelector('figcaption').textContent = data.camera_name;
+});
+
+// Socket.IO events: przesyłanie nagrań z rejestratorów
+const footageUploads = {};
+
+function renderFootageUpload(data, label) {
+    footageUploads[data.file_id] = data.camera_name + ' / ' + data.file_name + ': ' + label;
+
+    const container = document.getElementById('footage-uploads');
+    container.innerHTML = '';
+    Object.keys(footageUploads).forEach(id => {
+        const line = document.createElement('div');
+        line.textContent = footageUploads[id];
+        container.appendChild(line);
+    });
+}
+
+socket.on('footage_upload_progress', function(data) {
+    renderFootageUpload(data, data.percent + '% (' + data.uploaded_bytes + ' / ' + data.size_bytes + ' B)');
+});
+
+socket.on('footage_upload_complete', function(data) {
+    renderFootageUpload(data, '✓ zweryfikowano');
+});
+
+socket.on('footage_upload_failed', function(data) {
+    renderFootageUpload(data, '✗ ' + (data.error || 'błąd weryfikacji'));
+});
+
+// Socket.IO event: osiągnięty próg wartości akumulowanej (np. 5. faul drużyny w połowie)
+socket.on('threshold_reached', function(data) {
+    const alerts = document.getElementById('threshold-alerts');
+    const line = document.createElement('div');
+    line.textContent = '⚠ ' + data.label + ': drużyna #' + data.team_id + ' - ' + data.value +
+        ' (próg ' + data.threshold + ')';
+    alerts.prepend(line);
+
+    while (alerts.children.length > 5) {
+        alerts.removeChild(alerts.lastChild);
+    }
+});
+
 // API: OBS Studio
 
 function obsStartRecording() {